@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerAccountHandler services bmc_account messages for fleet-wide
+// BMC credential management. Actions: create, set_password, enable,
+// disable, and rotate_self, which changes the password of the account
+// the agent itself uses and persists the new credential.
+func registerAccountHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("bmc_account", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		action, _ := msg.Data["action"].(string)
+		// BMC round trips with retries; never block the receive loop.
+		go func() {
+			reply := websocket.NewMessage("bmc_account", map[string]interface{}{
+				"target": target,
+				"action": action,
+			})
+			reply.MessageID = msg.MessageID
+			result, err := runAccountAction(mgr, target, action, msg.Data)
+			if err != nil {
+				slog.Warn("bmc account action failed", "action", action, "target", target, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				slog.Info("bmc account action executed", "action", action, "target", target)
+				for k, v := range result {
+					reply.Data[k] = v
+				}
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("bmc account reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runAccountAction enforces the config policy and executes one account
+// action against the named BMC target. Passwords from the request are
+// never logged or echoed back.
+func runAccountAction(mgr *config.Manager, target, action string, data map[string]interface{}) (map[string]interface{}, error) {
+	cfg := mgr.Current()
+	if !cfg.BMCAccounts.Enabled {
+		return nil, fmt.Errorf("bmc account management is disabled on this agent")
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return nil, err
+	}
+	username, _ := data["username"].(string)
+	password, _ := data["password"].(string)
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	switch action {
+	case "create":
+		role, _ := data["role"].(string)
+		if role == "" {
+			role = "ReadOnly"
+		}
+		if !bmc.AccountRoles[role] {
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("create requires username and password")
+		}
+		if err := c.CreateAccount(username, password, role); err != nil {
+			return nil, err
+		}
+		return ok(), nil
+	case "set_password":
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("set_password requires username and password")
+		}
+		if err := c.SetAccountPassword(username, password); err != nil {
+			return nil, err
+		}
+		return ok(), nil
+	case "enable", "disable":
+		if username == "" {
+			return nil, fmt.Errorf("%s requires a username", action)
+		}
+		if err := c.SetAccountEnabled(username, action == "enable"); err != nil {
+			return nil, err
+		}
+		return ok(), nil
+	case "rotate_self":
+		if password == "" {
+			return nil, fmt.Errorf("rotate_self requires a password")
+		}
+		if err := c.SetAccountPassword(t.Username, password); err != nil {
+			return nil, err
+		}
+		// The BMC accepted the new password; persist it so the next
+		// collection cycle authenticates. Only file: references can be
+		// rewritten in place.
+		persisted, err := config.RotateBMCPasswordRef(mgr.Path(), t.Name, password)
+		if err != nil {
+			return nil, fmt.Errorf("password rotated on bmc, but persisting it failed: %w", err)
+		}
+		if persisted {
+			if _, err := mgr.Reload(); err != nil {
+				return nil, fmt.Errorf("password rotated and persisted, but reload failed: %w", err)
+			}
+		}
+		result := ok()
+		result["persisted"] = persisted
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown account action %q", action)
+	}
+}
+
+func ok() map[string]interface{} {
+	return map[string]interface{}{"status": "ok"}
+}
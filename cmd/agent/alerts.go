@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/inventory"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// alertMonitor evaluates each BMC collection against sensor thresholds
+// and health status and emits alert messages on breach and clear
+// transitions, so the server hears about failing hardware immediately
+// instead of diffing periodic inventories.
+type alertMonitor struct {
+	cfg    *config.Config
+	client *websocket.Client
+
+	mu sync.Mutex
+	// active holds the currently breached conditions per target, keyed
+	// by component, so only transitions produce messages.
+	active map[string]map[string]map[string]interface{}
+}
+
+func newAlertMonitor(cfg *config.Config, client *websocket.Client) *alertMonitor {
+	return &alertMonitor{
+		cfg:    cfg,
+		client: client,
+		active: make(map[string]map[string]map[string]interface{}),
+	}
+}
+
+// evaluate compares one collection result against the previous state
+// for the target and sends an alert for every new breach and every
+// condition that cleared.
+func (a *alertMonitor) evaluate(target string, invs []*inventory.BMCInventory) {
+	current := make(map[string]map[string]interface{})
+	for _, inv := range invs {
+		a.check(inv, current)
+	}
+	a.mu.Lock()
+	prev := a.active[target]
+	a.active[target] = current
+	a.mu.Unlock()
+	for key, data := range current {
+		if _, wasActive := prev[key]; !wasActive {
+			a.send(target, "breach", data)
+		}
+	}
+	for key, data := range prev {
+		if _, still := current[key]; !still {
+			a.send(target, "clear", data)
+		}
+	}
+}
+
+func (a *alertMonitor) check(inv *inventory.BMCInventory, current map[string]map[string]interface{}) {
+	for _, t := range inv.Temperatures {
+		limit := t.CriticalCelsius
+		if c := a.cfg.HealthAlerts.TemperatureCelsius; c > 0 && (limit == 0 || c < limit) {
+			limit = c
+		}
+		switch {
+		case limit > 0 && t.Celsius >= limit:
+			data := add(current, inv, "temperature", t.Name, t.Chassis, "critical",
+				fmt.Sprintf("%s at %.0f°C, critical threshold %.0f°C", t.Name, t.Celsius, limit))
+			data["reading"] = t.Celsius
+			data["threshold"] = limit
+		case unhealthy(t.Health):
+			add(current, inv, "temperature", t.Name, t.Chassis, severityFor(t.Health),
+				fmt.Sprintf("%s reports health %s", t.Name, t.Health))
+		}
+	}
+	for _, f := range inv.Fans {
+		limit := f.CriticalRPM
+		if c := a.cfg.HealthAlerts.FanMinRPM; c > 0 && c > limit {
+			limit = c
+		}
+		switch {
+		case limit > 0 && f.RPM < limit:
+			data := add(current, inv, "fan", f.Name, f.Chassis, "critical",
+				fmt.Sprintf("%s at %d RPM, critical threshold %d RPM", f.Name, f.RPM, limit))
+			data["reading"] = f.RPM
+			data["threshold"] = limit
+		case unhealthy(f.Health):
+			add(current, inv, "fan", f.Name, f.Chassis, severityFor(f.Health),
+				fmt.Sprintf("%s reports health %s", f.Name, f.Health))
+		}
+	}
+	for _, p := range inv.PowerSupplies {
+		if unhealthy(p.Health) {
+			add(current, inv, "power_supply", p.Name, p.Chassis, severityFor(p.Health),
+				fmt.Sprintf("%s reports health %s", p.Name, p.Health))
+		}
+	}
+	for _, d := range inv.Storage {
+		switch {
+		case unhealthy(d.Health):
+			add(current, inv, "drive", d.Name, "", severityFor(d.Health),
+				fmt.Sprintf("%s reports health %s", d.Name, d.Health))
+		case d.FailurePredicted:
+			add(current, inv, "drive", d.Name, "", "warning",
+				fmt.Sprintf("%s predicts failure", d.Name))
+		}
+	}
+}
+
+// add records one breached condition and returns its data map so the
+// caller can attach reading details.
+func add(current map[string]map[string]interface{}, inv *inventory.BMCInventory,
+	kind, name, chassis, severity, message string) map[string]interface{} {
+	data := map[string]interface{}{
+		"kind":     kind,
+		"name":     name,
+		"severity": severity,
+		"message":  message,
+	}
+	if chassis != "" {
+		data["chassis"] = chassis
+	}
+	if inv.NodeID != "" {
+		data["node_id"] = inv.NodeID
+	}
+	current[inv.NodeID+"/"+kind+"/"+chassis+"/"+name] = data
+	return data
+}
+
+func (a *alertMonitor) send(target, state string, data map[string]interface{}) {
+	msg := map[string]interface{}{
+		"target": target,
+		"state":  state,
+	}
+	for k, v := range data {
+		msg[k] = v
+	}
+	slog.Warn("hardware health alert", "target", target, "state", state,
+		"kind", data["kind"], "name", data["name"], "severity", data["severity"])
+	if err := a.client.Send(websocket.NewMessage(websocket.TypeAlert, msg)); err != nil {
+		slog.Warn("alert send failed", "target", target, "error", err)
+	}
+}
+
+// unhealthy reports whether a Redfish health value needs attention.
+// Absent sensors report an empty health and are skipped.
+func unhealthy(health string) bool {
+	return health != "" && !strings.EqualFold(health, "OK")
+}
+
+// severityFor maps a Redfish health value onto an alert severity.
+func severityFor(health string) string {
+	if s := strings.ToLower(health); s == "warning" || s == "critical" {
+		return s
+	}
+	return "warning"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerBIOSHandler services bios_settings messages. Without
+// attributes the request is a read: the reply carries the applied and
+// pending attribute maps. With attributes the changes are staged in
+// the BMC's Bios Settings resource and the reply reports what is now
+// pending, so the server can track drift until the next reboot applies
+// the change.
+func registerBIOSHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("bios_settings", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		attrs, _ := msg.Data["attributes"].(map[string]interface{})
+		// Several Redfish round trips; keep them off the receive loop.
+		go func() {
+			reply := websocket.NewMessage("bios_settings", map[string]interface{}{
+				"target": target,
+			})
+			reply.MessageID = msg.MessageID
+			result, err := runBIOSSettings(mgr.Current(), target, attrs)
+			if err != nil {
+				slog.Warn("bios settings request failed", "target", target, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				for k, v := range result {
+					reply.Data[k] = v
+				}
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("bios settings reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runBIOSSettings enforces the config policy, then reads or stages
+// BIOS attributes on the named BMC target.
+func runBIOSSettings(cfg *config.Config, target string, attrs map[string]interface{}) (map[string]interface{}, error) {
+	if !cfg.BIOSControl.Enabled {
+		return nil, fmt.Errorf("bios control is disabled on this agent")
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return nil, err
+	}
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	if len(attrs) > 0 {
+		if err := c.StageBIOSSettings(attrs); err != nil {
+			return nil, err
+		}
+		pending, err := c.PendingBIOSSettings()
+		if err != nil {
+			return nil, fmt.Errorf("staged, but reading pending settings back failed: %w", err)
+		}
+		slog.Info("bios settings staged", "target", target, "count", len(attrs))
+		return map[string]interface{}{"status": "staged", "pending": pending}, nil
+	}
+	applied, err := c.BIOSAttributes()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := c.PendingBIOSSettings()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"status": "ok", "applied": applied, "pending": pending}, nil
+}
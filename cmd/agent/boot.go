@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerBootHandler services set_boot messages: stage a boot source
+// override (e.g. PXE once) on the target BMC and, when requested,
+// power cycle the host so the override takes effect immediately. The
+// power cycle goes through the power_action policy, so reprovisioning
+// needs both boot_control and power_control enabled.
+func registerBootHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("set_boot", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		bootTarget, _ := msg.Data["boot_target"].(string)
+		enabled, _ := msg.Data["enabled"].(string)
+		powerCycle, _ := msg.Data["power_cycle"].(bool)
+		// Staging plus an optional power cycle is slow BMC work; keep
+		// it off the receive loop.
+		go func() {
+			reply := websocket.NewMessage("set_boot", map[string]interface{}{
+				"target":      target,
+				"boot_target": bootTarget,
+			})
+			reply.MessageID = msg.MessageID
+			if err := runSetBoot(mgr.Current(), target, bootTarget, enabled, powerCycle); err != nil {
+				slog.Warn("set_boot failed", "target", target, "boot_target", bootTarget, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				slog.Info("boot override staged", "target", target, "boot_target", bootTarget, "power_cycle", powerCycle)
+				reply.Data["status"] = "ok"
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("set_boot reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runSetBoot enforces the config policy, stages the override, and
+// optionally power cycles the host.
+func runSetBoot(cfg *config.Config, target, bootTarget, enabled string, powerCycle bool) error {
+	if !cfg.BootControl.Enabled {
+		return fmt.Errorf("boot control is disabled on this agent")
+	}
+	if !bmc.BootTargets[bootTarget] {
+		return fmt.Errorf("unknown boot target %q", bootTarget)
+	}
+	if enabled == "" {
+		enabled = "Once"
+	}
+	if !bmc.BootOverrideModes[enabled] {
+		return fmt.Errorf("unknown boot override mode %q", enabled)
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return err
+	}
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	if err := c.SetBootOverride(bootTarget, enabled); err != nil {
+		return err
+	}
+	if powerCycle {
+		return runPowerAction(cfg, "PowerCycle", target)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerCertHandler services bmc_cert messages so fleets can replace
+// default self-signed BMC certificates. Actions: info reads the current
+// HTTPS certificate, generate_csr has the BMC produce a signing request
+// (the private key stays on the BMC), and replace installs a signed PEM
+// certificate.
+func registerCertHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("bmc_cert", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		action, _ := msg.Data["action"].(string)
+		// CSR generation in particular can take a while on the BMC;
+		// keep it off the receive loop.
+		go func() {
+			reply := websocket.NewMessage("bmc_cert", map[string]interface{}{
+				"target": target,
+				"action": action,
+			})
+			reply.MessageID = msg.MessageID
+			result, err := runCertAction(mgr.Current(), target, action, msg.Data)
+			if err != nil {
+				slog.Warn("bmc certificate action failed", "action", action, "target", target, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				slog.Info("bmc certificate action executed", "action", action, "target", target)
+				for k, v := range result {
+					reply.Data[k] = v
+				}
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("bmc certificate reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runCertAction enforces the config policy and executes one certificate
+// action against the named BMC target.
+func runCertAction(cfg *config.Config, target, action string, data map[string]interface{}) (map[string]interface{}, error) {
+	if !cfg.BMCCerts.Enabled {
+		return nil, fmt.Errorf("bmc certificate management is disabled on this agent")
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return nil, err
+	}
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	switch action {
+	case "info":
+		info, err := c.HTTPSCertificate()
+		if err != nil {
+			return nil, err
+		}
+		result := ok()
+		result["certificate"] = info
+		return result, nil
+	case "generate_csr":
+		req := bmc.CSRRequest{
+			CommonName:         strField(data, "common_name"),
+			Organization:       strField(data, "organization"),
+			OrganizationalUnit: strField(data, "organizational_unit"),
+			City:               strField(data, "city"),
+			State:              strField(data, "state"),
+			Country:            strField(data, "country"),
+		}
+		if bits, isFloat := data["key_bit_length"].(float64); isFloat {
+			req.KeyBitLength = int(bits)
+		}
+		csr, err := c.GenerateCSR(req)
+		if err != nil {
+			return nil, err
+		}
+		result := ok()
+		result["csr"] = csr
+		return result, nil
+	case "replace":
+		certPEM := strField(data, "certificate")
+		if certPEM == "" {
+			return nil, fmt.Errorf("replace requires a PEM certificate")
+		}
+		if err := c.ReplaceHTTPSCertificate(certPEM); err != nil {
+			return nil, err
+		}
+		return ok(), nil
+	default:
+		return nil, fmt.Errorf("unknown certificate action %q", action)
+	}
+}
+
+func strField(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simonschuang/demo/internal/jitter"
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/collector"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/inventory"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// buildRegistry assembles the collectors enabled by the configuration.
+func buildRegistry(cfg *config.Config, client *websocket.Client) *collector.Registry {
+	reg := collector.NewRegistry()
+	if cfg.CollectorEnabled("local") {
+		mustRegister(reg, &localCollector{cfg: cfg, collector: inventory.NewCollector()})
+	}
+	if cfg.CollectorEnabled("bmc") {
+		// BMC targets share a semaphore so one agent can proxy a rack's
+		// worth of BMCs without opening them all at once.
+		sem := make(chan struct{}, cfg.BMCMaxConcurrent)
+		var alerts *alertMonitor
+		if cfg.HealthAlerts.Enabled {
+			alerts = newAlertMonitor(cfg, client)
+		}
+		for _, t := range cfg.BMC {
+			if t.IP == "" {
+				continue
+			}
+			mustRegister(reg, &bmcCollector{
+				cfg:    cfg,
+				target: t.Name,
+				client: bmc.NewClient(t, cfg.ProxyEnabled()),
+				sem:    sem,
+				alerts: alerts,
+			})
+		}
+	}
+	if cfg.Metrics.Enabled {
+		mustRegister(reg, &metricsCollector{cfg: cfg, sampler: inventory.NewMetricsSampler()})
+	}
+	if cfg.Discovery.Enabled {
+		mustRegister(reg, &discoveryCollector{cfg: cfg})
+	}
+	for _, cc := range cfg.CustomCollectors {
+		mustRegister(reg, collector.NewExec(cc.Name, cc.Command,
+			cc.Interval.Duration(), time.Duration(cc.TimeoutSeconds)*time.Second))
+	}
+	return reg
+}
+
+func mustRegister(reg *collector.Registry, c collector.Collector) {
+	if err := reg.Register(c); err != nil {
+		slog.Warn("collector registration failed", "error", err)
+	}
+}
+
+// localCollector reports the local host snapshot, as deltas when
+// differential reporting is enabled.
+type localCollector struct {
+	cfg       *config.Config
+	collector *inventory.Collector
+
+	// Differential reporting: the previous snapshot and when the last
+	// full payload went out.
+	lastSnapshot map[string]interface{}
+	lastFullSync time.Time
+}
+
+func (l *localCollector) Name() string { return "local" }
+
+func (l *localCollector) Interval() time.Duration {
+	return l.cfg.CollectorInterval("local").Duration()
+}
+
+func (l *localCollector) Collect(ctx context.Context) (string, map[string]interface{}, error) {
+	inv, err := l.collector.Collect()
+	if err != nil {
+		return "", nil, err
+	}
+	filterSections(l.cfg, inv)
+	if l.cfg.SnapshotPath != "" {
+		if err := inventory.WriteSnapshot(l.cfg.SnapshotPath, inv); err != nil {
+			slog.Warn("inventory snapshot write failed", "path", l.cfg.SnapshotPath, "error", err)
+		}
+	}
+	data, err := inventory.ToMap(inv)
+	if err != nil {
+		return "", nil, err
+	}
+	if !l.cfg.Delta.Enabled {
+		return websocket.TypeInventory, data, nil
+	}
+	fullEvery := time.Duration(l.cfg.Delta.FullSyncSeconds) * time.Second
+	if l.lastSnapshot == nil || time.Since(l.lastFullSync) >= fullEvery {
+		l.lastSnapshot, l.lastFullSync = data, time.Now()
+		return websocket.TypeInventory, data, nil
+	}
+	changed, removed := inventory.Diff(l.lastSnapshot, data)
+	l.lastSnapshot = data
+	if len(changed) == 0 && len(removed) == 0 {
+		return "", nil, nil
+	}
+	delta := map[string]interface{}{"changed": changed}
+	if len(removed) > 0 {
+		delta["removed"] = removed
+	}
+	return websocket.TypeInventoryDelta, delta, nil
+}
+
+// metricsCollector streams lightweight utilization samples on its own
+// short interval, independent of the inventory schedule.
+type metricsCollector struct {
+	cfg     *config.Config
+	sampler *inventory.MetricsSampler
+}
+
+func (m *metricsCollector) Name() string { return "metrics" }
+
+func (m *metricsCollector) Interval() time.Duration {
+	return m.cfg.Metrics.Interval.Duration()
+}
+
+func (m *metricsCollector) Collect(ctx context.Context) (string, map[string]interface{}, error) {
+	data, err := inventory.ToMap(m.sampler.Sample())
+	if err != nil {
+		return "", nil, err
+	}
+	return websocket.TypeMetrics, data, nil
+}
+
+// discoveryCollector periodically searches the management network for
+// unmanaged Redfish endpoints and reports candidates for credential
+// assignment. Already-configured targets are filtered out.
+type discoveryCollector struct {
+	cfg *config.Config
+}
+
+func (d *discoveryCollector) Name() string { return "discovery" }
+
+func (d *discoveryCollector) Interval() time.Duration {
+	return d.cfg.Discovery.Interval.Duration()
+}
+
+func (d *discoveryCollector) Collect(ctx context.Context) (string, map[string]interface{}, error) {
+	timeout := time.Duration(d.cfg.Discovery.TimeoutSeconds) * time.Second
+	var (
+		cands []bmc.Candidate
+		err   error
+	)
+	if d.cfg.Discovery.SSDP {
+		cands, err = bmc.DiscoverSSDP(ctx, timeout)
+	} else {
+		cands, err = bmc.DiscoverCIDR(ctx, d.cfg.Discovery.CIDR, timeout)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	known := make(map[string]bool)
+	for _, t := range d.cfg.BMC {
+		ip := t.IP
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		known[ip] = true
+	}
+	var unmanaged []bmc.Candidate
+	for _, cand := range cands {
+		if !known[cand.IP] {
+			unmanaged = append(unmanaged, cand)
+		}
+	}
+	if len(unmanaged) == 0 {
+		return "", nil, nil
+	}
+	return websocket.TypeBMCDiscovery, map[string]interface{}{
+		"candidates": unmanaged,
+	}, nil
+}
+
+// bmcCollector polls one BMC target.
+type bmcCollector struct {
+	cfg    *config.Config
+	target string
+	client *bmc.Client
+	sem    chan struct{}
+	// alerts, when non-nil, evaluates each collection for health
+	// threshold transitions.
+	alerts *alertMonitor
+}
+
+func (b *bmcCollector) Name() string { return "bmc:" + b.target }
+
+// Close logs the agent out of the BMC so long-lived sessions are not
+// leaked across agent restarts.
+func (b *bmcCollector) Close() error {
+	b.client.Logout()
+	return nil
+}
+
+func (b *bmcCollector) Interval() time.Duration {
+	return b.cfg.CollectorInterval("bmc").Duration()
+}
+
+func (b *bmcCollector) Collect(ctx context.Context) (string, map[string]interface{}, error) {
+	select {
+	case b.sem <- struct{}{}:
+		defer func() { <-b.sem }()
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+	invs, err := b.client.Collect()
+	if err != nil {
+		return "", nil, err
+	}
+	if b.alerts != nil {
+		b.alerts.evaluate(b.target, invs)
+	}
+	if len(invs) == 1 {
+		// Single-node servers keep the flat payload shape.
+		data, err := inventory.ToMap(invs[0])
+		if err != nil {
+			return "", nil, err
+		}
+		data["target"] = b.target
+		return websocket.TypeBMCInventory, data, nil
+	}
+	nodes := make([]interface{}, 0, len(invs))
+	for _, inv := range invs {
+		m, err := inventory.ToMap(inv)
+		if err != nil {
+			return "", nil, err
+		}
+		nodes = append(nodes, m)
+	}
+	return websocket.TypeBMCInventory, map[string]interface{}{
+		"target": b.target,
+		"nodes":  nodes,
+	}, nil
+}
+
+// eventRetryInterval spaces reconnect attempts for the BMC event
+// stream.
+const eventRetryInterval = 30 * time.Second
+
+// streamEvents forwards hardware events pushed by the BMC as bmc_event
+// messages, reconnecting until ctx is canceled. BMCs without an SSE
+// stream leave the agent on polling alone.
+func (b *bmcCollector) streamEvents(ctx context.Context, client *websocket.Client) {
+	for {
+		err := b.client.StreamEvents(ctx, func(ev bmc.Event) {
+			data, err := inventory.ToMap(ev)
+			if err != nil {
+				return
+			}
+			data["target"] = b.target
+			if err := client.Send(websocket.NewMessage(websocket.TypeBMCEvent, data)); err != nil {
+				slog.Warn("bmc event send failed", "target", b.target, "error", err)
+			}
+		})
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, bmc.ErrNoSSE):
+			slog.Debug("bmc offers no event stream, staying on polling", "target", b.target)
+			return
+		case err != nil:
+			slog.Warn("bmc event stream dropped", "target", b.target, "error", err)
+		}
+		select {
+		case <-time.After(eventRetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runCollector drives one collector: collect on its jittered interval
+// while connected, and immediately after each (re)connect.
+func runCollector(ctx context.Context, cfg *config.Config, client *websocket.Client, c collector.Collector) {
+	events, unsubscribe := client.SubscribeState(4)
+	defer unsubscribe()
+	if closer, ok := c.(io.Closer); ok {
+		defer closer.Close()
+	}
+	interval := c.Interval()
+	if interval <= 0 {
+		interval = cfg.CollectInterval.Duration()
+	}
+	ticker := jitter.NewTicker(interval, cfg.CollectorJitter(collectorBaseName(c.Name())))
+	defer ticker.Stop()
+	collect := func() {
+		msgType, data, err := c.Collect(ctx)
+		if err != nil {
+			slog.Warn("collector failed", "collector", c.Name(), "error", err)
+			return
+		}
+		if data == nil {
+			return
+		}
+		if err := client.Send(websocket.NewMessage(msgType, data)); err != nil {
+			slog.Warn("send failed", "type", msgType, "collector", c.Name(), "error", err)
+		}
+	}
+	if client.Connected() {
+		collect()
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if client.Connected() {
+				collect()
+			}
+		case ev := <-events:
+			if ev.State == websocket.StateConnected {
+				collect()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectorBaseName maps an instance name like "bmc:rack1-node3" to its
+// collector family for configuration lookups.
+func collectorBaseName(name string) string {
+	base, _, _ := strings.Cut(name, ":")
+	return base
+}
+
+// runInventory drives all registered collectors until stop is closed.
+// Each collector runs on its own schedule from the collectors section,
+// so expensive BMC polling can be hourly while local stats stay
+// frequent.
+func runInventory(cfg *config.Config, client *websocket.Client, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	var wg sync.WaitGroup
+	for _, c := range buildRegistry(cfg, client).All() {
+		wg.Add(1)
+		go func(c collector.Collector) {
+			defer wg.Done()
+			runCollector(ctx, cfg, client, c)
+		}(c)
+		// BMC targets additionally stream pushed hardware events when
+		// the operator opts in.
+		if b, ok := c.(*bmcCollector); ok && cfg.CollectorOptedIn("bmc_events") {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.streamEvents(ctx, client)
+			}()
+		}
+	}
+	wg.Wait()
+}
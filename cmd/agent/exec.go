@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/terminal"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerExecHandler services exec_command messages: run one
+// non-interactive command with optional args, env, working dir, and
+// timeout, stream stdout and stderr separately as exec_output, and
+// report the exit code and duration keyed to the request's message id.
+// The command runs on its own goroutine so a long-running process does
+// not stall inbound message dispatch.
+func registerExecHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler(websocket.TypeExecCommand, func(msg *websocket.Message) {
+		go func() {
+			reply := websocket.NewMessage(websocket.TypeExecCommand, map[string]interface{}{})
+			reply.MessageID = msg.MessageID
+			if err := runExecCommand(client, mgr.Current(), msg, reply.Data); err != nil {
+				slog.Warn("exec command failed", "error", err)
+				reply.Data["error"] = err.Error()
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("exec reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runExecCommand validates the request against policy, runs the
+// command, and fills result with exit_code, duration_ms, and status.
+func runExecCommand(client *websocket.Client, cfg *config.Config, msg *websocket.Message, result map[string]interface{}) error {
+	if !cfg.Exec.Enabled {
+		return fmt.Errorf("remote command execution is disabled on this agent")
+	}
+	command, _ := msg.Data["command"].(string)
+	if command == "" {
+		return fmt.Errorf("missing command")
+	}
+	var args []string
+	if raw, ok := msg.Data["args"].([]interface{}); ok {
+		for _, a := range raw {
+			s, ok := a.(string)
+			if !ok {
+				return fmt.Errorf("args must be strings")
+			}
+			args = append(args, s)
+		}
+	}
+	if cfg.CommandPolicy.Enabled {
+		pol, err := terminal.NewPolicy(cfg.CommandPolicy.Allow, cfg.CommandPolicy.Deny,
+			cfg.CommandPolicy.ReadOnly, cfg.CommandPolicy.NoSudo)
+		if err != nil {
+			return fmt.Errorf("command policy: %w", err)
+		}
+		line := strings.Join(append([]string{command}, args...), " ")
+		if err := pol.Check(line); err != nil {
+			return fmt.Errorf("command rejected by policy: %w", err)
+		}
+	}
+
+	timeout := time.Duration(cfg.Exec.TimeoutSeconds) * time.Second
+	if t, ok := msg.Data["timeout"].(float64); ok && t > 0 {
+		if requested := time.Duration(t * float64(time.Second)); requested < timeout {
+			timeout = requested
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	if dir, ok := msg.Data["dir"].(string); ok && dir != "" {
+		cmd.Dir = dir
+	}
+	cmd.Env = os.Environ()
+	if env, ok := msg.Data["env"].(map[string]interface{}); ok {
+		for k, v := range env {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("env values must be strings")
+			}
+			cmd.Env = append(cmd.Env, k+"="+s)
+		}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go streamExecOutput(client, msg.MessageID, "stdout", stdout, &wg)
+	go streamExecOutput(client, msg.MessageID, "stderr", stderr, &wg)
+	wg.Wait()
+	err = cmd.Wait()
+
+	result["duration_ms"] = time.Since(start).Milliseconds()
+	result["exit_code"] = cmd.ProcessState.ExitCode()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", timeout)
+	}
+	if err != nil {
+		if _, isExit := err.(*exec.ExitError); !isExit {
+			return err
+		}
+		// A non-zero exit is a result, not a transport failure.
+	}
+	result["status"] = "ok"
+	return nil
+}
+
+// streamExecOutput forwards one output stream in base64 chunks, each
+// correlated to the originating request.
+func streamExecOutput(client *websocket.Client, messageID, stream string, r io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			out := websocket.NewMessage(websocket.TypeExecOutput, map[string]interface{}{
+				"stream": stream,
+				"output": base64.StdEncoding.EncodeToString(buf[:n]),
+			})
+			out.MessageID = messageID
+			if err := client.Send(out); err != nil {
+				slog.Warn("exec output send failed", "stream", stream, "error", err)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// firmwareUpdateTimeout bounds one complete update workflow, download
+// and flash included.
+const firmwareUpdateTimeout = 2 * time.Hour
+
+// registerFirmwareHandler services firmware_update messages: push the
+// image URI to the target BMC's UpdateService and stream task progress
+// back under the request's message id, ending with a final status.
+func registerFirmwareHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("firmware_update", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		imageURI, _ := msg.Data["image_uri"].(string)
+		// Updates run minutes to hours; never block the receive loop.
+		go func() {
+			report := func(data map[string]interface{}) {
+				reply := websocket.NewMessage("firmware_update", data)
+				reply.MessageID = msg.MessageID
+				if err := client.Send(reply); err != nil {
+					slog.Warn("firmware update report failed", "error", err)
+				}
+			}
+			err := runFirmwareUpdate(mgr.Current(), target, imageURI, func(p bmc.UpdateProgress) {
+				report(map[string]interface{}{
+					"status":  "progress",
+					"state":   p.State,
+					"percent": p.Percent,
+					"message": p.Message,
+				})
+			})
+			if err != nil {
+				slog.Warn("firmware update failed", "target", target, "error", err)
+				report(map[string]interface{}{"status": "failed", "error": err.Error()})
+				return
+			}
+			slog.Info("firmware update completed", "target", target)
+			report(map[string]interface{}{"status": "completed"})
+		}()
+	})
+}
+
+func runFirmwareUpdate(cfg *config.Config, target, imageURI string, progress func(bmc.UpdateProgress)) error {
+	if !cfg.FirmwareUpdate.Enabled {
+		return fmt.Errorf("firmware updates are disabled on this agent")
+	}
+	if imageURI == "" {
+		return fmt.Errorf("firmware_update requires an image_uri")
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), firmwareUpdateTimeout)
+	defer cancel()
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	return c.UpdateFirmware(ctx, imageURI, progress)
+}
@@ -0,0 +1,574 @@
+// Command agent is the host-side management agent. It maintains a
+// WebSocket connection to the management server, reports heartbeats and
+// inventory (local and BMC), and services interactive terminal sessions.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/heartbeat"
+	"github.com/simonschuang/demo/pkg/inventory"
+	"github.com/simonschuang/demo/pkg/terminal"
+	"github.com/simonschuang/demo/pkg/transfer"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "encrypt":
+			os.Exit(runEncrypt(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "inventory":
+			os.Exit(runInventoryCmd(os.Args[2:]))
+		}
+	}
+	configPath := flag.String("config", "/etc/agent/config.yaml", "path to the configuration file")
+	profile := flag.String("profile", "", "named config profile to apply (also AGENT_PROFILE)")
+	flags := config.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Export the flag so reloads resolve the same profile.
+	if *profile != "" {
+		os.Setenv("AGENT_PROFILE", *profile)
+	}
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		os.Exit(1)
+	}
+	// Explicit command-line flags win over file and environment values.
+	if err := flags.Apply(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		os.Exit(1)
+	}
+	setupLogging(cfg.LogLevel)
+
+	client := websocket.NewClient(cfg)
+	switch {
+	case cfg.ClientTokenFile != "":
+		client.SetTokenProvider(websocket.FileToken(cfg.ClientTokenFile))
+	case cfg.ClientTokenCommand != "":
+		client.SetTokenProvider(websocket.CommandToken(cfg.ClientTokenCommand))
+	}
+	mgr, err := config.NewManager(cfg, *configPath+".overrides.json")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		os.Exit(1)
+	}
+
+	executor := terminal.NewExecutor(client)
+	executor.SetSOLCommand(solCommandProvider(mgr))
+	executor.SetLimits(cfg.Terminal.IdleTimeout.Duration(), cfg.Terminal.MaxDuration.Duration())
+	executor.SetSessionLimits(cfg.Terminal.MaxSessions, cfg.Terminal.MaxSessionsPerUser)
+	if cfg.Terminal.ScrollbackBytes > 0 {
+		executor.SetScrollback(cfg.Terminal.ScrollbackBytes)
+	}
+	if cfg.CommandPolicy.Enabled {
+		pol, err := terminal.NewPolicy(cfg.CommandPolicy.Allow, cfg.CommandPolicy.Deny,
+			cfg.CommandPolicy.ReadOnly, cfg.CommandPolicy.NoSudo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "agent: command policy: %v\n", err)
+			os.Exit(1)
+		}
+		executor.SetPolicy(pol, cfg.CommandPolicy.EnforceInteractive)
+	}
+	if cfg.Terminal.AuditLog != "" || cfg.Terminal.AuditSyslog {
+		audit, err := terminal.NewAuditLogger(cfg.Terminal.AuditLog, cfg.Terminal.AuditSyslog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+			os.Exit(1)
+		}
+		defer audit.Close()
+		executor.SetAudit(audit, cfg.Terminal.TranscriptDir)
+	}
+	client.RegisterHandler(websocket.TypeTerminalCommand, executor.HandleCommand)
+	registerPowerHandler(client, mgr)
+	registerPowerCapHandler(client, mgr)
+	registerFirmwareHandler(client, mgr)
+	registerBIOSHandler(client, mgr)
+	registerBootHandler(client, mgr)
+	registerAccountHandler(client, mgr)
+	registerCertHandler(client, mgr)
+	registerExecHandler(client, mgr)
+	if cfg.FileTransfer.Enabled {
+		transfer.New(client, cfg.FileTransfer).Register()
+	}
+
+	var (
+		subsysMu      sync.Mutex
+		hb            *heartbeat.Heartbeat
+		inventoryStop chan struct{}
+	)
+	startSubsystems := func() {
+		subsysMu.Lock()
+		defer subsysMu.Unlock()
+		current := mgr.Current()
+		hb = heartbeat.New(client, current.HeartbeatInterval.Duration(), current.JitterPercent)
+		hb.Start()
+		inventoryStop = make(chan struct{})
+		go runInventory(current, client, inventoryStop)
+	}
+	stopSubsystems := func() {
+		subsysMu.Lock()
+		defer subsysMu.Unlock()
+		if hb != nil {
+			hb.Stop()
+			hb = nil
+		}
+		if inventoryStop != nil {
+			close(inventoryStop)
+			inventoryStop = nil
+		}
+	}
+
+	// Subsystems are started once and react to connection state
+	// themselves; the event loop here only drives reconnection.
+	events, unsubscribe := client.SubscribeState(16)
+	defer unsubscribe()
+	reconnect := make(chan struct{}, 1)
+	var bootstrapOnce sync.Once
+	go func() {
+		for ev := range events {
+			switch ev.State {
+			case websocket.StateConnected:
+				if cfg.Bootstrap.Enabled {
+					bootstrapOnce.Do(func() { go fetchBootstrap(client, mgr, cfg) })
+				}
+			case websocket.StateDisconnected:
+				select {
+				case reconnect <- struct{}{}:
+				default:
+				}
+			case websocket.StateReconnecting:
+				slog.Debug("reconnecting", "attempt", ev.Attempt)
+			}
+		}
+	}()
+	startSubsystems()
+
+	// Server-pushed configuration: apply, optionally persist, and ack
+	// with the hash of the now-active configuration.
+	client.RegisterHandler("config_update", func(msg *websocket.Message) {
+		updates, _ := msg.Data["config"].(map[string]interface{})
+		persist, _ := msg.Data["persist"].(bool)
+		reply := websocket.NewMessage("config_update", map[string]interface{}{})
+		reply.MessageID = msg.MessageID
+		hash, err := mgr.Apply(updates, persist)
+		if err != nil {
+			slog.Warn("config update rejected", "error", err)
+			reply.Data["error"] = err.Error()
+		} else {
+			slog.Info("config update applied", "hash", hash, "persisted", persist)
+			reply.Data["status"] = "applied"
+			reply.Data["config_hash"] = hash
+		}
+		client.Send(reply)
+	})
+	mgr.Subscribe(func(newCfg *config.Config) {
+		setupLogging(newCfg.LogLevel)
+		// Restart periodic subsystems so new intervals take effect.
+		subsysMu.Lock()
+		running := hb != nil
+		subsysMu.Unlock()
+		if running {
+			stopSubsystems()
+			startSubsystems()
+		}
+	})
+
+	// Temporary log level bumps: SIGUSR1 or a set_log_level message
+	// raises verbosity, SIGUSR2 reverts, and an auto-revert timer makes
+	// sure debug logging is never left on forever.
+	const logRevertAfter = 15 * time.Minute
+	var (
+		logMu       sync.Mutex
+		revertTimer *time.Timer
+	)
+	revertLogLevel := func() {
+		logMu.Lock()
+		defer logMu.Unlock()
+		if revertTimer != nil {
+			revertTimer.Stop()
+			revertTimer = nil
+		}
+		level := mgr.Current().LogLevel
+		setupLogging(level)
+		slog.Info("log level reverted", "level", level)
+	}
+	bumpLogLevel := func(level string, d time.Duration) {
+		logMu.Lock()
+		defer logMu.Unlock()
+		if revertTimer != nil {
+			revertTimer.Stop()
+		}
+		setupLogging(level)
+		slog.Info("log level changed", "level", level, "revert_after", d)
+		revertTimer = time.AfterFunc(d, revertLogLevel)
+	}
+	client.RegisterHandler("set_log_level", func(msg *websocket.Message) {
+		level, _ := msg.Data["level"].(string)
+		reply := websocket.NewMessage("set_log_level", map[string]interface{}{})
+		reply.MessageID = msg.MessageID
+		switch level {
+		case "debug", "info", "warn", "error":
+			d := logRevertAfter
+			if secs, ok := msg.Data["duration_seconds"].(float64); ok && secs > 0 {
+				d = time.Duration(secs) * time.Second
+			}
+			bumpLogLevel(level, d)
+			reply.Data["status"] = "applied"
+			reply.Data["level"] = level
+		default:
+			reply.Data["error"] = fmt.Sprintf("unknown log level %q", level)
+		}
+		client.Send(reply)
+	})
+
+	// Reload the file on change or on SIGHUP; an invalid file keeps the
+	// running configuration.
+	stopWatch := mgr.Watch(*configPath, 0)
+	defer stopWatch()
+
+	// With Vault configured, periodic reloads re-fetch rotated
+	// credentials on the renewal interval.
+	if cfg.Vault.Addr != "" {
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.Vault.RenewIntervalSeconds) * time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := mgr.Reload(); err != nil {
+					slog.Warn("vault credential refresh failed", "error", err)
+				}
+			}
+		}()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signals := append([]os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP},
+		logLevelSignals...)
+	signal.Notify(sigCh, signals...)
+
+	go connectLoop(client, cfg, reconnect)
+
+loop:
+	for sig := range sigCh {
+		switch {
+		case sig == syscall.SIGHUP:
+			if hash, err := mgr.Reload(); err != nil {
+				slog.Warn("config reload failed, keeping previous", "error", err)
+			} else {
+				slog.Info("config reloaded", "hash", hash)
+			}
+		case isLogBumpSignal(sig):
+			bumpLogLevel("debug", logRevertAfter)
+		case isLogRevertSignal(sig):
+			revertLogLevel()
+		default:
+			break loop
+		}
+	}
+	slog.Info("shutting down")
+	// Stop producers first so the queue can only shrink, then flush
+	// what is already queued before closing the connection.
+	stopSubsystems()
+	executor.CloseAll()
+	client.Drain(time.Duration(cfg.DrainTimeout) * time.Second)
+}
+
+// runEncrypt implements `agent encrypt [-key-file path] [value]`,
+// printing the enc: form of a credential for pasting into the
+// configuration file. The key file is created on first use. With no
+// value argument the plaintext is read from stdin, keeping it out of
+// shell history.
+func runEncrypt(args []string) int {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	keyFile := fs.String("key-file", config.DefaultCredentialsKeyPath, "path to the machine-local key file")
+	fs.Parse(args)
+
+	var plaintext string
+	if fs.NArg() > 0 {
+		plaintext = fs.Arg(0)
+	} else {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "agent: read stdin: %v\n", err)
+			return 1
+		}
+		plaintext = strings.TrimSuffix(string(data), "\n")
+	}
+	key, err := config.LoadOrCreateKey(*keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	out, err := config.EncryptValue(key, plaintext)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	fmt.Println(out)
+	return 0
+}
+
+// runConfig implements `agent config <validate|show> [-config path]`,
+// so operators can check a config file (including env overrides and
+// secret resolution) without starting the agent.
+func runConfig(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: agent config <validate|show|init> [-config path]")
+		return 2
+	}
+	sub := args[0]
+	if sub == "init" {
+		return runConfigInit(args[1:])
+	}
+	fs := flag.NewFlagSet("config "+sub, flag.ExitOnError)
+	path := fs.String("config", "/etc/agent/config.yaml", "path to the configuration file")
+	profile := fs.String("profile", os.Getenv("AGENT_PROFILE"), "named config profile to apply")
+	fs.Parse(args[1:])
+
+	cfg, err := config.LoadConfigProfile(*path, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	switch sub {
+	case "validate":
+		fmt.Printf("%s: OK (hash %s)\n", *path, cfg.Hash())
+	case "show":
+		out, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	default:
+		fmt.Fprintf(os.Stderr, "agent: unknown config subcommand %q\n", sub)
+		return 2
+	}
+	return 0
+}
+
+// runConfigInit implements `agent config init [-write path]`, emitting
+// a sample configuration generated from the Config struct and its
+// defaults so new sections appear automatically as they are added.
+func runConfigInit(args []string) int {
+	fs := flag.NewFlagSet("config init", flag.ExitOnError)
+	write := fs.String("write", "", "write the sample to this path instead of stdout")
+	fs.Parse(args)
+
+	sample := config.MarshalYAML(config.SampleConfig())
+	if *write == "" {
+		os.Stdout.Write(sample)
+		return 0
+	}
+	if _, err := os.Stat(*write); err == nil {
+		fmt.Fprintf(os.Stderr, "agent: %s already exists, not overwriting\n", *write)
+		return 1
+	}
+	if err := os.WriteFile(*write, sample, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	fmt.Printf("wrote %s\n", *write)
+	return 0
+}
+
+// runInventoryCmd implements `agent inventory [-config path]`: collect
+// once, apply the configured section filters, and pretty-print to
+// stdout without touching the server. Handy for debugging collectors
+// and for air-gapped hosts.
+func runInventoryCmd(args []string) int {
+	fs := flag.NewFlagSet("inventory", flag.ExitOnError)
+	path := fs.String("config", "/etc/agent/config.yaml", "path to the configuration file")
+	profile := fs.String("profile", os.Getenv("AGENT_PROFILE"), "named config profile to apply")
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfigProfile(*path, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	inv, err := inventory.NewCollector().Collect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: collect: %v\n", err)
+		return 1
+	}
+	filterSections(cfg, inv)
+	out, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agent: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
+
+// fetchBootstrap asks the server for the remainder of the
+// configuration, applies it, and caches it for later starts. The local
+// file still wins over cached values on the next load.
+func fetchBootstrap(client *websocket.Client, mgr *config.Manager, cfg *config.Config) {
+	reply, err := client.SendRequest("config_bootstrap", map[string]interface{}{
+		"client_id": cfg.ClientID,
+	}, 30*time.Second)
+	if err != nil {
+		slog.Warn("bootstrap fetch failed", "error", err)
+		return
+	}
+	updates, _ := reply["config"].(map[string]interface{})
+	if len(updates) == 0 {
+		slog.Info("bootstrap: server returned no configuration")
+		return
+	}
+	hash, err := mgr.Apply(updates, false)
+	if err != nil {
+		slog.Warn("bootstrap config rejected", "error", err)
+		return
+	}
+	if err := config.SaveBootstrapCache(cfg.Bootstrap.CachePath, updates); err != nil {
+		slog.Warn("bootstrap cache write failed", "error", err)
+	}
+	slog.Info("bootstrap configuration applied", "hash", hash)
+}
+
+// connectLoop keeps the client connected, retrying on the configured
+// interval after failures and disconnects.
+func connectLoop(client *websocket.Client, cfg *config.Config, reconnect <-chan struct{}) {
+	for {
+		if err := client.Connect(); err != nil {
+			slog.Warn("connect failed", "error", err)
+			time.Sleep(cfg.ReconnectInterval.Duration())
+			continue
+		}
+		<-reconnect
+		time.Sleep(cfg.ReconnectInterval.Duration())
+	}
+}
+
+// filterSections clears local inventory sections whose collector is
+// disabled in the configuration.
+func filterSections(cfg *config.Config, inv *inventory.Inventory) {
+	if !cfg.CollectorEnabled("host") {
+		inv.KernelVersion = ""
+		inv.Uptime = 0
+	}
+	if !cfg.CollectorEnabled("cpu") {
+		inv.CPU = inventory.CPUInfo{}
+	}
+	if !cfg.CollectorEnabled("memory") {
+		inv.Memory = inventory.MemoryInfo{}
+	}
+	if !cfg.CollectorEnabled("disk") {
+		inv.Disks = nil
+	}
+	if !cfg.CollectorEnabled("network") {
+		inv.Network = nil
+		inv.NetworkConfig = nil
+	}
+	if !cfg.CollectorEnabled("pci") {
+		inv.PCIDevices = nil
+	}
+	if !cfg.CollectorEnabled("containers") {
+		inv.Containers = nil
+	}
+	if !cfg.CollectorEnabled("ports") {
+		inv.ListeningPorts = nil
+	}
+	if !cfg.CollectorEnabled("cloud") {
+		inv.Cloud = nil
+	}
+	if !cfg.CollectorEnabled("numa") {
+		inv.NUMA = nil
+	}
+	if !cfg.CollectorEnabled("sensors") {
+		inv.Sensors = nil
+	}
+	if !cfg.CollectorEnabled("firmware") {
+		inv.Firmware = nil
+	}
+	if !cfg.CollectorEnabled("timesync") {
+		inv.TimeSync = nil
+	}
+	if cfg.CollectorEnabled("certs") &&
+		(len(cfg.CertScan.Files) > 0 || len(cfg.CertScan.Ports) > 0) {
+		inv.Certificates = inventory.ScanCertificates(cfg.CertScan.Files, cfg.CertScan.Ports)
+	}
+	if !cfg.CollectorEnabled("kernel") {
+		inv.KernelModules = nil
+	} else {
+		inv.Sysctls = inventory.ReadSysctls(cfg.Sysctls)
+	}
+	// The process and user snapshots are opt-in: they are heavier and
+	// more sensitive than the other sections.
+	if !cfg.CollectorOptedIn("processes") {
+		inv.Processes = nil
+	}
+	if !cfg.CollectorOptedIn("users") {
+		inv.Users = nil
+	}
+	if !cfg.CollectorOptedIn("power") {
+		inv.Batteries = nil
+		inv.UPS = nil
+	}
+	if !cfg.CollectorEnabled("services") {
+		inv.Services = nil
+	} else if len(cfg.Services.Units) > 0 {
+		inv.Services = filterServices(inv.Services, cfg.Services.Units)
+	}
+}
+
+// filterServices keeps only services whose unit name matches one of the
+// configured patterns (exact names or globs like "ssh*").
+func filterServices(services []inventory.ServiceInfo, patterns []string) []inventory.ServiceInfo {
+	var out []inventory.ServiceInfo
+	for _, svc := range services {
+		for _, pat := range patterns {
+			if ok, _ := path.Match(pat, svc.Name); ok || pat == svc.Name {
+				out = append(out, svc)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// sendJSON marshals v into the message data map and sends it.
+func sendJSON(client *websocket.Client, msgType string, v interface{}) {
+	data, err := inventory.ToMap(v)
+	if err != nil {
+		slog.Warn("marshal inventory", "type", msgType, "error", err)
+		return
+	}
+	if err := client.Send(websocket.NewMessage(msgType, data)); err != nil {
+		slog.Warn("send failed", "type", msgType, "error", err)
+	}
+}
+
+func setupLogging(level string) {
+	var l slog.Level
+	switch level {
+	case "debug":
+		l = slog.LevelDebug
+	case "warn":
+		l = slog.LevelWarn
+	case "error":
+		l = slog.LevelError
+	default:
+		l = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})))
+}
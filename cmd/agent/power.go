@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerPowerHandler services power_action messages: look up the BMC
+// target, run the requested reset, and report the result keyed to the
+// request's message id. The config policy is consulted per request, so
+// a pushed config change takes effect immediately.
+func registerPowerHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("power_action", func(msg *websocket.Message) {
+		action, _ := msg.Data["action"].(string)
+		target, _ := msg.Data["target"].(string)
+		// A slow BMC takes retries and backoff to give up on; never
+		// block the receive loop on it.
+		go func() {
+			reply := websocket.NewMessage("power_action", map[string]interface{}{
+				"action": action,
+				"target": target,
+			})
+			reply.MessageID = msg.MessageID
+			if err := runPowerAction(mgr.Current(), action, target); err != nil {
+				slog.Warn("power action failed", "action", action, "target", target, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				slog.Info("power action executed", "action", action, "target", target)
+				reply.Data["status"] = "ok"
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("power action reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runPowerAction enforces the config policy and executes the action
+// against the named BMC target.
+func runPowerAction(cfg *config.Config, action, target string) error {
+	if !cfg.PowerControl.Enabled {
+		return fmt.Errorf("power control is disabled on this agent")
+	}
+	if !bmc.ResetTypes[action] {
+		return fmt.Errorf("unknown power action %q", action)
+	}
+	if !cfg.PowerControl.ActionAllowed(action) {
+		return fmt.Errorf("power action %q is not permitted by policy", action)
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return err
+	}
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	return c.PowerAction(action)
+}
+
+// findBMCTarget resolves a target name to its BMC configuration. An
+// empty name is accepted when exactly one target is configured.
+func findBMCTarget(cfg *config.Config, target string) (*config.BMCConfig, error) {
+	var candidates []*config.BMCConfig
+	for i := range cfg.BMC {
+		if cfg.BMC[i].IP == "" {
+			continue
+		}
+		if cfg.BMC[i].Name == target {
+			return &cfg.BMC[i], nil
+		}
+		candidates = append(candidates, &cfg.BMC[i])
+	}
+	if target == "" && len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, fmt.Errorf("no BMC target %q configured", target)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonschuang/demo/pkg/bmc"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// registerPowerCapHandler services set_power_cap messages for
+// rack-level power management. A watts value of zero removes the cap.
+// Capping shares the power_control policy with power actions.
+func registerPowerCapHandler(client *websocket.Client, mgr *config.Manager) {
+	client.RegisterHandler("set_power_cap", func(msg *websocket.Message) {
+		target, _ := msg.Data["target"].(string)
+		// Reading capacity and applying the cap are BMC round trips;
+		// keep them off the receive loop.
+		go func() {
+			reply := websocket.NewMessage("set_power_cap", map[string]interface{}{
+				"target": target,
+			})
+			reply.MessageID = msg.MessageID
+			result, err := runSetPowerCap(mgr.Current(), target, msg.Data)
+			if err != nil {
+				slog.Warn("power cap change failed", "target", target, "error", err)
+				reply.Data["error"] = err.Error()
+			} else {
+				slog.Info("power cap changed", "target", target, "watts", result["limit_watts"])
+				for k, v := range result {
+					reply.Data[k] = v
+				}
+			}
+			if err := client.Send(reply); err != nil {
+				slog.Warn("power cap reply failed", "error", err)
+			}
+		}()
+	})
+}
+
+// runSetPowerCap enforces the policy, bounds-checks the requested cap
+// against the chassis power capacity, and applies it.
+func runSetPowerCap(cfg *config.Config, target string, data map[string]interface{}) (map[string]interface{}, error) {
+	if !cfg.PowerControl.Enabled {
+		return nil, fmt.Errorf("power control is disabled on this agent")
+	}
+	t, err := findBMCTarget(cfg, target)
+	if err != nil {
+		return nil, err
+	}
+	watts, isFloat := data["watts"].(float64)
+	if !isFloat || watts < 0 {
+		return nil, fmt.Errorf("set_power_cap requires a non-negative watts value")
+	}
+	c := bmc.NewClient(*t, cfg.ProxyEnabled())
+	defer c.Logout()
+	current, err := c.PowerCap()
+	if err != nil {
+		return nil, err
+	}
+	if watts > 0 && current.CapacityWatts > 0 && watts > current.CapacityWatts {
+		return nil, fmt.Errorf("cap of %.0fW exceeds chassis capacity of %.0fW", watts, current.CapacityWatts)
+	}
+	if err := c.SetPowerCap(watts); err != nil {
+		return nil, err
+	}
+	result := ok()
+	result["limit_watts"] = watts
+	if current.CapacityWatts > 0 {
+		result["capacity_watts"] = current.CapacityWatts
+	}
+	return result, nil
+}
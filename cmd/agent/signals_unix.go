@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// logLevelSignals are the extra signals driving temporary log level
+// changes; SIGUSR1/SIGUSR2 do not exist on Windows.
+var logLevelSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
+
+func isLogBumpSignal(sig os.Signal) bool   { return sig == syscall.SIGUSR1 }
+func isLogRevertSignal(sig os.Signal) bool { return sig == syscall.SIGUSR2 }
@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// SIGUSR1/SIGUSR2 do not exist on Windows; log level changes are only
+// available through the set_log_level message there.
+var logLevelSignals []os.Signal
+
+func isLogBumpSignal(os.Signal) bool   { return false }
+func isLogRevertSignal(os.Signal) bool { return false }
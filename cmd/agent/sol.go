@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/simonschuang/demo/pkg/config"
+)
+
+// solCommandProvider builds the terminal executor's sol session
+// command: ipmitool bridging Serial-over-LAN to the named BMC target.
+// The command runs under a PTY, so console output and keystrokes flow
+// through the normal terminal_output / terminal_command path.
+func solCommandProvider(mgr *config.Manager) func(target string) (*exec.Cmd, error) {
+	return func(target string) (*exec.Cmd, error) {
+		cfg := mgr.Current()
+		if !cfg.SOLConsole.Enabled {
+			return nil, fmt.Errorf("sol console is disabled on this agent")
+		}
+		t, err := findBMCTarget(cfg, target)
+		if err != nil {
+			return nil, err
+		}
+		// A session dropped without detaching leaves the SOL payload
+		// active on the BMC, and activate then fails. Clear it first.
+		ipmiCommand(t, "sol", "deactivate").Run()
+		return ipmiCommand(t, "sol", "activate"), nil
+	}
+}
+
+// ipmiCommand builds an ipmitool invocation for the target over the
+// lanplus interface. The password travels in the environment via -E:
+// the activate process lives for the whole console session, so an
+// argv password would sit visible in the process list for minutes.
+func ipmiCommand(t *config.BMCConfig, args ...string) *exec.Cmd {
+	base := []string{"-I", "lanplus", "-H", t.IP, "-U", t.Username, "-E"}
+	cmd := exec.Command("ipmitool", append(base, args...)...)
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+t.Password)
+	return cmd
+}
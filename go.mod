@@ -0,0 +1,3 @@
+module github.com/simonschuang/demo
+
+go 1.21
@@ -0,0 +1,63 @@
+package certpin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func selfSigned(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestApplyAcceptsPinnedKey(t *testing.T) {
+	cert := selfSigned(t)
+	cfg := &tls.Config{}
+	Apply(cfg, []string{"sha256/" + Fingerprint(cert)})
+	if cfg.VerifyPeerCertificate == nil {
+		t.Fatal("VerifyPeerCertificate not installed")
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{cert.Raw}, nil); err != nil {
+		t.Errorf("pinned cert rejected: %v", err)
+	}
+}
+
+func TestApplyRejectsUnpinnedKey(t *testing.T) {
+	pinned, other := selfSigned(t), selfSigned(t)
+	cfg := &tls.Config{}
+	Apply(cfg, []string{Fingerprint(pinned)})
+	if err := cfg.VerifyPeerCertificate([][]byte{other.Raw}, nil); err == nil {
+		t.Error("unpinned cert accepted")
+	}
+}
+
+func TestApplyWithNoPinsIsNoop(t *testing.T) {
+	cfg := &tls.Config{}
+	Apply(cfg, nil)
+	if cfg.VerifyPeerCertificate != nil || cfg.InsecureSkipVerify {
+		t.Error("empty pin list modified the TLS config")
+	}
+}
@@ -0,0 +1,59 @@
+// Package jitter provides a ticker whose period is randomized around a
+// base interval. Large fleets of identically configured agents would
+// otherwise phase-lock and hit the server in the same second; spreading
+// each tick smooths that load.
+package jitter
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Ticker delivers ticks like time.Ticker, but each period is drawn
+// uniformly from [d*(1-p/100), d*(1+p/100)] where p is the configured
+// jitter percentage. Every tick is re-randomized, so agents drift apart
+// over time instead of merely starting offset.
+type Ticker struct {
+	C    <-chan time.Time
+	stop chan struct{}
+}
+
+// NewTicker returns a jittered ticker. A percent of zero (or less)
+// degrades to a plain fixed-period ticker.
+func NewTicker(d time.Duration, percent int) *Ticker {
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, stop: make(chan struct{})}
+	go t.run(c, d, percent)
+	return t
+}
+
+// Stop halts the ticker. As with time.Ticker, it does not close C.
+func (t *Ticker) Stop() {
+	close(t.stop)
+}
+
+func (t *Ticker) run(c chan<- time.Time, d time.Duration, percent int) {
+	timer := time.NewTimer(period(d, percent))
+	defer timer.Stop()
+	for {
+		select {
+		case now := <-timer.C:
+			select {
+			case c <- now:
+			default: // receiver is busy; drop the tick like time.Ticker
+			}
+			timer.Reset(period(d, percent))
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// period returns the next tick interval with jitter applied.
+func period(d time.Duration, percent int) time.Duration {
+	if percent <= 0 || d <= 0 {
+		return d
+	}
+	span := d * time.Duration(percent) / 100
+	return d - span + time.Duration(rand.Int63n(int64(2*span)+1))
+}
@@ -0,0 +1,32 @@
+package jitter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	base := 10 * time.Second
+	for i := 0; i < 1000; i++ {
+		p := period(base, 20)
+		if p < 8*time.Second || p > 12*time.Second {
+			t.Fatalf("period = %v, want within ±20%% of %v", p, base)
+		}
+	}
+}
+
+func TestPeriodZeroJitterIsExact(t *testing.T) {
+	if p := period(time.Minute, 0); p != time.Minute {
+		t.Errorf("period = %v, want exactly 1m", p)
+	}
+}
+
+func TestTickerDeliversAndStops(t *testing.T) {
+	tk := NewTicker(5*time.Millisecond, 20)
+	select {
+	case <-tk.C:
+	case <-time.After(time.Second):
+		t.Fatal("no tick within 1s")
+	}
+	tk.Stop()
+}
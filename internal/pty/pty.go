@@ -0,0 +1,17 @@
+// Package pty starts commands attached to a pseudo-terminal: a Unix
+// PTY on Linux, a ConPTY pseudo console on Windows.
+package pty
+
+import "io"
+
+// Session is one running terminal. Reads return terminal output,
+// writes feed keystrokes, and Close tears the terminal down.
+type Session interface {
+	io.ReadWriteCloser
+	// Resize sets the terminal window size.
+	Resize(rows, cols uint16) error
+	// Wait blocks until the process exits.
+	Wait() error
+	// Kill terminates the process.
+	Kill() error
+}
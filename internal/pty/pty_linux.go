@@ -0,0 +1,93 @@
+//go:build linux
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// unixSession is a command attached to a Unix PTY; the master side
+// carries the terminal I/O.
+type unixSession struct {
+	master *os.File
+	cmd    *exec.Cmd
+}
+
+// Start launches cmd with its controlling terminal set to a new PTY.
+func Start(cmd *exec.Cmd) (Session, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	slaveName, err := unlock(master)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	slave, err := os.OpenFile(slaveName, os.O_RDWR, 0)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	defer slave.Close()
+
+	cmd.Stdin = slave
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, err
+	}
+	return &unixSession{master: master, cmd: cmd}, nil
+}
+
+func (s *unixSession) Read(p []byte) (int, error)  { return s.master.Read(p) }
+func (s *unixSession) Write(p []byte) (int, error) { return s.master.Write(p) }
+func (s *unixSession) Close() error                { return s.master.Close() }
+
+func (s *unixSession) Wait() error { return s.cmd.Wait() }
+
+func (s *unixSession) Kill() error {
+	if s.cmd.Process == nil {
+		return nil
+	}
+	return s.cmd.Process.Kill()
+}
+
+// Resize sets the terminal window size on the PTY master.
+func (s *unixSession) Resize(rows, cols uint16) error {
+	ws := struct {
+		rows, cols, x, y uint16
+	}{rows, cols, 0, 0}
+	return ioctl(s.master.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
+
+// unlock unlocks the slave side of the PTY and returns its device path.
+func unlock(master *os.File) (string, error) {
+	var n uint32
+	if err := ioctl(master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		return "", err
+	}
+	var unlockArg int32
+	if err := ioctl(master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlockArg))); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/dev/pts/%d", n), nil
+}
+
+func ioctl(fd, request, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
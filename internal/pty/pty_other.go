@@ -0,0 +1,16 @@
+//go:build !linux && !windows
+
+package pty
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// ErrUnsupported is returned on platforms without PTY support.
+var ErrUnsupported = errors.New("pty: not supported on this platform")
+
+// Start is not supported on this platform.
+func Start(cmd *exec.Cmd) (Session, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,205 @@
+//go:build windows
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// ConPTY-backed sessions. The pseudo console API (Windows 10 1809+)
+// gives the same byte-stream terminal semantics as a Unix PTY: one
+// pipe carries VT-encoded output, the other keystrokes.
+
+var (
+	kernel32                              = syscall.NewLazyDLL("kernel32.dll")
+	procCreatePseudoConsole               = kernel32.NewProc("CreatePseudoConsole")
+	procResizePseudoConsole               = kernel32.NewProc("ResizePseudoConsole")
+	procClosePseudoConsole                = kernel32.NewProc("ClosePseudoConsole")
+	procInitializeProcThreadAttributeList = kernel32.NewProc("InitializeProcThreadAttributeList")
+	procUpdateProcThreadAttribute         = kernel32.NewProc("UpdateProcThreadAttribute")
+	procDeleteProcThreadAttributeList     = kernel32.NewProc("DeleteProcThreadAttributeList")
+)
+
+const (
+	procThreadAttributePseudoConsole = 0x20016
+	extendedStartupInfoPresent       = 0x00080000
+	createUnicodeEnvironment         = 0x00000400
+)
+
+// startupInfoEx is STARTUPINFOEXW: the base StartupInfo followed by
+// the attribute list carrying the pseudo console handle.
+type startupInfoEx struct {
+	startupInfo   syscall.StartupInfo
+	attributeList *byte
+}
+
+type conptySession struct {
+	hpc     uintptr
+	process syscall.Handle
+	// in feeds keystrokes to the console; out carries its output.
+	in  *os.File
+	out *os.File
+}
+
+// coordValue packs a COORD (passed by value) into one register.
+func coordValue(cols, rows uint16) uintptr {
+	return uintptr(cols) | uintptr(rows)<<16
+}
+
+// Start launches cmd attached to a new pseudo console. Only the
+// command specification (Path, Args, Env, Dir) of cmd is used; the
+// process is created through CreateProcess so the console can be
+// attached.
+func Start(cmd *exec.Cmd) (Session, error) {
+	var inR, inW, outR, outW syscall.Handle
+	if err := syscall.CreatePipe(&inR, &inW, nil, 0); err != nil {
+		return nil, err
+	}
+	if err := syscall.CreatePipe(&outR, &outW, nil, 0); err != nil {
+		syscall.CloseHandle(inR)
+		syscall.CloseHandle(inW)
+		return nil, err
+	}
+	closeAll := func() {
+		syscall.CloseHandle(inR)
+		syscall.CloseHandle(inW)
+		syscall.CloseHandle(outR)
+		syscall.CloseHandle(outW)
+	}
+	var hpc uintptr
+	if hr, _, _ := procCreatePseudoConsole.Call(coordValue(80, 24),
+		uintptr(inR), uintptr(outW), 0, uintptr(unsafe.Pointer(&hpc))); hr != 0 {
+		closeAll()
+		return nil, fmt.Errorf("CreatePseudoConsole: %#x", hr)
+	}
+	pi, err := createProcessWithConsole(cmd, hpc)
+	if err != nil {
+		procClosePseudoConsole.Call(hpc)
+		closeAll()
+		return nil, err
+	}
+	syscall.CloseHandle(pi.Thread)
+	// The console duplicated its ends of the pipes.
+	syscall.CloseHandle(inR)
+	syscall.CloseHandle(outW)
+	return &conptySession{
+		hpc:     hpc,
+		process: pi.Process,
+		in:      os.NewFile(uintptr(inW), "|conpty-in"),
+		out:     os.NewFile(uintptr(outR), "|conpty-out"),
+	}, nil
+}
+
+// createProcessWithConsole runs cmd with the pseudo console attached
+// through the process thread attribute list.
+func createProcessWithConsole(cmd *exec.Cmd, hpc uintptr) (*syscall.ProcessInformation, error) {
+	var attrSize uintptr
+	procInitializeProcThreadAttributeList.Call(0, 1, 0, uintptr(unsafe.Pointer(&attrSize)))
+	attrList := make([]byte, attrSize)
+	if ok, _, err := procInitializeProcThreadAttributeList.Call(
+		uintptr(unsafe.Pointer(&attrList[0])), 1, 0, uintptr(unsafe.Pointer(&attrSize))); ok == 0 {
+		return nil, fmt.Errorf("InitializeProcThreadAttributeList: %v", err)
+	}
+	defer procDeleteProcThreadAttributeList.Call(uintptr(unsafe.Pointer(&attrList[0])))
+	if ok, _, err := procUpdateProcThreadAttribute.Call(
+		uintptr(unsafe.Pointer(&attrList[0])), 0, procThreadAttributePseudoConsole,
+		hpc, unsafe.Sizeof(hpc), 0, 0); ok == 0 {
+		return nil, fmt.Errorf("UpdateProcThreadAttribute: %v", err)
+	}
+
+	var siEx startupInfoEx
+	siEx.startupInfo.Cb = uint32(unsafe.Sizeof(siEx))
+	siEx.attributeList = &attrList[0]
+	cmdline, err := syscall.UTF16PtrFromString(commandLine(cmd))
+	if err != nil {
+		return nil, err
+	}
+	var dir *uint16
+	if cmd.Dir != "" {
+		if dir, err = syscall.UTF16PtrFromString(cmd.Dir); err != nil {
+			return nil, err
+		}
+	}
+	var pi syscall.ProcessInformation
+	err = syscall.CreateProcess(nil, cmdline, nil, nil, false,
+		extendedStartupInfoPresent|createUnicodeEnvironment,
+		envBlock(cmd.Env), dir,
+		(*syscall.StartupInfo)(unsafe.Pointer(&siEx)), &pi)
+	if err != nil {
+		return nil, fmt.Errorf("CreateProcess: %w", err)
+	}
+	return &pi, nil
+}
+
+// commandLine renders the command in Windows quoting rules.
+func commandLine(cmd *exec.Cmd) string {
+	args := cmd.Args
+	if len(args) == 0 {
+		args = []string{cmd.Path}
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = syscall.EscapeArg(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// envBlock renders an environment in the NUL-separated block
+// CreateProcess expects; nil inherits the agent's environment.
+func envBlock(env []string) *uint16 {
+	if env == nil {
+		return nil
+	}
+	var block []uint16
+	for _, e := range env {
+		u, err := syscall.UTF16FromString(e)
+		if err != nil {
+			continue
+		}
+		block = append(block, u...)
+	}
+	block = append(block, 0)
+	return &block[0]
+}
+
+func (s *conptySession) Read(p []byte) (int, error)  { return s.out.Read(p) }
+func (s *conptySession) Write(p []byte) (int, error) { return s.in.Write(p) }
+
+// Close shuts the pseudo console down, which also ends the output
+// stream.
+func (s *conptySession) Close() error {
+	procClosePseudoConsole.Call(s.hpc)
+	s.in.Close()
+	return s.out.Close()
+}
+
+func (s *conptySession) Wait() error {
+	if _, err := syscall.WaitForSingleObject(s.process, syscall.INFINITE); err != nil {
+		return err
+	}
+	var code uint32
+	if err := syscall.GetExitCodeProcess(s.process, &code); err != nil {
+		return err
+	}
+	syscall.CloseHandle(s.process)
+	if code != 0 {
+		return fmt.Errorf("exit status %d", code)
+	}
+	return nil
+}
+
+func (s *conptySession) Kill() error {
+	return syscall.TerminateProcess(s.process, 1)
+}
+
+func (s *conptySession) Resize(rows, cols uint16) error {
+	if hr, _, _ := procResizePseudoConsole.Call(s.hpc, coordValue(cols, rows)); hr != 0 {
+		return fmt.Errorf("ResizePseudoConsole: %#x", hr)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Package tomlx implements the small TOML subset the agent's
+// configuration needs: tables, arrays of tables, and scalar or array
+// values. Like yamlx it exists to keep the agent free of third-party
+// dependencies; it is not a general-purpose TOML parser.
+package tomlx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses TOML into v via the json package, so the same
+// field tags drive YAML, TOML, and JSON configuration.
+func Unmarshal(data []byte, v interface{}) error {
+	tree, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Parse converts TOML to nested maps, slices, and scalars.
+func Parse(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	current := root
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := stripComment(raw)
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "[[") && strings.HasSuffix(line, "]]"):
+			name := strings.TrimSpace(line[2 : len(line)-2])
+			parent, last, err := descend(root, name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			entry := map[string]interface{}{}
+			list, _ := parent[last].([]interface{})
+			parent[last] = append(list, entry)
+			current = entry
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			parent, last, err := descend(root, name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			table, ok := parent[last].(map[string]interface{})
+			if !ok {
+				if parent[last] != nil {
+					return nil, fmt.Errorf("line %d: %s redefined", n+1, name)
+				}
+				table = map[string]interface{}{}
+				parent[last] = table
+			}
+			current = table
+		default:
+			key, val, found := strings.Cut(line, "=")
+			if !found {
+				return nil, fmt.Errorf("line %d: expected key = value", n+1)
+			}
+			parsed, err := parseValue(strings.TrimSpace(val))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			current[strings.TrimSpace(key)] = parsed
+		}
+	}
+	return root, nil
+}
+
+// descend walks a dotted table name, creating intermediate tables, and
+// returns the parent map and final segment.
+func descend(root map[string]interface{}, name string) (map[string]interface{}, string, error) {
+	parts := strings.Split(name, ".")
+	current := root
+	for _, p := range parts[:len(parts)-1] {
+		p = strings.TrimSpace(p)
+		next, ok := current[p].(map[string]interface{})
+		if !ok {
+			if current[p] != nil {
+				return nil, "", fmt.Errorf("%s is not a table", p)
+			}
+			next = map[string]interface{}{}
+			current[p] = next
+		}
+		current = next
+	}
+	return current, strings.TrimSpace(parts[len(parts)-1]), nil
+}
+
+// parseValue converts a TOML value: quoted strings, booleans, numbers,
+// and single-line arrays.
+func parseValue(s string) (interface{}, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("empty value")
+	case strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) && len(s) >= 2:
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2:
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]"):
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return []interface{}{}, nil
+		}
+		var out []interface{}
+		for _, item := range splitArray(inner) {
+			v, err := parseValue(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	default:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unsupported value %q", s)
+	}
+}
+
+// splitArray splits array elements on commas outside quotes.
+func splitArray(s string) []string {
+	var out []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	out = append(out, s[start:])
+	return out
+}
+
+// stripComment removes a trailing # comment that is not inside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,89 @@
+package tomlx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTablesAndScalars(t *testing.T) {
+	got, err := Parse([]byte(`
+# agent config
+server_url = "wss://x/agent"
+heartbeat_interval = 30
+insecure_skip_verify = false
+pin_sha256 = ["abc", "def"]
+
+[rate_limit]
+bytes_per_sec = 1024
+
+[send_queue.overflow]
+inventory = "drop_oldest"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]interface{}{
+		"server_url":           "wss://x/agent",
+		"heartbeat_interval":   int64(30),
+		"insecure_skip_verify": false,
+		"pin_sha256":           []interface{}{"abc", "def"},
+		"rate_limit": map[string]interface{}{
+			"bytes_per_sec": int64(1024),
+		},
+		"send_queue": map[string]interface{}{
+			"overflow": map[string]interface{}{
+				"inventory": "drop_oldest",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseArrayOfTables(t *testing.T) {
+	got, err := Parse([]byte(`
+[[bmc]]
+ip = "10.0.0.1"
+username = "admin"
+
+[[bmc]]
+ip = "10.0.0.2"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	list, ok := got["bmc"].([]interface{})
+	if !ok || len(list) != 2 {
+		t.Fatalf("bmc = %#v", got["bmc"])
+	}
+	first := list[0].(map[string]interface{})
+	if first["ip"] != "10.0.0.1" || first["username"] != "admin" {
+		t.Errorf("first target = %#v", first)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, bad := range []string{
+		"key without equals",
+		"key = ",
+		"key = unquoted string",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	var out struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	if err := Unmarshal([]byte("name = \"x\"\ncount = 3\n"), &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Name != "x" || out.Count != 3 {
+		t.Errorf("Unmarshal = %+v", out)
+	}
+}
@@ -0,0 +1,133 @@
+// Package vault is a minimal HashiCorp Vault API client covering what
+// the agent needs: AppRole or token auth, KV v2 secret reads, and
+// token self-renewal. It deliberately avoids the official SDK to keep
+// the agent dependency-free.
+package vault
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to a single Vault server.
+type Client struct {
+	addr string
+	http *http.Client
+	// token is the client token used for authenticated requests. Set
+	// directly for token auth or obtained by Login for AppRole.
+	token string
+}
+
+// New returns a client for the Vault server at addr (e.g.
+// https://vault.example.com:8200). token may be empty when AppRole
+// login is used.
+func New(addr, token string, insecureSkipVerify bool) *Client {
+	return &Client{
+		addr: strings.TrimRight(addr, "/"),
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
+			},
+		},
+		token: token,
+	}
+}
+
+// Login performs an AppRole login and stores the resulting client
+// token for subsequent requests.
+func (c *Client) Login(roleID, secretID string) error {
+	body, err := c.do(http.MethodPost, "/v1/auth/approle/login", map[string]interface{}{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return fmt.Errorf("approle login: %w", err)
+	}
+	auth, _ := body["auth"].(map[string]interface{})
+	token, _ := auth["client_token"].(string)
+	if token == "" {
+		return fmt.Errorf("approle login: no client_token in response")
+	}
+	c.token = token
+	return nil
+}
+
+// ReadKV reads a KV v2 secret at mount/path and returns its data as
+// strings. Non-string values are ignored.
+func (c *Client) ReadKV(mount, path string) (map[string]string, error) {
+	body, err := c.do(http.MethodGet,
+		fmt.Sprintf("/v1/%s/data/%s", mount, strings.TrimLeft(path, "/")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("read %s/%s: %w", mount, path, err)
+	}
+	outer, _ := body["data"].(map[string]interface{})
+	inner, _ := outer["data"].(map[string]interface{})
+	if inner == nil {
+		return nil, fmt.Errorf("read %s/%s: unexpected response shape", mount, path)
+	}
+	out := make(map[string]string, len(inner))
+	for k, v := range inner {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out, nil
+}
+
+// RenewSelf extends the lease of the current client token.
+func (c *Client) RenewSelf() error {
+	_, err := c.do(http.MethodPost, "/v1/auth/token/renew-self", nil)
+	if err != nil {
+		return fmt.Errorf("renew token: %w", err)
+	}
+	return nil
+}
+
+// do issues a request and decodes the JSON response body.
+func (c *Client) do(method, path string, payload interface{}) (map[string]interface{}, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.addr+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+	if len(raw) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("decode vault response: %w", err)
+	}
+	return body, nil
+}
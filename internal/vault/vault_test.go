@@ -0,0 +1,89 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeVault serves just enough of the Vault HTTP API for the client.
+func fakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["role_id"] != "rid" || req["secret_id"] != "sid" {
+			http.Error(w, `{"errors":["invalid role"]}`, http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "s.logged-in"},
+		})
+	})
+	mux.HandleFunc("/v1/secret/data/agent/node-01", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") == "" {
+			http.Error(w, `{"errors":["missing token"]}`, http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{
+					"client_token": "agent-tok",
+					"bmc_password": "bmc-pw",
+					"ignored":      42,
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/v1/auth/token/renew-self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method", http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestAppRoleLoginAndReadKV(t *testing.T) {
+	srv := fakeVault(t)
+	defer srv.Close()
+
+	c := New(srv.URL, "", false)
+	if err := c.Login("rid", "sid"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	data, err := c.ReadKV("secret", "agent/node-01")
+	if err != nil {
+		t.Fatalf("ReadKV: %v", err)
+	}
+	if data["client_token"] != "agent-tok" || data["bmc_password"] != "bmc-pw" {
+		t.Errorf("ReadKV = %v", data)
+	}
+	if _, ok := data["ignored"]; ok {
+		t.Error("non-string value not dropped")
+	}
+	if err := c.RenewSelf(); err != nil {
+		t.Errorf("RenewSelf: %v", err)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	srv := fakeVault(t)
+	defer srv.Close()
+	c := New(srv.URL, "", false)
+	if err := c.Login("wrong", "creds"); err == nil {
+		t.Error("expected login failure")
+	}
+}
+
+func TestTokenAuthRead(t *testing.T) {
+	srv := fakeVault(t)
+	defer srv.Close()
+	c := New(srv.URL, "s.static", false)
+	if _, err := c.ReadKV("secret", "agent/node-01"); err != nil {
+		t.Errorf("ReadKV with static token: %v", err)
+	}
+}
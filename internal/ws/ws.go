@@ -0,0 +1,440 @@
+// Package ws implements the client side of the WebSocket protocol
+// (RFC 6455). It intentionally covers only what the agent needs: dialing
+// over TCP/TLS, text, binary and control frames, and client-side masking.
+// Keeping the implementation in-tree avoids an external dependency for
+// what is a small, stable protocol surface.
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types, mirroring the RFC 6455 opcode values.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxFrameSize bounds a single inbound frame. The payload buffer is
+// allocated from the server-advertised length, so without a cap a
+// misbehaving server could demand a multi-gigabyte allocation with one
+// spoofed header. 8 MiB comfortably covers the application's chunking
+// threshold.
+const maxFrameSize = 8 << 20
+
+// ErrFrameTooLarge is returned when the peer announces a frame larger
+// than maxFrameSize; the connection is no longer usable afterwards.
+var ErrFrameTooLarge = errors.New("ws: frame exceeds maximum size")
+
+// ErrBadHandshake is returned by Dial when the server does not complete
+// the WebSocket upgrade.
+var ErrBadHandshake = errors.New("ws: bad handshake")
+
+// CloseError is returned from ReadMessage when the peer sends a close
+// frame.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("ws: close %d %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket client connection. There may be at most one
+// concurrent reader and one concurrent writer of data messages, but
+// WriteControl may be called from other goroutines (the ping ticker,
+// the automatic pong reply, close paths) concurrently with a data
+// write: all frame writes and their deadline updates are serialized on
+// an internal mutex.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// writeMu serializes frame writes; writeDeadline is the deadline
+	// applied to data writes, guarded by the same mutex so a control
+	// frame's single-use deadline cannot clobber it.
+	writeMu       sync.Mutex
+	writeDeadline time.Time
+
+	// readIdle, when non-zero, re-arms the read deadline before every
+	// frame read. Only the reading goroutine touches it.
+	readIdle time.Duration
+}
+
+// Dialer contains options for establishing a WebSocket connection.
+type Dialer struct {
+	// TLSConfig is used for wss:// URLs. A nil config uses defaults.
+	TLSConfig *tls.Config
+	// HandshakeTimeout bounds the dial and upgrade. Zero means no limit.
+	HandshakeTimeout time.Duration
+	// NetDial, if non-nil, is used to establish the underlying TCP
+	// connection instead of net.Dial.
+	NetDial func(network, addr string) (net.Conn, error)
+	// Proxy, if non-nil, selects a proxy for the connection in the
+	// manner of http.Transport.Proxy (typically
+	// http.ProxyFromEnvironment, which also honors NO_PROXY). The
+	// connection is tunneled through the proxy with HTTP CONNECT.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// Dial opens a WebSocket connection to urlStr (ws:// or wss://) sending
+// any extra HTTP headers with the handshake.
+func (d *Dialer) Dial(urlStr string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var secure bool
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		secure = true
+	default:
+		return nil, nil, fmt.Errorf("ws: unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		if secure {
+			host += ":443"
+		} else {
+			host += ":80"
+		}
+	}
+	deadline := time.Time{}
+	if d.HandshakeTimeout > 0 {
+		deadline = time.Now().Add(d.HandshakeTimeout)
+	}
+	dial := d.NetDial
+	if dial == nil {
+		nd := &net.Dialer{Deadline: deadline}
+		dial = nd.Dial
+	}
+	proxyURL, err := d.proxyFor(u, secure)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialAddr := host
+	if proxyURL != nil {
+		dialAddr = proxyURL.Host
+		if proxyURL.Port() == "" {
+			dialAddr += ":80"
+		}
+	}
+	netConn, err := dial("tcp", dialAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !deadline.IsZero() {
+		netConn.SetDeadline(deadline)
+	}
+	if proxyURL != nil {
+		if err := proxyConnect(netConn, proxyURL, host); err != nil {
+			netConn.Close()
+			return nil, nil, err
+		}
+	}
+	if secure {
+		cfg := d.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(netConn, cfg)
+		if err := tlsConn.Handshake(); err != nil {
+			netConn.Close()
+			return nil, nil, err
+		}
+		netConn = tlsConn
+	}
+
+	key, accept := generateKey()
+	path := u.RequestURI()
+	var req strings.Builder
+	fmt.Fprintf(&req, "GET %s HTTP/1.1\r\n", path)
+	fmt.Fprintf(&req, "Host: %s\r\n", u.Host)
+	req.WriteString("Upgrade: websocket\r\n")
+	req.WriteString("Connection: Upgrade\r\n")
+	fmt.Fprintf(&req, "Sec-WebSocket-Key: %s\r\n", key)
+	req.WriteString("Sec-WebSocket-Version: 13\r\n")
+	for k, vs := range header {
+		for _, v := range vs {
+			fmt.Fprintf(&req, "%s: %s\r\n", k, v)
+		}
+	}
+	req.WriteString("\r\n")
+	if _, err := netConn.Write([]byte(req.String())); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: "GET", URL: u})
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != accept {
+		netConn.Close()
+		return nil, resp, ErrBadHandshake
+	}
+	netConn.SetDeadline(time.Time{})
+	return &Conn{conn: netConn, br: br}, resp, nil
+}
+
+// proxyFor asks the Proxy hook which proxy (if any) to use. The target
+// is presented with an http/https scheme so standard proxy selectors
+// like http.ProxyFromEnvironment apply their HTTPS_PROXY and NO_PROXY
+// rules to WebSocket URLs too.
+func (d *Dialer) proxyFor(u *url.URL, secure bool) (*url.URL, error) {
+	if d.Proxy == nil {
+		return nil, nil
+	}
+	target := *u
+	if secure {
+		target.Scheme = "https"
+	} else {
+		target.Scheme = "http"
+	}
+	return d.Proxy(&http.Request{URL: &target})
+}
+
+// proxyConnect establishes an HTTP CONNECT tunnel to host through an
+// already-dialed proxy connection.
+func proxyConnect(conn net.Conn, proxyURL *url.URL, host string) error {
+	var req strings.Builder
+	fmt.Fprintf(&req, "CONNECT %s HTTP/1.1\r\n", host)
+	fmt.Fprintf(&req, "Host: %s\r\n", host)
+	if u := proxyURL.User; u != nil {
+		pass, _ := u.Password()
+		cred := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + pass))
+		fmt.Fprintf(&req, "Proxy-Authorization: Basic %s\r\n", cred)
+	}
+	req.WriteString("\r\n")
+	if _, err := conn.Write([]byte(req.String())); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ws: proxy CONNECT %s via %s: %s", host, proxyURL.Host, resp.Status)
+	}
+	return nil
+}
+
+func generateKey() (key, accept string) {
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	key = base64.StdEncoding.EncodeToString(raw)
+	h := sha1.Sum([]byte(key + websocketGUID))
+	accept = base64.StdEncoding.EncodeToString(h[:])
+	return key, accept
+}
+
+// SetReadDeadline sets the deadline for future reads.
+func (c *Conn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetReadIdleTimeout arms an automatic read deadline: every frame read,
+// including the pings and pongs consumed inside ReadMessage, pushes the
+// deadline d into the future. This is how keepalive traffic keeps an
+// otherwise idle connection from being declared dead. Zero disables
+// the mechanism. Call from the reading goroutine before ReadMessage.
+func (c *Conn) SetReadIdleTimeout(d time.Duration) {
+	c.readIdle = d
+}
+
+// SetWriteDeadline sets the deadline applied to future data writes.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeMu.Lock()
+	c.writeDeadline = t
+	c.writeMu.Unlock()
+	return nil
+}
+
+// Close closes the underlying network connection without sending a close
+// frame.
+func (c *Conn) Close() error { return c.conn.Close() }
+
+// WriteMessage writes a complete data message with the given type.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(c.writeDeadline); err != nil {
+		return err
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WriteControl writes a control frame (close, ping or pong) with the
+// given single-use deadline. It is safe to call concurrently with
+// WriteMessage.
+func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	err := c.writeFrame(messageType, data)
+	c.conn.SetWriteDeadline(c.writeDeadline)
+	return err
+}
+
+// FormatCloseMessage formats a close payload from a code and text.
+func FormatCloseMessage(code int, text string) []byte {
+	buf := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(buf, uint16(code))
+	copy(buf[2:], text)
+	return buf
+}
+
+// writeFrame writes one complete frame as a single Write. The caller
+// must hold writeMu.
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	var hdr [14]byte
+	hdr[0] = 0x80 | byte(opcode) // FIN set, no fragmentation on write
+	n := 2
+	switch {
+	case len(payload) < 126:
+		hdr[1] = 0x80 | byte(len(payload))
+	case len(payload) <= 0xFFFF:
+		hdr[1] = 0x80 | 126
+		binary.BigEndian.PutUint16(hdr[2:], uint16(len(payload)))
+		n = 4
+	default:
+		hdr[1] = 0x80 | 127
+		binary.BigEndian.PutUint64(hdr[2:], uint64(len(payload)))
+		n = 10
+	}
+	var mask [4]byte
+	rand.Read(mask[:])
+	copy(hdr[n:], mask[:])
+	n += 4
+	frame := make([]byte, n+len(payload))
+	copy(frame, hdr[:n])
+	for i, b := range payload {
+		frame[n+i] = b ^ mask[i&3]
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// ReadMessage reads the next data message, transparently answering ping
+// frames and reassembling fragmented messages.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	var assembled []byte
+	assembledType := 0
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			c.WriteControl(PongMessage, payload, time.Now().Add(5*time.Second))
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			ce := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Text = string(payload[2:])
+			}
+			c.WriteControl(CloseMessage, FormatCloseMessage(ce.Code, ""), time.Now().Add(5*time.Second))
+			return 0, nil, ce
+		case 0: // continuation
+			if assembledType == 0 {
+				return 0, nil, errors.New("ws: unexpected continuation frame")
+			}
+			assembled = append(assembled, payload...)
+			if fin {
+				return assembledType, assembled, nil
+			}
+		case TextMessage, BinaryMessage:
+			if fin {
+				return opcode, payload, nil
+			}
+			assembledType = opcode
+			assembled = append(assembled[:0], payload...)
+		default:
+			return 0, nil, fmt.Errorf("ws: unknown opcode %d", opcode)
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	if c.readIdle > 0 {
+		if err = c.conn.SetReadDeadline(time.Now().Add(c.readIdle)); err != nil {
+			return
+		}
+	}
+	var hdr [2]byte
+	if _, err = io.ReadFull(c.br, hdr[:]); err != nil {
+		return
+	}
+	fin = hdr[0]&0x80 != 0
+	opcode = int(hdr[0] & 0x0F)
+	masked := hdr[1]&0x80 != 0
+	length := uint64(hdr[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxFrameSize {
+		err = ErrFrameTooLarge
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i&3]
+		}
+	}
+	return
+}
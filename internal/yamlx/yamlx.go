@@ -0,0 +1,266 @@
+// Package yamlx is a small YAML-subset parser covering the constructs
+// used by the agent configuration file: nested mappings by indentation,
+// block sequences, scalars (strings, integers, floats, booleans, null)
+// and '#' comments. Anchors, multi-line scalars and flow collections are
+// not supported. Keeping the parser in-tree avoids an external YAML
+// dependency for a deliberately small configuration surface.
+package yamlx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses YAML data into out. Mappings are parsed into
+// map[string]interface{} values and then decoded into out through its
+// json struct tags.
+func Unmarshal(data []byte, out interface{}) error {
+	root, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if m, ok := out.(*map[string]interface{}); ok {
+		if root == nil {
+			*m = map[string]interface{}{}
+		} else {
+			mm, ok := root.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("yamlx: document is not a mapping")
+			}
+			*m = mm
+		}
+		return nil
+	}
+	raw, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+type line struct {
+	num    int
+	indent int
+	text   string // content with indentation stripped
+}
+
+// Parse parses a YAML document into nested map[string]interface{},
+// []interface{} and scalar values. An empty document yields nil.
+func Parse(data []byte) (interface{}, error) {
+	var lines []line
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := stripComment(raw)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if strings.Contains(text, "\t") {
+			return nil, fmt.Errorf("yamlx: line %d: tabs are not allowed for indentation", i+1)
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		lines = append(lines, line{num: i + 1, indent: indent, text: strings.TrimSpace(text)})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	val, rest, err := parseBlock(lines, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("yamlx: line %d: unexpected indentation", rest[0].num)
+	}
+	return val, nil
+}
+
+func stripComment(s string) string {
+	inSingle, inDouble := false, false
+	for i, r := range s {
+		switch r {
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case '#':
+			if !inSingle && !inDouble && (i == 0 || s[i-1] == ' ') {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// parseBlock parses consecutive lines at exactly the given indent level
+// and returns the parsed value along with the unconsumed lines.
+func parseBlock(lines []line, indent int) (interface{}, []line, error) {
+	if len(lines) == 0 {
+		return nil, lines, nil
+	}
+	if strings.HasPrefix(lines[0].text, "- ") || lines[0].text == "-" {
+		return parseSequence(lines, indent)
+	}
+	return parseMapping(lines, indent)
+}
+
+func parseMapping(lines []line, indent int) (interface{}, []line, error) {
+	m := map[string]interface{}{}
+	for len(lines) > 0 {
+		l := lines[0]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, nil, fmt.Errorf("yamlx: line %d: unexpected indentation", l.num)
+		}
+		key, rest, found := cutUnquoted(l.text, ':')
+		if !found {
+			return nil, nil, fmt.Errorf("yamlx: line %d: expected 'key: value'", l.num)
+		}
+		key = unquote(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+		lines = lines[1:]
+		if rest != "" {
+			m[key] = parseScalar(rest)
+			continue
+		}
+		// Value is a nested block (or empty).
+		if len(lines) == 0 || lines[0].indent <= indent {
+			m[key] = nil
+			continue
+		}
+		val, remaining, err := parseBlock(lines, lines[0].indent)
+		if err != nil {
+			return nil, nil, err
+		}
+		m[key] = val
+		lines = remaining
+	}
+	return m, lines, nil
+}
+
+func parseSequence(lines []line, indent int) (interface{}, []line, error) {
+	var seq []interface{}
+	for len(lines) > 0 {
+		l := lines[0]
+		if l.indent != indent || (!strings.HasPrefix(l.text, "- ") && l.text != "-") {
+			break
+		}
+		item := strings.TrimSpace(strings.TrimPrefix(l.text, "-"))
+		if item == "" {
+			// Nested block item.
+			lines = lines[1:]
+			if len(lines) == 0 || lines[0].indent <= indent {
+				seq = append(seq, nil)
+				continue
+			}
+			val, remaining, err := parseBlock(lines, lines[0].indent)
+			if err != nil {
+				return nil, nil, err
+			}
+			seq = append(seq, val)
+			lines = remaining
+			continue
+		}
+		if _, _, found := cutUnquoted(item, ':'); found && !looksQuoted(item) {
+			// Inline mapping start: "- key: value" with optional
+			// continuation lines indented past the dash.
+			itemIndent := indent + 2
+			synth := []line{{num: l.num, indent: itemIndent, text: item}}
+			remaining := lines[1:]
+			for len(remaining) > 0 && remaining[0].indent >= itemIndent && !strings.HasPrefix(remaining[0].text, "- ") {
+				cont := remaining[0]
+				cont.indent = itemIndent
+				synth = append(synth, cont)
+				remaining = remaining[1:]
+			}
+			val, _, err := parseMapping(synth, itemIndent)
+			if err != nil {
+				return nil, nil, err
+			}
+			seq = append(seq, val)
+			lines = remaining
+			continue
+		}
+		seq = append(seq, parseScalar(item))
+		lines = lines[1:]
+	}
+	return seq, lines, nil
+}
+
+// cutUnquoted splits s at the first occurrence of sep outside quotes,
+// requiring the separator to terminate a key (followed by space or EOL).
+func cutUnquoted(s string, sep byte) (before, after string, found bool) {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case sep:
+			if !inSingle && !inDouble && (i == len(s)-1 || s[i+1] == ' ') {
+				return s[:i], s[i+1:], true
+			}
+		}
+	}
+	return s, "", false
+}
+
+func looksQuoted(s string) bool {
+	return len(s) > 0 && (s[0] == '\'' || s[0] == '"')
+}
+
+func parseScalar(s string) interface{} {
+	if looksQuoted(s) {
+		return unquote(s)
+	}
+	switch s {
+	case "null", "~":
+		return nil
+	case "true", "yes", "on":
+		return true
+	case "false", "no", "off":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return []interface{}{}
+		}
+		var out []interface{}
+		for _, part := range strings.Split(inner, ",") {
+			out = append(out, parseScalar(strings.TrimSpace(part)))
+		}
+		return out
+	}
+	return s
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		if s[0] == '"' && s[len(s)-1] == '"' {
+			if uq, err := strconv.Unquote(s); err == nil {
+				return uq
+			}
+		}
+		if s[0] == '\'' && s[len(s)-1] == '\'' {
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+	}
+	return s
+}
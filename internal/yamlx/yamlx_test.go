@@ -0,0 +1,89 @@
+package yamlx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNestedMapping(t *testing.T) {
+	doc := `
+server_url: wss://mgmt.example.com/agent  # endpoint
+client_id: "node-01"
+heartbeat_interval: 30
+insecure_skip_verify: true
+bmc:
+  ip: 10.0.0.5
+  username: admin
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]interface{}{
+		"server_url":           "wss://mgmt.example.com/agent",
+		"client_id":            "node-01",
+		"heartbeat_interval":   int64(30),
+		"insecure_skip_verify": true,
+		"bmc": map[string]interface{}{
+			"ip":       "10.0.0.5",
+			"username": "admin",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse mismatch:\n got  %#v\n want %#v", got, want)
+	}
+}
+
+func TestParseSequences(t *testing.T) {
+	doc := `
+plain:
+  - one
+  - two
+inline: [1, 2, 3]
+items:
+  - name: a
+    value: 1
+  - name: b
+    value: 2
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m := got.(map[string]interface{})
+	if want := []interface{}{"one", "two"}; !reflect.DeepEqual(m["plain"], want) {
+		t.Errorf("plain = %#v, want %#v", m["plain"], want)
+	}
+	if want := []interface{}{int64(1), int64(2), int64(3)}; !reflect.DeepEqual(m["inline"], want) {
+		t.Errorf("inline = %#v, want %#v", m["inline"], want)
+	}
+	items := m["items"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("items = %#v, want 2 entries", items)
+	}
+	first := items[0].(map[string]interface{})
+	if first["name"] != "a" || first["value"] != int64(1) {
+		t.Errorf("items[0] = %#v", first)
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	type dst struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+		On    bool   `json:"on"`
+	}
+	var d dst
+	if err := Unmarshal([]byte("name: test\ncount: 7\non: yes\n"), &d); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if d.Name != "test" || d.Count != 7 || !d.On {
+		t.Errorf("Unmarshal = %+v", d)
+	}
+}
+
+func TestParseRejectsTabs(t *testing.T) {
+	if _, err := Parse([]byte("a:\n\tb: 1\n")); err == nil {
+		t.Error("expected error for tab indentation")
+	}
+}
@@ -0,0 +1,88 @@
+package bmc
+
+import "fmt"
+
+// AccountRoles enumerates the standard Redfish roles the agent accepts
+// for created or updated BMC accounts.
+var AccountRoles = map[string]bool{
+	"Administrator": true,
+	"Operator":      true,
+	"ReadOnly":      true,
+}
+
+// accountsPath resolves the AccountService's Accounts collection.
+func (c *Client) accountsPath() (string, error) {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return "", fmt.Errorf("service root: %w", err)
+	}
+	asRef := odataID(root["AccountService"])
+	if asRef == "" {
+		return "", fmt.Errorf("bmc has no AccountService")
+	}
+	as, err := c.get(asRef)
+	if err != nil {
+		return "", err
+	}
+	accounts := odataID(as["Accounts"])
+	if accounts == "" {
+		return "", fmt.Errorf("AccountService has no Accounts collection")
+	}
+	return accounts, nil
+}
+
+// findAccount resolves the account resource with the given user name.
+func (c *Client) findAccount(username string) (string, error) {
+	accounts, err := c.accountsPath()
+	if err != nil {
+		return "", err
+	}
+	collection, err := c.get(accounts)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range memberRefs(collection) {
+		acc, err := c.get(ref)
+		if err != nil {
+			continue
+		}
+		if str(acc["UserName"]) == username {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("no account %q on this BMC", username)
+}
+
+// CreateAccount creates a BMC user account. Some BMCs pre-allocate
+// account slots instead of accepting POSTs; those reject creation, and
+// the existing slot must be updated instead.
+func (c *Client) CreateAccount(username, password, role string) error {
+	accounts, err := c.accountsPath()
+	if err != nil {
+		return err
+	}
+	return c.post(accounts, map[string]interface{}{
+		"UserName": username,
+		"Password": password,
+		"RoleId":   role,
+		"Enabled":  true,
+	})
+}
+
+// SetAccountPassword changes an existing account's password.
+func (c *Client) SetAccountPassword(username, password string) error {
+	ref, err := c.findAccount(username)
+	if err != nil {
+		return err
+	}
+	return c.patch(ref, map[string]interface{}{"Password": password})
+}
+
+// SetAccountEnabled enables or disables an existing account.
+func (c *Client) SetAccountEnabled(username string, enabled bool) error {
+	ref, err := c.findAccount(username)
+	if err != nil {
+		return err
+	}
+	return c.patch(ref, map[string]interface{}{"Enabled": enabled})
+}
@@ -0,0 +1,150 @@
+package bmc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// biosRefs resolves the system's Bios resource and its staging
+// (Settings) resource.
+func (c *Client) biosRefs() (biosURL, settingsURL string, err error) {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return "", "", fmt.Errorf("service root: %w", err)
+	}
+	systemURL, err := c.discoverSystemURL(root)
+	if err != nil {
+		return "", "", err
+	}
+	return c.biosRefsFrom(systemURL)
+}
+
+func (c *Client) biosRefsFrom(systemURL string) (biosURL, settingsURL string, err error) {
+	sys, err := c.get(systemURL)
+	if err != nil {
+		return "", "", err
+	}
+	biosURL = odataID(sys["Bios"])
+	if biosURL == "" {
+		return "", "", fmt.Errorf("system exposes no Bios resource: %w", errNotFound)
+	}
+	bios, err := c.get(biosURL)
+	if err != nil {
+		return "", "", err
+	}
+	if settings, ok := bios["@Redfish.Settings"].(map[string]interface{}); ok {
+		settingsURL = odataID(settings["SettingsObject"])
+	}
+	return biosURL, settingsURL, nil
+}
+
+// collectBIOS adds applied and pending BIOS attributes to the
+// inventory snapshot. Not every BMC exposes a Bios resource.
+func (c *Client) collectBIOS(systemURL string, inv *inventory.BMCInventory) error {
+	biosURL, settingsURL, err := c.biosRefsFrom(systemURL)
+	if err != nil {
+		return err
+	}
+	bios, err := c.get(biosURL)
+	if err != nil {
+		return err
+	}
+	inv.BIOSAttributes, _ = bios["Attributes"].(map[string]interface{})
+	if settingsURL == "" {
+		return nil
+	}
+	settings, err := c.get(settingsURL)
+	if err != nil {
+		return err
+	}
+	inv.PendingBIOS, _ = settings["Attributes"].(map[string]interface{})
+	return nil
+}
+
+// BIOSAttributes returns the currently applied BIOS settings.
+func (c *Client) BIOSAttributes() (map[string]interface{}, error) {
+	biosURL, _, err := c.biosRefs()
+	if err != nil {
+		return nil, err
+	}
+	bios, err := c.get(biosURL)
+	if err != nil {
+		return nil, err
+	}
+	attrs, _ := bios["Attributes"].(map[string]interface{})
+	return attrs, nil
+}
+
+// PendingBIOSSettings returns attributes staged in the Settings
+// resource but not applied yet (most BMCs apply them on the next
+// reboot).
+func (c *Client) PendingBIOSSettings() (map[string]interface{}, error) {
+	_, settingsURL, err := c.biosRefs()
+	if err != nil {
+		return nil, err
+	}
+	if settingsURL == "" {
+		return nil, nil
+	}
+	settings, err := c.get(settingsURL)
+	if err != nil {
+		return nil, err
+	}
+	attrs, _ := settings["Attributes"].(map[string]interface{})
+	return attrs, nil
+}
+
+// StageBIOSSettings patches attribute changes into the Bios Settings
+// resource. The changes stay pending until the host reboots.
+func (c *Client) StageBIOSSettings(attrs map[string]interface{}) error {
+	if len(attrs) == 0 {
+		return fmt.Errorf("no attributes to stage")
+	}
+	_, settingsURL, err := c.biosRefs()
+	if err != nil {
+		return err
+	}
+	if settingsURL == "" {
+		return fmt.Errorf("bios resource is not writable on this BMC")
+	}
+	return c.patch(settingsURL, map[string]interface{}{"Attributes": attrs})
+}
+
+// patch sends a Redfish PATCH with the same session handling as get.
+func (c *Client) patch(path string, payload interface{}) error {
+	resp, err := c.patchOnce(path, payload)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.dropSession()
+		if resp, err = c.patchOnce(path, payload); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PATCH %s: %s", path, resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) patchOnce(path string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	c.ensureSession()
+	req, err := http.NewRequest(http.MethodPatch, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req)
+}
@@ -0,0 +1,533 @@
+// Package bmc collects out-of-band hardware inventory from a baseboard
+// management controller over the Redfish protocol.
+package bmc
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/simonschuang/demo/internal/certpin"
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// Client talks Redfish to one BMC.
+type Client struct {
+	cfg        config.BMCConfig
+	baseURL    string
+	httpClient *http.Client
+	session    session
+	cache      etagCache
+	breaker    breaker
+	expand     expandSupport
+}
+
+// NewClient builds a Redfish client for the configured BMC. BMCs almost
+// universally present self-signed certificates, so without an explicit
+// tls block verification is disabled on this internal management
+// network connection. proxyFromEnv applies HTTPS_PROXY / NO_PROXY to
+// Redfish requests; BMCs usually sit on the local management network,
+// so NO_PROXY entries typically exclude them.
+func NewClient(cfg config.BMCConfig, proxyFromEnv bool) *Client {
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if cfg.TLS != nil {
+		// The block was validated at load; fall back to the default on
+		// the off chance it fails here.
+		if built, err := cfg.TLS.Build(); err == nil {
+			tlsConfig = built
+		}
+	}
+	certpin.Apply(tlsConfig, cfg.PinSHA256)
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	if proxyFromEnv {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{
+		cfg:     cfg,
+		baseURL: "https://" + cfg.IP,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// get fetches a Redfish resource and decodes it into a generic map.
+// The first attempt uses the cached session token (logging in if
+// needed); a 401 drops the stale session and retries once with fresh
+// credentials. Resources seen before are requested conditionally with
+// If-None-Match, and a 304 is answered from the ETag cache.
+func (c *Client) get(path string) (map[string]interface{}, error) {
+	resp, err := c.getOnce(path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.dropSession()
+		if resp, err = c.getOnce(path); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		if out, ok := c.cache.decode(path); ok {
+			return out, nil
+		}
+		// The cache entry vanished underneath us; refetch without the
+		// conditional header.
+		c.cache.drop(path)
+		if resp, err = c.getOnce(path); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Optional resources 404 on hardware that simply lacks them;
+		// callers distinguish this from real failures via errNotFound.
+		return nil, fmt.Errorf("GET %s: %w", path, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("GET %s: decode: %w", path, err)
+	}
+	if c.cfg.RecordDir != "" {
+		c.recordResponse(path, body)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.cache.store(path, etag, body)
+	}
+	return out, nil
+}
+
+func (c *Client) getOnce(path string) (*http.Response, error) {
+	c.ensureSession()
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Accept", "application/json")
+	if etag := c.cache.etag(path); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return c.do(req)
+}
+
+// errNotFound marks a 404 on an optional resource: hardware absent
+// rather than collection failed.
+var errNotFound = errors.New("resource not present")
+
+// sectionErrors accumulates per-section collection failures so partial
+// results can say which sections are missing and why.
+type sectionErrors map[string]string
+
+// record notes a section failure, ignoring absent optional resources.
+func (e sectionErrors) record(section string, err error) {
+	if err == nil || errors.Is(err, errNotFound) {
+		return
+	}
+	e[section] = err.Error()
+}
+
+// Collect gathers an inventory snapshot per ComputerSystem. Single-node
+// servers yield one entry; multi-node sleds and blades yield one entry
+// per node, distinguished by NodeID. Sub-collections that fail or run
+// past the collect budget are listed in each snapshot's
+// CollectionErrors, so a missing section is distinguishable from
+// hardware that is simply absent. Targets configured with protocol
+// ipmi are collected through ipmitool instead of Redfish.
+func (c *Client) Collect() ([]*inventory.BMCInventory, error) {
+	if c.cfg.Protocol == "ipmi" {
+		return c.collectIPMI()
+	}
+	var deadline time.Time
+	if s := c.cfg.CollectBudgetSeconds; s > 0 {
+		deadline = time.Now().Add(time.Duration(s) * time.Second)
+	}
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return nil, fmt.Errorf("service root: %w", err)
+	}
+	systemURLs, err := c.discoverSystemURLs(root)
+	if err != nil {
+		return nil, err
+	}
+	adapter := detectVendor(root)
+	shared := make(sectionErrors)
+	manager, err := c.collectManager(root)
+	shared.record("manager", err)
+	firmware, err := c.collectFirmwareInventory(root)
+	shared.record("firmware_inventory", err)
+	var invs []*inventory.BMCInventory
+	var lastErr error
+	for _, systemURL := range systemURLs {
+		inv := &inventory.BMCInventory{
+			SchemaVersion:     inventory.SchemaVersion,
+			BMCVersion:        str(root["RedfishVersion"]),
+			Manager:           manager,
+			FirmwareInventory: firmware,
+		}
+		errs := make(sectionErrors)
+		for k, v := range shared {
+			errs[k] = v
+		}
+		// run executes one sub-collection unless the budget is already
+		// spent, recording failures under section.
+		run := func(section string, fn func() error) {
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				errs[section] = "collect budget exhausted"
+				return
+			}
+			errs.record(section, fn())
+		}
+		chassisRefs, err := c.collectSystemInfo(systemURL, inv)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		run("processors", func() error { return c.collectProcessors(systemURL, inv) })
+		run("memory", func() error { return c.collectMemory(systemURL, inv) })
+		run("storage", func() error { return c.collectStorage(systemURL, inv) })
+		run("bios", func() error { return c.collectBIOS(systemURL, inv) })
+		if len(chassisRefs) == 0 {
+			// Firmware without Links.Chassis reads every chassis; on
+			// linked systems each node keeps to its own enclosures.
+			chassisRefs = c.chassisMembers(root)
+		}
+		run("chassis", func() error { return c.collectChassis(chassisRefs, inv) })
+		run("pcie_devices", func() error { return c.collectPCIe(chassisRefs, inv) })
+		if adapter != nil {
+			adapter.enrich(c, systemURL, chassisRefs, inv)
+		}
+		if len(errs) > 0 {
+			inv.CollectionErrors = errs
+		}
+		invs = append(invs, inv)
+	}
+	if len(invs) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("no collectable systems")
+	}
+	return invs, nil
+}
+
+// discoverSystemURLs resolves every ComputerSystem resource the BMC
+// exposes.
+func (c *Client) discoverSystemURLs(root map[string]interface{}) ([]string, error) {
+	systemsRef := odataID(root["Systems"])
+	if systemsRef == "" {
+		return nil, fmt.Errorf("service root has no Systems collection")
+	}
+	systems, err := c.get(systemsRef)
+	if err != nil {
+		return nil, err
+	}
+	members := memberRefs(systems)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("Systems collection is empty")
+	}
+	return members, nil
+}
+
+// discoverSystemURL resolves the ComputerSystem that commands target.
+// Commands act on the first Systems member.
+func (c *Client) discoverSystemURL(root map[string]interface{}) (string, error) {
+	members, err := c.discoverSystemURLs(root)
+	if err != nil {
+		return "", err
+	}
+	return members[0], nil
+}
+
+// collectSystemInfo fills the system identity fields and returns the
+// chassis resources the system links to, if any.
+func (c *Client) collectSystemInfo(systemURL string, inv *inventory.BMCInventory) ([]string, error) {
+	raw, err := c.get(systemURL)
+	if err != nil {
+		return nil, fmt.Errorf("system: %w", err)
+	}
+	var sys computerSystem
+	if err := decodeResource(raw, &sys); err != nil {
+		return nil, fmt.Errorf("system: %w", err)
+	}
+	inv.NodeID = sys.ID
+	inv.Manufacturer = sys.Manufacturer
+	inv.Model = sys.Model
+	inv.SerialNumber = sys.SerialNumber
+	inv.BIOSVersion = sys.BiosVersion
+	inv.PowerState = sys.PowerState
+	inv.Health = sys.Status.Health
+	if gib := float64(sys.MemorySummary.TotalSystemMemoryGiB); gib > 0 {
+		inv.MemoryTotal = uint64(gib * 1024 * 1024 * 1024)
+	}
+	inv.Boot = bootInfo(sys)
+	var chassisRefs []string
+	for _, r := range sys.Links.Chassis {
+		if r.ODataID != "" {
+			chassisRefs = append(chassisRefs, r.ODataID)
+		}
+	}
+	return chassisRefs, nil
+}
+
+func (c *Client) collectProcessors(systemURL string, inv *inventory.BMCInventory) error {
+	procs, err := c.getMembers(systemURL + "/Processors")
+	if err != nil {
+		return err
+	}
+	for _, raw := range procs {
+		var p processor
+		if err := decodeResource(raw, &p); err != nil {
+			continue
+		}
+		switch p.ProcessorType {
+		case "GPU", "Accelerator", "FPGA":
+			// Accelerators belong with the PCIe devices, not the CPUs.
+			inv.PCIeDevices = append(inv.PCIeDevices, inventory.BMCPCIeDevice{
+				Name:         str(raw["Id"]),
+				Manufacturer: p.Manufacturer,
+				Model:        p.Model,
+				DeviceClass:  "Accelerator",
+				Health:       p.Status.Health,
+			})
+			continue
+		case "", "CPU":
+		default:
+			continue
+		}
+		inv.Processors = append(inv.Processors, inventory.CPUInfo{
+			Model:   p.Model,
+			Cores:   int(p.TotalCores),
+			Threads: int(p.TotalThreads),
+			MHz:     float64(p.MaxSpeedMHz),
+		})
+	}
+	return nil
+}
+
+func (c *Client) collectMemory(systemURL string, inv *inventory.BMCInventory) error {
+	if inv.MemoryTotal > 0 {
+		return nil
+	}
+	dimms, err := c.getMembers(systemURL + "/Memory")
+	if err != nil {
+		return err
+	}
+	var total uint64
+	for _, raw := range dimms {
+		var dimm memoryModule
+		if err := decodeResource(raw, &dimm); err != nil {
+			continue
+		}
+		total += uint64(dimm.CapacityMiB) * 1024 * 1024
+	}
+	inv.MemoryTotal = total
+	return nil
+}
+
+func (c *Client) collectStorage(systemURL string, inv *inventory.BMCInventory) error {
+	ctrls, err := c.getMembers(systemURL + "/Storage")
+	if err != nil {
+		return err
+	}
+	for _, ctrl := range ctrls {
+		drives, _ := ctrl["Drives"].([]interface{})
+		for _, d := range drives {
+			raw, ok := d.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if len(raw) <= 1 {
+				// A bare reference; expanded controllers inline the
+				// drive resources instead.
+				driveRef := odataID(d)
+				if driveRef == "" {
+					continue
+				}
+				if raw, err = c.get(driveRef); err != nil {
+					continue
+				}
+			}
+			var dr drive
+			if err := decodeResource(raw, &dr); err != nil {
+				continue
+			}
+			si := inventory.StorageInfo{
+				Name:                 dr.Name,
+				Model:                dr.Model,
+				SerialNumber:         dr.SerialNumber,
+				MediaType:            dr.MediaType,
+				CapacityBytes:        uint64(dr.CapacityBytes),
+				Health:               dr.Status.Health,
+				FirmwareVersion:      dr.Revision,
+				MediaLifeLeftPercent: -1,
+				SpeedGbs:             float64(dr.NegotiatedSpeedGbs),
+				EncryptionAbility:    dr.EncryptionAbility,
+				EncryptionStatus:     dr.EncryptionStatus,
+				FailurePredicted:     dr.FailurePredicted,
+			}
+			if si.FirmwareVersion == "" {
+				si.FirmwareVersion = dr.FirmwareVersion
+			}
+			if si.SpeedGbs == 0 {
+				si.SpeedGbs = float64(dr.CapableSpeedGbs)
+			}
+			if dr.PredictedMediaLife != nil {
+				si.MediaLifeLeftPercent = int(*dr.PredictedMediaLife)
+			}
+			inv.Storage = append(inv.Storage, si)
+		}
+	}
+	return nil
+}
+
+// chassisMembers resolves every chassis resource the BMC exposes.
+func (c *Client) chassisMembers(root map[string]interface{}) []string {
+	chassisRef := odataID(root["Chassis"])
+	if chassisRef == "" {
+		return nil
+	}
+	collection, err := c.get(chassisRef)
+	if err != nil {
+		return nil
+	}
+	return memberRefs(collection)
+}
+
+// collectChassis reads thermal and power data from the given chassis
+// resources, tagging readings with the chassis id so blades and
+// multi-enclosure systems keep their sensors apart. Returns the first
+// failure while still visiting every chassis.
+func (c *Client) collectChassis(members []string, inv *inventory.BMCInventory) error {
+	var firstErr error
+	for _, ref := range members {
+		id := path.Base(ref)
+		if ch, err := c.get(ref); err == nil {
+			if s := str(ch["Id"]); s != "" {
+				id = s
+			}
+		}
+		if len(members) == 1 {
+			// A single chassis needs no disambiguation.
+			id = ""
+		}
+		if err := c.collectThermal(ref, id, inv); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := c.collectPower(ref, id, inv); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *Client) collectThermal(chassisURL, chassisID string, inv *inventory.BMCInventory) error {
+	raw, err := c.get(chassisURL + "/Thermal")
+	if err != nil {
+		return err
+	}
+	var thermal thermalResource
+	if err := decodeResource(raw, &thermal); err != nil {
+		return err
+	}
+	for _, t := range thermal.Temperatures {
+		inv.Temperatures = append(inv.Temperatures, inventory.TempInfo{
+			Name:            t.Name,
+			Celsius:         float64(t.ReadingCelsius),
+			CriticalCelsius: float64(t.UpperThresholdCritical),
+			Health:          t.Status.Health,
+			Chassis:         chassisID,
+		})
+	}
+	for _, f := range thermal.Fans {
+		name := f.Name
+		if name == "" {
+			name = f.FanName
+		}
+		inv.Fans = append(inv.Fans, inventory.FanInfo{
+			Name:        name,
+			RPM:         int(f.Reading),
+			CriticalRPM: int(f.LowerThresholdCritical),
+			Health:      f.Status.Health,
+			Chassis:     chassisID,
+		})
+	}
+	return nil
+}
+
+func (c *Client) collectPower(chassisURL, chassisID string, inv *inventory.BMCInventory) error {
+	raw, err := c.get(chassisURL + "/Power")
+	if err != nil {
+		return err
+	}
+	var power powerResource
+	if err := decodeResource(raw, &power); err != nil {
+		return err
+	}
+	for _, pc := range power.PowerControl {
+		inv.PowerConsumedWatts += float64(pc.PowerConsumedWatts)
+		if inv.PowerCapWatts == 0 && pc.PowerLimit.LimitInWatts != nil {
+			inv.PowerCapWatts = float64(*pc.PowerLimit.LimitInWatts)
+		}
+	}
+	for _, p := range power.PowerSupplies {
+		inv.PowerSupplies = append(inv.PowerSupplies, inventory.PSUInfo{
+			Name:         p.Name,
+			Model:        p.Model,
+			SerialNumber: p.SerialNumber,
+			CapacityWatt: float64(p.PowerCapacityWatts),
+			Health:       p.Status.Health,
+			Chassis:      chassisID,
+		})
+	}
+	return nil
+}
+
+// str returns v as a string when it is one, and "" otherwise.
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// odataID extracts the @odata.id link from a Redfish reference object.
+func odataID(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return str(m["@odata.id"])
+}
+
+// memberRefs extracts the member links from a Redfish collection.
+func memberRefs(collection map[string]interface{}) []string {
+	members, _ := collection["Members"].([]interface{})
+	var out []string
+	for _, m := range members {
+		if ref := odataID(m); ref != "" {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package bmc
+
+import (
+	"fmt"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// BootTargets enumerates the Redfish boot source override targets the
+// agent accepts from set_boot commands.
+var BootTargets = map[string]bool{
+	"None":      true,
+	"Pxe":       true,
+	"Hdd":       true,
+	"Cd":        true,
+	"Usb":       true,
+	"BiosSetup": true,
+	"UefiHttp":  true,
+}
+
+// BootOverrideModes enumerates the accepted BootSourceOverrideEnabled
+// values.
+var BootOverrideModes = map[string]bool{
+	"Once":       true,
+	"Continuous": true,
+	"Disabled":   true,
+}
+
+// bootInfo converts the Boot section of a ComputerSystem resource.
+func bootInfo(sys computerSystem) *inventory.BootInfo {
+	b := sys.Boot
+	if b.BootSourceOverrideTarget == "" && b.BootSourceOverrideEnabled == "" && len(b.BootOrder) == 0 {
+		return nil
+	}
+	return &inventory.BootInfo{
+		Order:           b.BootOrder,
+		OverrideTarget:  b.BootSourceOverrideTarget,
+		OverrideEnabled: b.BootSourceOverrideEnabled,
+		OverrideMode:    b.BootSourceOverrideMode,
+	}
+}
+
+// SetBootOverride stages a boot source override on the first system:
+// target is the boot source (Pxe, Hdd, ...) and enabled is Once,
+// Continuous, or Disabled. The override takes effect on the next
+// reset.
+func (c *Client) SetBootOverride(target, enabled string) error {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return fmt.Errorf("service root: %w", err)
+	}
+	systemURL, err := c.discoverSystemURL(root)
+	if err != nil {
+		return err
+	}
+	return c.patch(systemURL, map[string]interface{}{
+		"Boot": map[string]interface{}{
+			"BootSourceOverrideTarget":  target,
+			"BootSourceOverrideEnabled": enabled,
+		},
+	})
+}
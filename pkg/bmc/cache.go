@@ -0,0 +1,58 @@
+package bmc
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// etagCache remembers the last response body per resource URL together
+// with its ETag so unchanged resources can be refetched conditionally.
+// FRU-type data rarely changes between cycles, and the resulting 304s
+// cut the BMC's per-cycle work substantially on large collections.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+// etag returns the stored ETag for path, or "" when nothing is cached.
+func (e *etagCache) etag(path string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.entries[path].etag
+}
+
+// decode unmarshals the cached body for path. Each call decodes fresh
+// so callers can modify the returned map without corrupting the cache.
+func (e *etagCache) decode(path string) (map[string]interface{}, bool) {
+	e.mu.Lock()
+	body := e.entries[path].body
+	e.mu.Unlock()
+	if body == nil {
+		return nil, false
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (e *etagCache) store(path, etag string, body []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.entries == nil {
+		e.entries = make(map[string]etagEntry)
+	}
+	e.entries[path] = etagEntry{etag: etag, body: body}
+}
+
+func (e *etagCache) drop(path string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.entries, path)
+}
@@ -0,0 +1,245 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CertificateInfo summarizes the BMC's installed HTTPS certificate.
+type CertificateInfo struct {
+	Subject        string `json:"subject"`
+	Issuer         string `json:"issuer"`
+	ValidNotBefore string `json:"valid_not_before,omitempty"`
+	ValidNotAfter  string `json:"valid_not_after,omitempty"`
+	SerialNumber   string `json:"serial_number,omitempty"`
+	// SelfSigned is true when subject and issuer match — the factory
+	// default on effectively every BMC.
+	SelfSigned bool `json:"self_signed"`
+}
+
+// CSRRequest names the subject for a certificate signing request. Empty
+// fields are omitted from the Redfish payload, though Dell iDRAC in
+// particular rejects requests without a full subject.
+type CSRRequest struct {
+	CommonName         string
+	Organization       string
+	OrganizationalUnit string
+	City               string
+	State              string
+	Country            string
+	KeyBitLength       int
+}
+
+// HTTPSCertificate reads the certificate the BMC web server currently
+// presents, from the manager's HTTPS certificate collection.
+func (c *Client) HTTPSCertificate() (*CertificateInfo, error) {
+	_, members, err := c.httpsCertificates()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("bmc reports no HTTPS certificate")
+	}
+	raw, err := c.get(members[0])
+	if err != nil {
+		return nil, err
+	}
+	var cert certificateResource
+	if err := decodeResource(raw, &cert); err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	subject := subjectString(cert.Subject)
+	issuer := subjectString(cert.Issuer)
+	return &CertificateInfo{
+		Subject:        subject,
+		Issuer:         issuer,
+		ValidNotBefore: cert.ValidNotBefore,
+		ValidNotAfter:  cert.ValidNotAfter,
+		SerialNumber:   cert.SerialNumber,
+		SelfSigned:     subject != "" && subject == issuer,
+	}, nil
+}
+
+// GenerateCSR asks the CertificateService to create a signing request
+// for the HTTPS certificate slot and returns it in PEM form. The
+// private key never leaves the BMC.
+func (c *Client) GenerateCSR(req CSRRequest) (string, error) {
+	if req.CommonName == "" {
+		return "", fmt.Errorf("csr requires a common name")
+	}
+	target, err := c.certificateAction("#CertificateService.GenerateCSR")
+	if err != nil {
+		return "", err
+	}
+	collection, _, err := c.httpsCertificates()
+	if err != nil {
+		return "", err
+	}
+	payload := map[string]interface{}{
+		"CommonName":            req.CommonName,
+		"CertificateCollection": map[string]interface{}{"@odata.id": collection},
+	}
+	for key, val := range map[string]string{
+		"Organization":       req.Organization,
+		"OrganizationalUnit": req.OrganizationalUnit,
+		"City":               req.City,
+		"State":              req.State,
+		"Country":            req.Country,
+	} {
+		if val != "" {
+			payload[key] = val
+		}
+	}
+	if req.KeyBitLength > 0 {
+		payload["KeyBitLength"] = req.KeyBitLength
+	}
+	body, err := c.postForBody(target, payload)
+	if err != nil {
+		return "", fmt.Errorf("GenerateCSR: %w", err)
+	}
+	csr := str(body["CSRString"])
+	if csr == "" {
+		return "", fmt.Errorf("GenerateCSR: bmc returned no CSRString")
+	}
+	return csr, nil
+}
+
+// ReplaceHTTPSCertificate installs a PEM certificate over the current
+// HTTPS certificate. Most BMCs restart their web server afterwards, so
+// the next request may fail until it comes back.
+func (c *Client) ReplaceHTTPSCertificate(certPEM string) error {
+	target, err := c.certificateAction("#CertificateService.ReplaceCertificate")
+	if err != nil {
+		return err
+	}
+	_, members, err := c.httpsCertificates()
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("bmc reports no HTTPS certificate to replace")
+	}
+	return c.post(target, map[string]interface{}{
+		"CertificateString": certPEM,
+		"CertificateType":   "PEM",
+		"CertificateUri":    map[string]interface{}{"@odata.id": members[0]},
+	})
+}
+
+// certificateAction resolves a CertificateService action target.
+func (c *Client) certificateAction(name string) (string, error) {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return "", fmt.Errorf("service root: %w", err)
+	}
+	csRef := odataID(root["CertificateService"])
+	if csRef == "" {
+		return "", fmt.Errorf("bmc has no CertificateService")
+	}
+	cs, err := c.get(csRef)
+	if err != nil {
+		return "", err
+	}
+	actions, _ := cs["Actions"].(map[string]interface{})
+	action, _ := actions[name].(map[string]interface{})
+	target := str(action["target"])
+	if target == "" {
+		return "", fmt.Errorf("CertificateService offers no %s action", name)
+	}
+	return target, nil
+}
+
+// httpsCertificates resolves the manager's HTTPS certificate collection
+// and its current members.
+func (c *Client) httpsCertificates() (collection string, members []string, err error) {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return "", nil, fmt.Errorf("service root: %w", err)
+	}
+	managersRef := odataID(root["Managers"])
+	if managersRef == "" {
+		return "", nil, fmt.Errorf("service root has no Managers collection")
+	}
+	managers, err := c.get(managersRef)
+	if err != nil {
+		return "", nil, err
+	}
+	refs := memberRefs(managers)
+	if len(refs) == 0 {
+		return "", nil, fmt.Errorf("Managers collection is empty")
+	}
+	mgr, err := c.get(refs[0])
+	if err != nil {
+		return "", nil, err
+	}
+	npRef := odataID(mgr["NetworkProtocol"])
+	if npRef == "" {
+		return "", nil, fmt.Errorf("manager has no NetworkProtocol resource")
+	}
+	raw, err := c.get(npRef)
+	if err != nil {
+		return "", nil, err
+	}
+	var np networkProtocol
+	if err := decodeResource(raw, &np); err != nil {
+		return "", nil, err
+	}
+	collection = np.HTTPS.Certificates.ODataID
+	if collection == "" {
+		return "", nil, fmt.Errorf("bmc does not expose HTTPS certificates")
+	}
+	certs, err := c.get(collection)
+	if err != nil {
+		return "", nil, err
+	}
+	return collection, memberRefs(certs), nil
+}
+
+// subjectString renders a certificate subject in the usual
+// CN=..., O=..., C=... form, skipping absent attributes.
+func subjectString(s certSubject) string {
+	out := ""
+	for _, part := range []struct{ key, val string }{
+		{"CN", s.CommonName}, {"O", s.Organization}, {"C", s.Country},
+	} {
+		if part.val == "" {
+			continue
+		}
+		if out != "" {
+			out += ", "
+		}
+		out += part.key + "=" + part.val
+	}
+	return out
+}
+
+// postForBody sends a Redfish action and decodes the JSON response
+// body, for actions that answer with data rather than a task monitor.
+func (c *Client) postForBody(path string, payload interface{}) (map[string]interface{}, error) {
+	resp, err := c.postOnce(path, payload)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.dropSession()
+		if resp, err = c.postOnce(path, payload); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("POST %s: %s", path, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("POST %s: decode: %w", path, err)
+	}
+	return out, nil
+}
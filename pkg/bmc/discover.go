@@ -0,0 +1,183 @@
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Candidate is one Redfish endpoint found by discovery. The probe is
+// anonymous — the service root requires no authentication — so only
+// what the BMC advertises publicly is filled in.
+type Candidate struct {
+	IP             string `json:"ip"`
+	Vendor         string `json:"vendor,omitempty"`
+	Product        string `json:"product,omitempty"`
+	RedfishVersion string `json:"redfish_version,omitempty"`
+	UUID           string `json:"uuid,omitempty"`
+}
+
+// discoverConcurrency bounds parallel probes during a subnet sweep.
+const discoverConcurrency = 32
+
+// DiscoverCIDR sweeps an IPv4 subnet for Redfish endpoints by probing
+// every host address for a service root.
+func DiscoverCIDR(ctx context.Context, cidr string, timeout time.Duration) ([]Candidate, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if ipnet.IP.To4() == nil {
+		return nil, fmt.Errorf("discovery: %s: only IPv4 subnets are swept", cidr)
+	}
+	var (
+		mu    sync.Mutex
+		found []Candidate
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, discoverConcurrency)
+	)
+	for _, addr := range hostAddrs(ipnet) {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(addr string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if cand := probeRedfish(ctx, addr, timeout); cand != nil {
+				mu.Lock()
+				found = append(found, *cand)
+				mu.Unlock()
+			}
+		}(addr)
+	}
+	wg.Wait()
+	sort.Slice(found, func(i, j int) bool { return found[i].IP < found[j].IP })
+	return found, ctx.Err()
+}
+
+// ssdpSearchTarget is the DMTF-registered SSDP search target for
+// Redfish services.
+const ssdpSearchTarget = "urn:dmtf-org:service:redfish-rest:1"
+
+// DiscoverSSDP multicasts an SSDP search for Redfish services and
+// probes every responder. Only BMCs with SSDP enabled answer; the
+// subnet sweep finds the rest.
+func DiscoverSSDP(ctx context.Context, timeout time.Duration) ([]Candidate, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	defer conn.Close()
+	search := strings.Join([]string{
+		"M-SEARCH * HTTP/1.1",
+		"HOST: 239.255.255.250:1900",
+		`MAN: "ssdp:discover"`,
+		"MX: 2",
+		"ST: " + ssdpSearchTarget,
+	}, "\r\n") + "\r\n\r\n"
+	dst := &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+	if _, err := conn.WriteTo([]byte(search), dst); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+	seen := make(map[string]bool)
+	var found []Candidate
+	buf := make([]byte, 2048)
+	for {
+		n, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			// The read deadline ends the collection window.
+			break
+		}
+		if !strings.Contains(string(buf[:n]), ssdpSearchTarget) {
+			continue
+		}
+		host, _, err := net.SplitHostPort(src.String())
+		if err != nil || seen[host] {
+			continue
+		}
+		seen[host] = true
+		if cand := probeRedfish(ctx, host, timeout); cand != nil {
+			found = append(found, *cand)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].IP < found[j].IP })
+	return found, nil
+}
+
+// probeRedfish fetches a host's service root without credentials and
+// returns a candidate when it identifies as Redfish.
+func probeRedfish(ctx context.Context, ip string, timeout time.Duration) *Candidate {
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	defer client.CloseIdleConnections()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+ip+"/redfish/v1", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	var root struct {
+		Vendor         string `json:"Vendor"`
+		Product        string `json:"Product"`
+		RedfishVersion string `json:"RedfishVersion"`
+		UUID           string `json:"UUID"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+		return nil
+	}
+	if root.RedfishVersion == "" {
+		// Answered on 443 but is not a Redfish service root.
+		return nil
+	}
+	return &Candidate{
+		IP:             ip,
+		Vendor:         root.Vendor,
+		Product:        root.Product,
+		RedfishVersion: root.RedfishVersion,
+		UUID:           root.UUID,
+	}
+}
+
+// hostAddrs lists the host addresses of an IPv4 subnet, excluding the
+// network and broadcast addresses.
+func hostAddrs(ipnet *net.IPNet) []string {
+	base := binary.BigEndian.Uint32(ipnet.IP.To4())
+	ones, bits := ipnet.Mask.Size()
+	size := uint32(1) << (bits - ones)
+	var out []string
+	for i := uint32(0); i < size; i++ {
+		if size > 2 && (i == 0 || i == size-1) {
+			continue
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], base+i)
+		out = append(out, net.IP(b[:]).String())
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package bmc
+
+import "sync"
+
+// expandSupport caches whether the BMC handles the $expand query
+// parameter, probed once per client from the service root's
+// ProtocolFeaturesSupported section.
+type expandSupport struct {
+	mu        sync.Mutex
+	probed    bool
+	supported bool
+}
+
+// supportsExpand reports whether collections can be fetched in one
+// expanded request. The service root is ETag-cached, so the probe is
+// cheap even when called repeatedly.
+func (c *Client) supportsExpand() bool {
+	c.expand.mu.Lock()
+	defer c.expand.mu.Unlock()
+	if !c.expand.probed {
+		c.expand.probed = true
+		root, err := c.get("/redfish/v1")
+		if err != nil {
+			// Leave unprobed so a transient failure does not pin the
+			// slow path for the client's lifetime.
+			c.expand.probed = false
+			return false
+		}
+		pfs, _ := root["ProtocolFeaturesSupported"].(map[string]interface{})
+		eq, _ := pfs["ExpandQuery"].(map[string]interface{})
+		for _, k := range []string{"ExpandAll", "NoLinks", "Links"} {
+			if v, ok := eq[k].(bool); ok && v {
+				c.expand.supported = true
+				break
+			}
+		}
+	}
+	return c.expand.supported
+}
+
+// getMembers fetches every member resource of a collection: one
+// expanded request when the BMC supports $expand, per-member GETs
+// otherwise. On large collections the expanded form saves dozens of
+// round trips per cycle.
+func (c *Client) getMembers(path string) ([]map[string]interface{}, error) {
+	if c.supportsExpand() {
+		if col, err := c.get(path + "?$expand=."); err == nil {
+			if members, ok := expandedMembers(col); ok {
+				return members, nil
+			}
+		}
+	}
+	col, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]interface{}
+	for _, ref := range memberRefs(col) {
+		m, err := c.get(ref)
+		if err != nil {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// expandedMembers returns the inlined member resources of an expanded
+// collection. Some BMCs accept $expand but still return bare
+// references; those report false so the caller falls back.
+func expandedMembers(col map[string]interface{}) ([]map[string]interface{}, bool) {
+	members, _ := col["Members"].([]interface{})
+	var out []map[string]interface{}
+	for _, m := range members {
+		mm, ok := m.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		// A bare reference holds only the @odata.id link.
+		if len(mm) <= 1 {
+			return nil, false
+		}
+		out = append(out, mm)
+	}
+	return out, true
+}
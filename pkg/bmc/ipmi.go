@@ -0,0 +1,161 @@
+package bmc
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// IPMI-based collection for targets configured with protocol: ipmi.
+// The same ipmitool fallback used for power control covers inventory:
+// FRU data for identity, the sensor data repository for temperatures
+// and fans, and mc info for the BMC firmware. Far shallower than
+// Redfish, but enough to keep legacy machines in the fleet view.
+
+// collectIPMI assembles one inventory snapshot over IPMI.
+func (c *Client) collectIPMI() ([]*inventory.BMCInventory, error) {
+	inv := &inventory.BMCInventory{SchemaVersion: inventory.SchemaVersion}
+	errs := make(sectionErrors)
+	errs.record("identity", c.ipmiFRU(inv))
+	errs.record("sensors", c.ipmiSensors(inv))
+	errs.record("manager", c.ipmiManager(inv))
+	errs.record("power_state", c.ipmiPowerState(inv))
+	if len(errs) == 4 {
+		// Nothing came back at all; report it like an unreachable
+		// Redfish service root instead of an empty snapshot.
+		return nil, fmt.Errorf("ipmi collection failed: %s", errs["identity"])
+	}
+	if len(errs) > 0 {
+		inv.CollectionErrors = errs
+	}
+	return []*inventory.BMCInventory{inv}, nil
+}
+
+// ipmiRun executes one ipmitool subcommand and returns its output.
+func (c *Client) ipmiRun(args ...string) (string, error) {
+	out, err := c.ipmiCommand(args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("ipmitool %s: %v: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+	return string(out), nil
+}
+
+// ipmiFRU fills the machine identity from FRU device 0.
+func (c *Client) ipmiFRU(inv *inventory.BMCInventory) error {
+	out, err := c.ipmiRun("fru", "print", "0")
+	if err != nil {
+		return err
+	}
+	fields := parseColonFields(out)
+	inv.Manufacturer = firstOf(fields, "Product Manufacturer", "Board Mfg")
+	inv.Model = firstOf(fields, "Product Name", "Board Product")
+	inv.SerialNumber = firstOf(fields, "Product Serial", "Board Serial")
+	return nil
+}
+
+// ipmiSensors fills temperatures and fans from the SDR.
+func (c *Client) ipmiSensors(inv *inventory.BMCInventory) error {
+	out, err := c.ipmiRun("sdr")
+	if err != nil {
+		return err
+	}
+	parseSDR(out, inv)
+	return nil
+}
+
+// parseSDR reads ipmitool sdr output, one `name | reading | status`
+// line per sensor, keeping the temperature and fan readings.
+func parseSDR(out string, inv *inventory.BMCInventory) {
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		reading := strings.TrimSpace(parts[1])
+		health := sensorHealth(strings.TrimSpace(parts[2]))
+		switch {
+		case strings.HasSuffix(reading, "degrees C"):
+			v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(reading, "degrees C")), 64)
+			if err == nil {
+				inv.Temperatures = append(inv.Temperatures,
+					inventory.TempInfo{Name: name, Celsius: v, Health: health})
+			}
+		case strings.HasSuffix(reading, "RPM"):
+			v, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(reading, "RPM")))
+			if err == nil {
+				inv.Fans = append(inv.Fans,
+					inventory.FanInfo{Name: name, RPM: v, Health: health})
+			}
+		}
+	}
+}
+
+// sensorHealth maps ipmitool's status column onto the Redfish health
+// vocabulary the rest of the pipeline expects.
+func sensorHealth(s string) string {
+	switch strings.ToLower(s) {
+	case "ok":
+		return "OK"
+	case "nc":
+		return "Warning"
+	case "cr", "nr":
+		return "Critical"
+	default:
+		return ""
+	}
+}
+
+// ipmiManager fills the BMC's own firmware and IPMI version.
+func (c *Client) ipmiManager(inv *inventory.BMCInventory) error {
+	out, err := c.ipmiRun("mc", "info")
+	if err != nil {
+		return err
+	}
+	fields := parseColonFields(out)
+	inv.Manager = &inventory.BMCManagerInfo{FirmwareVersion: fields["Firmware Revision"]}
+	inv.BMCVersion = fields["IPMI Version"]
+	return nil
+}
+
+// ipmiPowerState fills the chassis power state.
+func (c *Client) ipmiPowerState(inv *inventory.BMCInventory) error {
+	out, err := c.ipmiRun("chassis", "power", "status")
+	if err != nil {
+		return err
+	}
+	switch {
+	case strings.Contains(out, "is on"):
+		inv.PowerState = "On"
+	case strings.Contains(out, "is off"):
+		inv.PowerState = "Off"
+	}
+	return nil
+}
+
+// parseColonFields reads `key : value` lines into a map, as printed by
+// ipmitool fru and mc info.
+func parseColonFields(out string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// firstOf returns the first non-empty value among the named fields.
+func firstOf(fields map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := fields[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package bmc
+
+import (
+	"testing"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+func TestParseSDR(t *testing.T) {
+	out := `CPU1 Temp        | 45 degrees C      | ok
+CPU2 Temp        | 48.5 degrees C    | nc
+Inlet Temp       | no reading        | ns
+FAN1             | 5280 RPM          | ok
+FAN2             | 0 RPM             | cr
+12V              | 12.10 Volts       | ok
+malformed line without pipes
+`
+	var inv inventory.BMCInventory
+	parseSDR(out, &inv)
+	if len(inv.Temperatures) != 2 {
+		t.Fatalf("got %d temperatures, want 2", len(inv.Temperatures))
+	}
+	if inv.Temperatures[0].Name != "CPU1 Temp" || inv.Temperatures[0].Celsius != 45 ||
+		inv.Temperatures[0].Health != "OK" {
+		t.Errorf("unexpected first temperature: %+v", inv.Temperatures[0])
+	}
+	if inv.Temperatures[1].Celsius != 48.5 || inv.Temperatures[1].Health != "Warning" {
+		t.Errorf("unexpected second temperature: %+v", inv.Temperatures[1])
+	}
+	if len(inv.Fans) != 2 {
+		t.Fatalf("got %d fans, want 2", len(inv.Fans))
+	}
+	if inv.Fans[0].Name != "FAN1" || inv.Fans[0].RPM != 5280 {
+		t.Errorf("unexpected first fan: %+v", inv.Fans[0])
+	}
+	if inv.Fans[1].Health != "Critical" {
+		t.Errorf("fan health = %q, want Critical", inv.Fans[1].Health)
+	}
+}
+
+func TestParseColonFields(t *testing.T) {
+	out := ` Product Manufacturer  : Contoso
+ Product Name          : R340
+ Product Serial        : SN123456
+ Board Mfg             :
+no colon here
+`
+	fields := parseColonFields(out)
+	if fields["Product Manufacturer"] != "Contoso" {
+		t.Errorf("manufacturer = %q", fields["Product Manufacturer"])
+	}
+	if fields["Product Serial"] != "SN123456" {
+		t.Errorf("serial = %q", fields["Product Serial"])
+	}
+	if got := firstOf(fields, "Board Mfg", "Product Manufacturer"); got != "Contoso" {
+		t.Errorf("firstOf skipped empty field incorrectly, got %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package bmc
+
+import "github.com/simonschuang/demo/pkg/inventory"
+
+// collectManager reads the first Managers member: the BMC's own
+// firmware version, network configuration, NTP settings, and license
+// state. A BMC without a Managers collection just leaves the section
+// empty.
+func (c *Client) collectManager(root map[string]interface{}) (*inventory.BMCManagerInfo, error) {
+	managersRef := odataID(root["Managers"])
+	if managersRef == "" {
+		return nil, nil
+	}
+	collection, err := c.get(managersRef)
+	if err != nil {
+		return nil, err
+	}
+	members := memberRefs(collection)
+	if len(members) == 0 {
+		return nil, nil
+	}
+	mgrURL := members[0]
+	raw, err := c.get(mgrURL)
+	if err != nil {
+		return nil, err
+	}
+	var mgr managerResource
+	if err := decodeResource(raw, &mgr); err != nil {
+		return nil, err
+	}
+	info := &inventory.BMCManagerInfo{
+		FirmwareVersion: mgr.FirmwareVersion,
+		Model:           mgr.Model,
+		Health:          mgr.Status.Health,
+		License:         managerLicense(raw),
+	}
+	c.collectManagerNICs(mgr, mgrURL, info)
+	c.collectManagerNTP(mgr, info)
+	return info, nil
+}
+
+func (c *Client) collectManagerNICs(mgr managerResource, mgrURL string, info *inventory.BMCManagerInfo) {
+	ethRef := mgr.EthernetInterfaces.ODataID
+	if ethRef == "" {
+		ethRef = mgrURL + "/EthernetInterfaces"
+	}
+	members, err := c.getMembers(ethRef)
+	if err != nil {
+		return
+	}
+	for _, raw := range members {
+		var eth ethernetInterface
+		if err := decodeResource(raw, &eth); err != nil {
+			continue
+		}
+		nic := inventory.BMCNICInfo{
+			Name: eth.ID,
+			MAC:  eth.MACAddress,
+		}
+		for _, a := range eth.IPv4Addresses {
+			if a.Address != "" {
+				nic.Addresses = append(nic.Addresses, a.Address)
+			}
+			if a.AddressOrigin == "DHCP" {
+				nic.DHCP = true
+			}
+		}
+		if eth.VLAN.VLANEnable {
+			nic.VLANID = int(eth.VLAN.VLANID)
+		}
+		info.NICs = append(info.NICs, nic)
+	}
+}
+
+func (c *Client) collectManagerNTP(mgr managerResource, info *inventory.BMCManagerInfo) {
+	if mgr.NetworkProtocol.ODataID == "" {
+		return
+	}
+	raw, err := c.get(mgr.NetworkProtocol.ODataID)
+	if err != nil {
+		return
+	}
+	var np networkProtocol
+	if err := decodeResource(raw, &np); err != nil {
+		return
+	}
+	info.NTPEnabled = np.NTP.ProtocolEnabled
+	for _, s := range np.NTP.NTPServers {
+		if s != "" {
+			info.NTPServers = append(info.NTPServers, s)
+		}
+	}
+}
+
+// managerLicense digs the license tier out of the vendor OEM section;
+// there is no standard field old firmware agrees on.
+func managerLicense(mgr map[string]interface{}) string {
+	oem, _ := mgr["Oem"].(map[string]interface{})
+	for _, vendor := range []string{"Hpe", "Hp"} {
+		if v, ok := oem[vendor].(map[string]interface{}); ok {
+			if lic, ok := v["License"].(map[string]interface{}); ok {
+				if s := str(lic["LicenseString"]); s != "" {
+					return s
+				}
+			}
+		}
+	}
+	if dell, ok := oem["Dell"].(map[string]interface{}); ok {
+		if s := str(dell["License"]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
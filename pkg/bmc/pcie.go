@@ -0,0 +1,106 @@
+package bmc
+
+import (
+	"errors"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// collectPCIe walks each chassis' PCIeDevices collection so GPUs,
+// NICs, and HBAs show up even when the host OS cannot see them (powered
+// off, passthrough, or no driver). Slot ordinals come from the chassis
+// PCIeSlots resource when it links the devices. Returns the first
+// failure while still visiting every chassis.
+func (c *Client) collectPCIe(chassisRefs []string, inv *inventory.BMCInventory) error {
+	var firstErr error
+	for _, chassisURL := range chassisRefs {
+		slots := c.pcieSlotMap(chassisURL)
+		devices, err := c.getMembers(chassisURL + "/PCIeDevices")
+		if err != nil {
+			if firstErr == nil && !errors.Is(err, errNotFound) {
+				firstErr = err
+			}
+			continue
+		}
+		chassisID := ""
+		if len(chassisRefs) > 1 {
+			if ch, err := c.get(chassisURL); err == nil {
+				chassisID = str(ch["Id"])
+			}
+		}
+		for _, raw := range devices {
+			var dev pcieDevice
+			if err := decodeResource(raw, &dev); err != nil {
+				continue
+			}
+			name := dev.Name
+			if name == "" {
+				name = dev.ID
+			}
+			ref := odataID(raw)
+			inv.PCIeDevices = append(inv.PCIeDevices, inventory.BMCPCIeDevice{
+				Name:            name,
+				Manufacturer:    dev.Manufacturer,
+				Model:           dev.Model,
+				DeviceClass:     c.pcieDeviceClass(dev),
+				FirmwareVersion: dev.FirmwareVersion,
+				Slot:            slots[ref],
+				Health:          dev.Status.Health,
+				Chassis:         chassisID,
+			})
+		}
+	}
+	return firstErr
+}
+
+// pcieDeviceClass reads the class of the device's first function.
+func (c *Client) pcieDeviceClass(dev pcieDevice) string {
+	var fnRef string
+	if len(dev.Links.PCIeFunctions) > 0 {
+		fnRef = dev.Links.PCIeFunctions[0].ODataID
+	} else if dev.PCIeFunctions.ODataID != "" {
+		fns, err := c.getMembers(dev.PCIeFunctions.ODataID)
+		if err != nil || len(fns) == 0 {
+			return ""
+		}
+		var fn pcieFunction
+		if decodeResource(fns[0], &fn) == nil {
+			return fn.DeviceClass
+		}
+		return ""
+	}
+	if fnRef == "" {
+		return ""
+	}
+	raw, err := c.get(fnRef)
+	if err != nil {
+		return ""
+	}
+	var fn pcieFunction
+	if decodeResource(raw, &fn) != nil {
+		return ""
+	}
+	return fn.DeviceClass
+}
+
+// pcieSlotMap maps device resource URLs to physical slot ordinals.
+func (c *Client) pcieSlotMap(chassisURL string) map[string]int {
+	raw, err := c.get(chassisURL + "/PCIeSlots")
+	if err != nil {
+		return nil
+	}
+	var ps pcieSlots
+	if decodeResource(raw, &ps) != nil {
+		return nil
+	}
+	slots := make(map[string]int)
+	for _, s := range ps.Slots {
+		ordinal := int(s.Location.PartLocation.LocationOrdinalValue)
+		for _, ref := range s.Links.PCIeDevice {
+			if ref.ODataID != "" {
+				slots[ref.ODataID] = ordinal
+			}
+		}
+	}
+	return slots
+}
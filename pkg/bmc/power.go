@@ -0,0 +1,114 @@
+package bmc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+)
+
+// ResetTypes the agent accepts in power actions, matching the Redfish
+// ComputerSystem.Reset vocabulary.
+var ResetTypes = map[string]bool{
+	"On":               true,
+	"ForceOff":         true,
+	"GracefulShutdown": true,
+	"ForceRestart":     true,
+	"PowerCycle":       true,
+}
+
+// ipmiPowerCommands maps the Redfish reset vocabulary onto ipmitool
+// chassis power subcommands.
+var ipmiPowerCommands = map[string]string{
+	"On":               "on",
+	"ForceOff":         "off",
+	"GracefulShutdown": "soft",
+	"ForceRestart":     "reset",
+	"PowerCycle":       "cycle",
+}
+
+// PowerAction executes one power control action against the machine
+// behind this BMC, over whichever protocol the target is configured
+// for.
+func (c *Client) PowerAction(action string) error {
+	if !ResetTypes[action] {
+		return fmt.Errorf("unknown power action %q", action)
+	}
+	if c.cfg.Protocol == "ipmi" {
+		return c.ipmiPower(action)
+	}
+	return c.redfishReset(action)
+}
+
+// redfishReset posts the ComputerSystem.Reset action.
+func (c *Client) redfishReset(resetType string) error {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return fmt.Errorf("service root: %w", err)
+	}
+	systemURL, err := c.discoverSystemURL(root)
+	if err != nil {
+		return err
+	}
+	return c.post(systemURL+"/Actions/ComputerSystem.Reset",
+		map[string]string{"ResetType": resetType})
+}
+
+// ipmiPower shells out to ipmitool; implementing the RMCP+ session
+// protocol in-tree is not worth it for five chassis commands.
+func (c *Client) ipmiPower(action string) error {
+	out, err := c.ipmiCommand("chassis", "power", ipmiPowerCommands[action]).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ipmitool: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// ipmiCommand builds an ipmitool invocation for this target over the
+// lanplus (IPMI v2.0) interface, which modern BMCs require. The
+// password travels in the environment via -E, never on the command
+// line where the process list would expose it.
+func (c *Client) ipmiCommand(args ...string) *exec.Cmd {
+	base := []string{"-I", "lanplus", "-H", c.cfg.IP, "-U", c.cfg.Username, "-E"}
+	cmd := exec.Command("ipmitool", append(base, args...)...)
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+c.cfg.Password)
+	return cmd
+}
+
+// post sends a Redfish action with the same session handling as get: a
+// 401 drops the cached session and retries once.
+func (c *Client) post(path string, payload interface{}) error {
+	resp, err := c.postOnce(path, payload)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.dropSession()
+		if resp, err = c.postOnce(path, payload); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", path, resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) postOnce(path string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	c.ensureSession()
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req)
+}
@@ -0,0 +1,72 @@
+package bmc
+
+import "fmt"
+
+// PowerCapInfo is the chassis power limit state.
+type PowerCapInfo struct {
+	// LimitWatts is the active cap; zero means uncapped.
+	LimitWatts float64 `json:"limit_watts"`
+	// CapacityWatts is the chassis power capacity, the natural upper
+	// bound for a cap, when the BMC reports one.
+	CapacityWatts float64 `json:"capacity_watts,omitempty"`
+}
+
+// PowerCap reads the power limit from the first chassis exposing a
+// PowerControl entry.
+func (c *Client) PowerCap() (*PowerCapInfo, error) {
+	_, power, err := c.powerControl()
+	if err != nil {
+		return nil, err
+	}
+	info := &PowerCapInfo{
+		CapacityWatts: float64(power.PowerControl[0].PowerCapacityWatts),
+	}
+	if l := power.PowerControl[0].PowerLimit.LimitInWatts; l != nil {
+		info.LimitWatts = float64(*l)
+	}
+	return info, nil
+}
+
+// SetPowerCap sets the chassis power limit in watts; zero removes the
+// cap.
+func (c *Client) SetPowerCap(watts float64) error {
+	powerURL, _, err := c.powerControl()
+	if err != nil {
+		return err
+	}
+	// A null LimitInWatts clears the cap per the Redfish schema.
+	var limit interface{}
+	if watts > 0 {
+		limit = watts
+	}
+	return c.patch(powerURL, map[string]interface{}{
+		"PowerControl": []interface{}{
+			map[string]interface{}{
+				"PowerLimit": map[string]interface{}{"LimitInWatts": limit},
+			},
+		},
+	})
+}
+
+// powerControl finds the first chassis Power resource carrying a
+// PowerControl entry and returns its URL and decoded form.
+func (c *Client) powerControl() (string, *powerResource, error) {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return "", nil, fmt.Errorf("service root: %w", err)
+	}
+	for _, chassisURL := range c.chassisMembers(root) {
+		raw, err := c.get(chassisURL + "/Power")
+		if err != nil {
+			continue
+		}
+		var power powerResource
+		if decodeResource(raw, &power) != nil {
+			continue
+		}
+		if len(power.PowerControl) > 0 {
+			return chassisURL + "/Power", &power, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no chassis exposes a PowerControl entry")
+}
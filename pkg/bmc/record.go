@@ -0,0 +1,57 @@
+package bmc
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Recording and replay of Redfish traffic. A target with record_dir
+// set mirrors every response body into the directory, laid out by URL
+// path; NewReplayHandler serves such a directory back as a mock BMC.
+// Collector changes can then be validated against captured vendor
+// behaviors without lab hardware.
+
+// recordResponse mirrors one response body under the fixture
+// directory. Best effort; recording never fails a collection.
+func (c *Client) recordResponse(path string, body []byte) {
+	file := fixturePath(c.cfg.RecordDir, path)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(file, body, 0o644)
+}
+
+// fixturePath maps a request path, query included, to its file under
+// dir.
+func fixturePath(dir, reqPath string) string {
+	name := strings.TrimPrefix(reqPath, "/")
+	// Queried variants ($expand probes) are distinct captures; '?' is
+	// not usable in Windows file names.
+	name = strings.ReplaceAll(name, "?", "@")
+	return filepath.Join(dir, filepath.FromSlash(name)+".json")
+}
+
+// NewReplayHandler serves a recorded fixture directory as a mock
+// Redfish BMC. Only GET is answered; session creation 404s like a BMC
+// without a SessionService, keeping replayed clients on Basic auth.
+func NewReplayHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "not supported in replay", http.StatusNotFound)
+			return
+		}
+		p := r.URL.Path
+		if r.URL.RawQuery != "" {
+			p += "?" + r.URL.RawQuery
+		}
+		body, err := os.ReadFile(fixturePath(dir, p))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
@@ -0,0 +1,96 @@
+package bmc
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// breakerThreshold is how many consecutive failed requests trip the
+// circuit breaker.
+const breakerThreshold = 3
+
+// retryBackoff is the first retry delay; each further attempt doubles
+// it. A variable so tests can shrink it.
+var retryBackoff = time.Second
+
+// breaker short-circuits requests to a BMC that keeps failing, so one
+// sick target does not stretch every collection cycle to minutes.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether a request may proceed. During the cooldown it
+// returns the time the circuit reopens; after the cooldown the next
+// request goes through as a probe.
+func (b *breaker) allow() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return b.openUntil, false
+	}
+	return time.Time{}, true
+}
+
+// observe records the outcome of a request; cooldown is how long to
+// hold the circuit open once the failure threshold is reached.
+func (b *breaker) observe(ok bool, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		b.openUntil = time.Now().Add(cooldown)
+		b.failures = 0
+	}
+}
+
+// do sends a request through the retry and circuit-breaker policy.
+// Timeouts and transient 5xx responses are retried with exponential
+// backoff; repeated failures open the breaker for the configured
+// cooldown.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if until, ok := c.breaker.allow(); !ok {
+		return nil, fmt.Errorf("bmc %s: unreachable, retrying after %s", c.cfg.Name, time.Until(until).Round(time.Second))
+	}
+	retries := c.cfg.MaxRetries
+	if retries < 0 {
+		retries = 0
+	}
+	var resp *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		resp, err = c.httpClient.Do(req)
+		if !transient(resp, err) || attempt >= retries {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(retryBackoff << attempt)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	cooldown := time.Duration(c.cfg.BreakerCooldownSeconds) * time.Second
+	c.breaker.observe(!transient(resp, err), cooldown)
+	return resp, err
+}
+
+// transient reports whether a request outcome is worth retrying:
+// transport errors (timeouts included) and 5xx responses other than
+// Not Implemented.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
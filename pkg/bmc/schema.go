@@ -0,0 +1,254 @@
+package bmc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Typed views of the Redfish resources the agent consumes. Decoding
+// into these structs replaces the per-field type assertions that used
+// to be spread through the collection code, and absorbs nulls and
+// numeric representation differences in one place. Only the fields the
+// agent reads are declared; unknown fields are ignored. A full typed
+// client (gofish) would model hundreds of resources for the handful
+// used here, so the structs are kept in-tree instead. Traversal
+// (@odata.id links) and vendor OEM sections stay on the generic map
+// form by design.
+
+// decodeResource converts an already-fetched generic resource into a
+// typed view.
+func decodeResource(m map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// flexFloat decodes a JSON number, a numeric string, or null — BMC
+// firmware disagrees on which one a reading is. Values that parse as
+// none of those decode to zero rather than failing the whole resource.
+type flexFloat float64
+
+func (f *flexFloat) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(b)), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		*f = 0
+		return nil
+	}
+	*f = flexFloat(v)
+	return nil
+}
+
+// flexInt is flexFloat truncated to an integer, for counts that some
+// firmware reports as floats or strings.
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	var f flexFloat
+	if err := f.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	*i = flexInt(f)
+	return nil
+}
+
+// redfishRef is a resource reference.
+type redfishRef struct {
+	ODataID string `json:"@odata.id"`
+}
+
+// redfishStatus is the common Status block.
+type redfishStatus struct {
+	Health string `json:"Health"`
+	State  string `json:"State"`
+}
+
+// computerSystem is the subset of a ComputerSystem resource the agent
+// reads.
+type computerSystem struct {
+	ID            string        `json:"Id"`
+	Manufacturer  string        `json:"Manufacturer"`
+	Model         string        `json:"Model"`
+	SerialNumber  string        `json:"SerialNumber"`
+	BiosVersion   string        `json:"BiosVersion"`
+	PowerState    string        `json:"PowerState"`
+	Status        redfishStatus `json:"Status"`
+	MemorySummary struct {
+		TotalSystemMemoryGiB flexFloat `json:"TotalSystemMemoryGiB"`
+	} `json:"MemorySummary"`
+	Boot struct {
+		BootSourceOverrideTarget  string   `json:"BootSourceOverrideTarget"`
+		BootSourceOverrideEnabled string   `json:"BootSourceOverrideEnabled"`
+		BootSourceOverrideMode    string   `json:"BootSourceOverrideMode"`
+		BootOrder                 []string `json:"BootOrder"`
+	} `json:"Boot"`
+	Links struct {
+		Chassis []redfishRef `json:"Chassis"`
+	} `json:"Links"`
+}
+
+type processor struct {
+	ProcessorType string        `json:"ProcessorType"`
+	Manufacturer  string        `json:"Manufacturer"`
+	Model         string        `json:"Model"`
+	TotalCores    flexInt       `json:"TotalCores"`
+	TotalThreads  flexInt       `json:"TotalThreads"`
+	MaxSpeedMHz   flexFloat     `json:"MaxSpeedMHz"`
+	Status        redfishStatus `json:"Status"`
+}
+
+type memoryModule struct {
+	CapacityMiB flexFloat `json:"CapacityMiB"`
+}
+
+type drive struct {
+	Name          string        `json:"Name"`
+	Model         string        `json:"Model"`
+	SerialNumber  string        `json:"SerialNumber"`
+	MediaType     string        `json:"MediaType"`
+	CapacityBytes flexFloat     `json:"CapacityBytes"`
+	Status        redfishStatus `json:"Status"`
+	// Revision is the drive firmware version; some firmware reports
+	// FirmwareVersion instead.
+	Revision           string     `json:"Revision"`
+	FirmwareVersion    string     `json:"FirmwareVersion"`
+	PredictedMediaLife *flexFloat `json:"PredictedMediaLifeLeftPercent"`
+	NegotiatedSpeedGbs flexFloat  `json:"NegotiatedSpeedGbs"`
+	CapableSpeedGbs    flexFloat  `json:"CapableSpeedGbs"`
+	EncryptionAbility  string     `json:"EncryptionAbility"`
+	EncryptionStatus   string     `json:"EncryptionStatus"`
+	FailurePredicted   bool       `json:"FailurePredicted"`
+}
+
+type thermalResource struct {
+	Temperatures []struct {
+		Name                   string        `json:"Name"`
+		ReadingCelsius         flexFloat     `json:"ReadingCelsius"`
+		UpperThresholdCritical flexFloat     `json:"UpperThresholdCritical"`
+		Status                 redfishStatus `json:"Status"`
+	} `json:"Temperatures"`
+	Fans []struct {
+		Name                   string        `json:"Name"`
+		FanName                string        `json:"FanName"`
+		Reading                flexFloat     `json:"Reading"`
+		LowerThresholdCritical flexFloat     `json:"LowerThresholdCritical"`
+		Status                 redfishStatus `json:"Status"`
+	} `json:"Fans"`
+}
+
+type powerResource struct {
+	PowerControl []struct {
+		PowerConsumedWatts flexFloat `json:"PowerConsumedWatts"`
+		PowerCapacityWatts flexFloat `json:"PowerCapacityWatts"`
+		PowerLimit         struct {
+			LimitInWatts *flexFloat `json:"LimitInWatts"`
+		} `json:"PowerLimit"`
+	} `json:"PowerControl"`
+	PowerSupplies []struct {
+		Name               string        `json:"Name"`
+		Model              string        `json:"Model"`
+		SerialNumber       string        `json:"SerialNumber"`
+		PowerCapacityWatts flexFloat     `json:"PowerCapacityWatts"`
+		Status             redfishStatus `json:"Status"`
+	} `json:"PowerSupplies"`
+}
+
+type managerResource struct {
+	FirmwareVersion    string        `json:"FirmwareVersion"`
+	Model              string        `json:"Model"`
+	Status             redfishStatus `json:"Status"`
+	EthernetInterfaces redfishRef    `json:"EthernetInterfaces"`
+	NetworkProtocol    redfishRef    `json:"NetworkProtocol"`
+}
+
+type ethernetInterface struct {
+	ID            string `json:"Id"`
+	MACAddress    string `json:"MACAddress"`
+	IPv4Addresses []struct {
+		Address       string `json:"Address"`
+		AddressOrigin string `json:"AddressOrigin"`
+	} `json:"IPv4Addresses"`
+	VLAN struct {
+		VLANEnable bool    `json:"VLANEnable"`
+		VLANID     flexInt `json:"VLANId"`
+	} `json:"VLAN"`
+}
+
+type networkProtocol struct {
+	NTP struct {
+		ProtocolEnabled bool     `json:"ProtocolEnabled"`
+		NTPServers      []string `json:"NTPServers"`
+	} `json:"NTP"`
+	HTTPS struct {
+		Certificates redfishRef `json:"Certificates"`
+	} `json:"HTTPS"`
+}
+
+// certSubject is the Subject / Issuer block of a Certificate resource.
+type certSubject struct {
+	CommonName   string `json:"CommonName"`
+	Organization string `json:"Organization"`
+	Country      string `json:"Country"`
+}
+
+type certificateResource struct {
+	Subject        certSubject `json:"Subject"`
+	Issuer         certSubject `json:"Issuer"`
+	ValidNotBefore string      `json:"ValidNotBefore"`
+	ValidNotAfter  string      `json:"ValidNotAfter"`
+	SerialNumber   string      `json:"SerialNumber"`
+}
+
+type pcieDevice struct {
+	ID              string        `json:"Id"`
+	Name            string        `json:"Name"`
+	Manufacturer    string        `json:"Manufacturer"`
+	Model           string        `json:"Model"`
+	FirmwareVersion string        `json:"FirmwareVersion"`
+	Status          redfishStatus `json:"Status"`
+	// The function list moved between schema revisions: older firmware
+	// links it, newer firmware exposes a collection.
+	PCIeFunctions redfishRef `json:"PCIeFunctions"`
+	Links         struct {
+		PCIeFunctions []redfishRef `json:"PCIeFunctions"`
+	} `json:"Links"`
+}
+
+type pcieFunction struct {
+	DeviceClass string `json:"DeviceClass"`
+}
+
+type pcieSlots struct {
+	Slots []struct {
+		Location struct {
+			PartLocation struct {
+				LocationOrdinalValue flexInt `json:"LocationOrdinalValue"`
+			} `json:"PartLocation"`
+		} `json:"Location"`
+		Links struct {
+			PCIeDevice []redfishRef `json:"PCIeDevice"`
+		} `json:"Links"`
+	} `json:"Slots"`
+}
+
+type firmwareComponent struct {
+	ID         string `json:"Id"`
+	Name       string `json:"Name"`
+	Version    string `json:"Version"`
+	Updateable bool   `json:"Updateable"`
+}
+
+type taskResource struct {
+	TaskState       string  `json:"TaskState"`
+	PercentComplete flexInt `json:"PercentComplete"`
+	Messages        []struct {
+		Message string `json:"Message"`
+	} `json:"Messages"`
+}
@@ -0,0 +1,118 @@
+package bmc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// sessionsPath is where Redfish creates login sessions.
+const sessionsPath = "/redfish/v1/SessionService/Sessions"
+
+// session caches one Redfish login. Some BMCs rate-limit or even lock
+// accounts under repeated Basic auth, so the token from a single login
+// is reused across requests and only replaced when the BMC rejects it.
+type session struct {
+	mu sync.Mutex
+	// token is sent as X-Auth-Token; empty when not logged in.
+	token string
+	// location is the session resource, deleted on logout.
+	location string
+	// unsupported marks BMCs without a usable SessionService; requests
+	// then stay on Basic auth for the client's lifetime.
+	unsupported bool
+}
+
+// authorize attaches credentials to a request: the cached session
+// token when one exists, Basic auth otherwise.
+func (c *Client) authorize(req *http.Request) {
+	c.session.mu.Lock()
+	token := c.session.token
+	c.session.mu.Unlock()
+	if token != "" {
+		req.Header.Set("X-Auth-Token", token)
+		return
+	}
+	req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+}
+
+// ensureSession logs in when no session is cached and the BMC supports
+// sessions. Safe to call before every request; it is a no-op in the
+// common case.
+func (c *Client) ensureSession() {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	if c.session.token != "" || c.session.unsupported {
+		return
+	}
+	token, location, err := c.createSession()
+	if err != nil {
+		// No SessionService, or it refused us: stay on Basic auth and
+		// do not retry the login on every request.
+		c.session.unsupported = true
+		return
+	}
+	c.session.token = token
+	c.session.location = location
+}
+
+// dropSession forgets a token the BMC no longer accepts, so the next
+// request logs in again.
+func (c *Client) dropSession() {
+	c.session.mu.Lock()
+	c.session.token = ""
+	c.session.location = ""
+	c.session.mu.Unlock()
+}
+
+// createSession performs the Redfish login and returns the token and
+// the session resource location.
+func (c *Client) createSession() (token, location string, err error) {
+	body, err := json.Marshal(map[string]string{
+		"UserName": c.cfg.Username,
+		"Password": c.cfg.Password,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+sessionsPath, bytes.NewReader(body))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("session login: %s", resp.Status)
+	}
+	token = resp.Header.Get("X-Auth-Token")
+	if token == "" {
+		return "", "", fmt.Errorf("session login: no X-Auth-Token in response")
+	}
+	return token, resp.Header.Get("Location"), nil
+}
+
+// Logout deletes the cached session on the BMC. Called on shutdown so
+// long-lived agents do not leak sessions until the BMC expires them.
+func (c *Client) Logout() {
+	c.session.mu.Lock()
+	token, location := c.session.token, c.session.location
+	c.session.token, c.session.location = "", ""
+	c.session.mu.Unlock()
+	if token == "" || location == "" {
+		return
+	}
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+location, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("X-Auth-Token", token)
+	if resp, err := c.httpClient.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
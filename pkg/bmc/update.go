@@ -0,0 +1,159 @@
+package bmc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// updatePollInterval spaces task monitor polls during a firmware
+// update.
+var updatePollInterval = 5 * time.Second
+
+// UpdateProgress is one observation of a running firmware update task.
+type UpdateProgress struct {
+	// State is the Redfish TaskState: Running, Completed, Exception, ...
+	State   string `json:"state"`
+	Percent int    `json:"percent"`
+	Message string `json:"message,omitempty"`
+}
+
+// terminalTaskStates end a task monitor poll loop.
+var terminalTaskStates = map[string]bool{
+	"Completed": true, "Exception": true, "Killed": true, "Cancelled": true,
+}
+
+// UpdateFirmware asks the UpdateService to apply the firmware image at
+// imageURI (the BMC downloads it) and follows the task monitor to
+// completion, reporting every state change through progress. Returns
+// nil only when the task completes successfully.
+func (c *Client) UpdateFirmware(ctx context.Context, imageURI string, progress func(UpdateProgress)) error {
+	root, err := c.get("/redfish/v1")
+	if err != nil {
+		return fmt.Errorf("service root: %w", err)
+	}
+	usRef := odataID(root["UpdateService"])
+	if usRef == "" {
+		return fmt.Errorf("bmc has no UpdateService")
+	}
+	us, err := c.get(usRef)
+	if err != nil {
+		return err
+	}
+	actions, _ := us["Actions"].(map[string]interface{})
+	simple, _ := actions["#UpdateService.SimpleUpdate"].(map[string]interface{})
+	target := str(simple["target"])
+	if target == "" {
+		return fmt.Errorf("UpdateService offers no SimpleUpdate action")
+	}
+	taskURL, err := c.postForLocation(target, map[string]interface{}{"ImageURI": imageURI})
+	if err != nil {
+		return fmt.Errorf("SimpleUpdate: %w", err)
+	}
+	if taskURL == "" {
+		// The BMC applied the update synchronously.
+		progress(UpdateProgress{State: "Completed", Percent: 100})
+		return nil
+	}
+	return c.followTask(ctx, taskURL, progress)
+}
+
+// followTask polls a task monitor until it reaches a terminal state.
+func (c *Client) followTask(ctx context.Context, taskURL string, progress func(UpdateProgress)) error {
+	var last UpdateProgress
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(updatePollInterval):
+		}
+		raw, err := c.get(taskURL)
+		if err != nil {
+			return fmt.Errorf("task monitor: %w", err)
+		}
+		var task taskResource
+		if err := decodeResource(raw, &task); err != nil {
+			return fmt.Errorf("task monitor: %w", err)
+		}
+		p := UpdateProgress{State: task.TaskState, Percent: int(task.PercentComplete)}
+		if n := len(task.Messages); n > 0 {
+			p.Message = task.Messages[n-1].Message
+		}
+		if p != last {
+			progress(p)
+			last = p
+		}
+		if terminalTaskStates[p.State] {
+			if p.State != "Completed" {
+				return fmt.Errorf("firmware update ended in state %s: %s", p.State, p.Message)
+			}
+			return nil
+		}
+	}
+}
+
+// collectFirmwareInventory lists every firmware component the
+// UpdateService knows about: BIOS, BMC, NIC and drive firmware, CPLDs,
+// and so on. Older firmware has no such collection.
+func (c *Client) collectFirmwareInventory(root map[string]interface{}) ([]inventory.FirmwareComponent, error) {
+	usRef := odataID(root["UpdateService"])
+	if usRef == "" {
+		return nil, nil
+	}
+	us, err := c.get(usRef)
+	if err != nil {
+		return nil, err
+	}
+	fiRef := odataID(us["FirmwareInventory"])
+	if fiRef == "" {
+		return nil, nil
+	}
+	members, err := c.getMembers(fiRef)
+	if err != nil {
+		return nil, err
+	}
+	var out []inventory.FirmwareComponent
+	for _, raw := range members {
+		var fc firmwareComponent
+		if err := decodeResource(raw, &fc); err != nil {
+			continue
+		}
+		name := fc.Name
+		if name == "" {
+			name = fc.ID
+		}
+		if name == "" {
+			continue
+		}
+		out = append(out, inventory.FirmwareComponent{
+			Name:       name,
+			Version:    fc.Version,
+			Updateable: fc.Updateable,
+		})
+	}
+	return out, nil
+}
+
+// postForLocation sends a Redfish action and returns the Location
+// header, which for long-running actions is the task monitor URL.
+func (c *Client) postForLocation(path string, payload interface{}) (string, error) {
+	resp, err := c.postOnce(path, payload)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.dropSession()
+		if resp, err = c.postOnce(path, payload); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("POST %s: %s", path, resp.Status)
+	}
+	return resp.Header.Get("Location"), nil
+}
@@ -0,0 +1,152 @@
+package bmc
+
+import (
+	"path"
+	"strings"
+
+	"github.com/simonschuang/demo/pkg/inventory"
+)
+
+// vendorAdapter fills the gaps a vendor's Redfish implementation
+// leaves in the standard model: OEM-only sections, renamed fields, and
+// data that predates the standard schema. The generic collection
+// always runs first; adapters only patch what came back empty or
+// unusable.
+type vendorAdapter interface {
+	name() string
+	// enrich patches one system's inventory after the standard
+	// collection. chassis are the chassis resources the system reads
+	// sensors from.
+	enrich(c *Client, systemURL string, chassis []string, inv *inventory.BMCInventory)
+}
+
+// detectVendor selects an adapter from the service root's Vendor
+// field, falling back to the Oem section keys that older firmware uses
+// instead. Unknown vendors run without an adapter.
+func detectVendor(root map[string]interface{}) vendorAdapter {
+	vendor := strings.ToLower(str(root["Vendor"]))
+	if vendor == "" {
+		if oem, ok := root["Oem"].(map[string]interface{}); ok {
+			for k := range oem {
+				vendor += strings.ToLower(k)
+			}
+		}
+	}
+	switch {
+	case strings.Contains(vendor, "dell"):
+		return dellAdapter{}
+	case strings.Contains(vendor, "hpe") || strings.Contains(vendor, "hp"):
+		return iloAdapter{}
+	case strings.Contains(vendor, "lenovo"):
+		return xccAdapter{}
+	case strings.Contains(vendor, "openbmc"):
+		return openBMCAdapter{}
+	}
+	return nil
+}
+
+// dellAdapter covers iDRAC. The service tag lives in the DellSystem
+// OEM resource, and on OEM-rebranded hardware it is the only usable
+// serial number.
+type dellAdapter struct{}
+
+func (dellAdapter) name() string { return "idrac" }
+
+func (dellAdapter) enrich(c *Client, systemURL string, chassis []string, inv *inventory.BMCInventory) {
+	if inv.SerialNumber != "" {
+		return
+	}
+	sys, err := c.get(systemURL)
+	if err != nil {
+		return
+	}
+	oem, _ := sys["Oem"].(map[string]interface{})
+	dell, _ := oem["Dell"].(map[string]interface{})
+	ref := odataID(dell["DellSystem"])
+	if ref == "" {
+		return
+	}
+	ds, err := c.get(ref)
+	if err != nil {
+		return
+	}
+	if tag := str(ds["ChassisServiceTag"]); tag != "" {
+		inv.SerialNumber = tag
+	}
+}
+
+// iloAdapter covers HPE iLO. iLO 4 predates the standard Reading
+// field on fans and reports CurrentReading instead, which leaves every
+// fan at zero after the generic collection.
+type iloAdapter struct{}
+
+func (iloAdapter) name() string { return "ilo" }
+
+func (iloAdapter) enrich(c *Client, systemURL string, chassis []string, inv *inventory.BMCInventory) {
+	for _, fi := range inv.Fans {
+		if fi.RPM != 0 {
+			return
+		}
+	}
+	if len(inv.Fans) == 0 {
+		return
+	}
+	readings := make(map[string]int)
+	for _, chassisURL := range chassis {
+		thermal, err := c.get(chassisURL + "/Thermal")
+		if err != nil {
+			continue
+		}
+		fans, _ := thermal["Fans"].([]interface{})
+		for _, f := range fans {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name := str(fm["Name"])
+			if name == "" {
+				name = str(fm["FanName"])
+			}
+			if v, ok := fm["CurrentReading"].(float64); ok {
+				readings[name] = int(v)
+			}
+		}
+	}
+	for i := range inv.Fans {
+		if v, ok := readings[inv.Fans[i].Name]; ok {
+			inv.Fans[i].RPM = v
+		}
+	}
+}
+
+// xccAdapter covers Lenovo XCC, which reports the machine type-model
+// in SKU and often leaves Model empty.
+type xccAdapter struct{}
+
+func (xccAdapter) name() string { return "xcc" }
+
+func (xccAdapter) enrich(c *Client, systemURL string, chassis []string, inv *inventory.BMCInventory) {
+	if inv.Model != "" {
+		return
+	}
+	sys, err := c.get(systemURL)
+	if err != nil {
+		return
+	}
+	inv.Model = str(sys["SKU"])
+}
+
+// openBMCAdapter covers OpenBMC builds whose sensor names are full
+// D-Bus object paths; keep only the final segment.
+type openBMCAdapter struct{}
+
+func (openBMCAdapter) name() string { return "openbmc" }
+
+func (openBMCAdapter) enrich(c *Client, systemURL string, chassis []string, inv *inventory.BMCInventory) {
+	for i := range inv.Temperatures {
+		inv.Temperatures[i].Name = path.Base(inv.Temperatures[i].Name)
+	}
+	for i := range inv.Fans {
+		inv.Fans[i].Name = path.Base(inv.Fans[i].Name)
+	}
+}
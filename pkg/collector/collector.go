@@ -0,0 +1,60 @@
+// Package collector defines the interface and registry through which
+// the agent schedules inventory collection. Built-in collectors (local
+// host, BMC targets) and user-provided ones register the same way, so
+// the scheduler in cmd/agent needs no knowledge of concrete types.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Collector gathers one kind of inventory on its own schedule.
+type Collector interface {
+	// Name identifies the collector in logs and configuration, e.g.
+	// "local" or "bmc:rack1-node3".
+	Name() string
+	// Interval is the collection period. Zero means the agent's default
+	// collect interval.
+	Interval() time.Duration
+	// Collect gathers a snapshot and returns the message type and
+	// payload to send. A nil payload with a nil error means there is
+	// nothing to report this cycle.
+	Collect(ctx context.Context) (msgType string, data map[string]interface{}, err error)
+}
+
+// Registry holds the registered collectors in registration order.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+	names      map[string]bool
+}
+
+// NewRegistry builds an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Register adds a collector. Names must be unique; a duplicate is a
+// wiring bug and is reported rather than silently shadowed.
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[c.Name()] {
+		return fmt.Errorf("collector %q already registered", c.Name())
+	}
+	r.names[c.Name()] = true
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+// All returns the registered collectors in registration order.
+func (r *Registry) All() []Collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Collector, len(r.collectors))
+	copy(out, r.collectors)
+	return out
+}
@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeCollector struct{ name string }
+
+func (f fakeCollector) Name() string            { return f.name }
+func (f fakeCollector) Interval() time.Duration { return 0 }
+func (f fakeCollector) Collect(context.Context) (string, map[string]interface{}, error) {
+	return "", nil, nil
+}
+
+func TestRegistryOrderAndDuplicates(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(fakeCollector{"local"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.Register(fakeCollector{"bmc:a"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.Register(fakeCollector{"local"}); err == nil {
+		t.Error("duplicate name accepted")
+	}
+	all := reg.All()
+	if len(all) != 2 || all[0].Name() != "local" || all[1].Name() != "bmc:a" {
+		t.Errorf("All() = %v", all)
+	}
+}
+
+func TestAllReturnsCopy(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(fakeCollector{"local"})
+	all := reg.All()
+	all[0] = fakeCollector{"mutated"}
+	if reg.All()[0].Name() != "local" {
+		t.Error("All() exposed internal slice")
+	}
+}
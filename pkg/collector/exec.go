@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+const (
+	// defaultExecTimeout bounds a run of an external collector command.
+	defaultExecTimeout = 30 * time.Second
+	// maxExecOutput caps how much stdout an external collector may
+	// produce; anything larger is a bug or an attack, not inventory.
+	maxExecOutput = 1 << 20
+)
+
+// Exec runs an external command and reports its JSON stdout as an
+// inventory section named after the collector. It lets operators extend
+// inventory without recompiling the agent.
+type Exec struct {
+	name     string
+	command  string
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewExec builds an external command collector. A zero timeout uses
+// the default.
+func NewExec(name, command string, interval, timeout time.Duration) *Exec {
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	return &Exec{name: name, command: command, interval: interval, timeout: timeout}
+}
+
+// Name implements Collector.
+func (e *Exec) Name() string { return "exec:" + e.name }
+
+// Interval implements Collector.
+func (e *Exec) Interval() time.Duration { return e.interval }
+
+// Collect runs the command through the shell and parses its stdout as
+// JSON. The payload nests the result under the collector name so the
+// server can merge it into the host's inventory.
+func (e *Exec) Collect(ctx context.Context) (string, map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", e.command)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	// Don't let an orphaned grandchild holding the stdout pipe keep a
+	// timed-out run alive.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		return "", nil, fmt.Errorf("exec collector %s: %w", e.name, err)
+	}
+	if stdout.Len() > maxExecOutput {
+		return "", nil, fmt.Errorf("exec collector %s: output exceeds %d bytes", e.name, maxExecOutput)
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &parsed); err != nil {
+		return "", nil, fmt.Errorf("exec collector %s: stdout is not JSON: %w", e.name, err)
+	}
+	return websocket.TypeInventory, map[string]interface{}{e.name: parsed}, nil
+}
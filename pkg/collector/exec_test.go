@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+func TestExecCollect(t *testing.T) {
+	e := NewExec("raid", `echo '{"status":"ok","disks":2}'`, time.Minute, 0)
+	if e.Name() != "exec:raid" {
+		t.Errorf("Name() = %q", e.Name())
+	}
+	msgType, data, err := e.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if msgType != websocket.TypeInventory {
+		t.Errorf("msgType = %q", msgType)
+	}
+	section, ok := data["raid"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("data[raid] = %#v", data["raid"])
+	}
+	if section["status"] != "ok" {
+		t.Errorf("status = %v", section["status"])
+	}
+}
+
+func TestExecCollectBadJSON(t *testing.T) {
+	e := NewExec("bad", "echo not-json", time.Minute, 0)
+	if _, _, err := e.Collect(context.Background()); err == nil {
+		t.Error("non-JSON stdout accepted")
+	}
+}
+
+func TestExecCollectTimeout(t *testing.T) {
+	e := NewExec("slow", "sleep 5", time.Minute, 50*time.Millisecond)
+	start := time.Now()
+	if _, _, err := e.Collect(context.Background()); err == nil {
+		t.Error("timed-out command reported success")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+}
+
+func TestExecCollectCommandFailure(t *testing.T) {
+	e := NewExec("fail", "exit 3", time.Minute, 0)
+	if _, _, err := e.Collect(context.Background()); err == nil {
+		t.Error("failing command reported success")
+	}
+}
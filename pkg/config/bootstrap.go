@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Remote bootstrap lets a host ship with a minimal config (server URL,
+// client ID, enrollment token); the server supplies the rest on first
+// connect and the agent caches it locally, so provisioning does not
+// require templating full YAML per host.
+
+// DefaultBootstrapCachePath is where the fetched configuration is
+// cached when bootstrap.cache_path is not set.
+const DefaultBootstrapCachePath = "/var/lib/agent/bootstrap.json"
+
+// BootstrapConfig controls fetching the remainder of the configuration
+// from the server.
+type BootstrapConfig struct {
+	Enabled bool `json:"enabled"`
+	// CachePath stores the fetched configuration for use on later
+	// starts when the server is unreachable.
+	CachePath string `json:"cache_path"`
+}
+
+// LoadBootstrapCache reads a previously cached bootstrap configuration.
+// A missing file returns an empty map.
+func LoadBootstrapCache(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parse bootstrap cache %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// SaveBootstrapCache persists a fetched bootstrap configuration.
+func SaveBootstrapCache(path string, updates map[string]interface{}) error {
+	return writeJSONFile(path, updates)
+}
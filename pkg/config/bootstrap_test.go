@@ -0,0 +1,57 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBootstrapCacheRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bootstrap.json")
+	updates := map[string]interface{}{"log_level": "debug", "collect_interval": 120.0}
+	if err := SaveBootstrapCache(path, updates); err != nil {
+		t.Fatalf("SaveBootstrapCache: %v", err)
+	}
+	got, err := LoadBootstrapCache(path)
+	if err != nil {
+		t.Fatalf("LoadBootstrapCache: %v", err)
+	}
+	if got["log_level"] != "debug" {
+		t.Errorf("cache = %v", got)
+	}
+}
+
+func TestLoadBootstrapCacheMissingFile(t *testing.T) {
+	got, err := LoadBootstrapCache(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("missing cache = %v, %v; want empty, nil", got, err)
+	}
+}
+
+func TestLoadConfigOverlaysBootstrapCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "bootstrap.json")
+	if err := SaveBootstrapCache(cache, map[string]interface{}{
+		"log_level":          "debug",
+		"heartbeat_interval": 10.0,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+heartbeat_interval: 15
+bootstrap:
+  enabled: true
+  cache_path: `+cache+`
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want cached debug", cfg.LogLevel)
+	}
+	// The local file wins over the cached server copy.
+	if cfg.HeartbeatInterval != 15 {
+		t.Errorf("HeartbeatInterval = %d, want local 15", cfg.HeartbeatInterval)
+	}
+}
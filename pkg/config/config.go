@@ -0,0 +1,1131 @@
+// Package config loads and validates the agent configuration file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/simonschuang/demo/internal/tomlx"
+	"github.com/simonschuang/demo/internal/yamlx"
+)
+
+// Config is the top-level agent configuration.
+type Config struct {
+	// ServerURL is the WebSocket endpoint of the management server,
+	// e.g. wss://mgmt.example.com/agent.
+	ServerURL string `json:"server_url"`
+	// ClientID uniquely identifies this agent to the server. When
+	// empty, a stable identifier is derived from the machine ID and
+	// persisted to ClientIDFile.
+	ClientID string `json:"client_id"`
+	// ClientIDFile stores the auto-generated client ID across
+	// reinstalls. Default /var/lib/agent/client-id.
+	ClientIDFile string `json:"client_id_file"`
+	// ClientToken authenticates the agent to the server. It may be a
+	// secret reference (file:, env:, exec:), resolved at load and
+	// reload.
+	ClientToken string `json:"client_token"`
+	// ClientTokenFile, when set, is read for the token on every connect
+	// and refresh, supporting rotation without restart.
+	ClientTokenFile string `json:"client_token_file"`
+	// ClientTokenCommand, when set, is executed and its stdout used as
+	// the token.
+	ClientTokenCommand string `json:"client_token_command"`
+	// CredentialsKeyFile is the machine-local key used to decrypt enc:
+	// values produced by `agent encrypt`.
+	CredentialsKeyFile string `json:"credentials_key_file"`
+	// InsecureSkipVerify disables TLS certificate verification for the
+	// server connection. Intended for lab use only. Deprecated in
+	// favor of tls.insecure_skip_verify.
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+	// TLS tunes the server connection: CA bundle, minimum version,
+	// cipher suites.
+	TLS TLSConfig `json:"tls"`
+	// PinSHA256 lists base64 SPKI SHA-256 fingerprints; when non-empty,
+	// the server must present a certificate matching one of them. Pins
+	// replace CA validation.
+	PinSHA256 []string `json:"pin_sha256"`
+
+	// ProxyFromEnv controls whether HTTPS_PROXY / NO_PROXY environment
+	// variables apply to the WebSocket connection and Redfish requests.
+	// Defaults to true when omitted; set false to always dial directly.
+	ProxyFromEnv *bool `json:"proxy_from_env"`
+
+	// HeartbeatInterval is the heartbeat period, as integer seconds or
+	// a duration string ("30s", "5m").
+	HeartbeatInterval Seconds `json:"heartbeat_interval"`
+	// ReconnectInterval is the delay between reconnect attempts.
+	ReconnectInterval Seconds `json:"reconnect_interval"`
+	// CollectInterval is the default inventory collection period, used
+	// for any collector without its own interval.
+	CollectInterval Seconds `json:"collect_interval"`
+	// JitterPercent randomizes each heartbeat and collection period by
+	// up to this percentage in either direction, so identically
+	// configured fleets do not all report in the same second. 0-50;
+	// default 0 (no jitter).
+	JitterPercent int `json:"jitter_percent"`
+	// Collectors tunes individual collectors by name. "local" and
+	// "bmc" schedule the local and BMC inventory runs; "host", "cpu",
+	// "memory", "disk", "network", "pci", "services", "containers",
+	// "ports", "kernel", "cloud", "numa", "sensors", "firmware",
+	// "timesync", and "certs" toggle sections of the local snapshot.
+	// Absent
+	// collectors are enabled on the default interval, except
+	// "processes", "users", "power", and "bmc_events" which must be
+	// enabled explicitly.
+	Collectors map[string]CollectorConfig `json:"collectors"`
+	// Services filters the systemd service section of the local
+	// snapshot.
+	Services ServicesConfig `json:"services"`
+	// Sysctls lists the kernel parameters to report, by dotted name.
+	// Defaults to a small set relevant to drift auditing.
+	Sysctls []string `json:"sysctls"`
+	// CustomCollectors runs external commands whose JSON stdout extends
+	// the inventory, keyed by collector name.
+	CustomCollectors []CustomCollectorConfig `json:"custom_collectors"`
+	// CertScan lists TLS certificates to watch for expiry.
+	CertScan CertScanConfig `json:"cert_scan"`
+	// SnapshotPath, when set, receives the latest local inventory as
+	// JSON after each collection, replaced atomically.
+	SnapshotPath string `json:"snapshot_path"`
+	// Metrics streams lightweight utilization samples at a short
+	// interval, separate from the heavy inventory payload.
+	Metrics MetricsConfig `json:"metrics"`
+	// PowerControl governs server-initiated power actions against BMC
+	// targets. Disabled unless explicitly enabled.
+	PowerControl PowerControlConfig `json:"power_control"`
+	// FirmwareUpdate allows server-initiated firmware updates through
+	// the BMC. Disabled unless explicitly enabled.
+	FirmwareUpdate FirmwareUpdateConfig `json:"firmware_update"`
+	// BIOSControl allows server-initiated BIOS setting changes through
+	// the BMC. Disabled unless explicitly enabled.
+	BIOSControl BIOSControlConfig `json:"bios_control"`
+	// BootControl allows server-initiated boot override changes
+	// through the BMC. Disabled unless explicitly enabled.
+	BootControl BootControlConfig `json:"boot_control"`
+	// BMCAccounts allows server-initiated BMC account management and
+	// credential rotation. Disabled unless explicitly enabled.
+	BMCAccounts BMCAccountsConfig `json:"bmc_accounts"`
+	// BMCCerts allows server-initiated BMC HTTPS certificate
+	// management. Disabled unless explicitly enabled.
+	BMCCerts BMCCertsConfig `json:"bmc_certs"`
+	// SOLConsole allows Serial-over-LAN terminal sessions to BMC
+	// targets. Disabled unless explicitly enabled.
+	SOLConsole SOLConsoleConfig `json:"sol_console"`
+	// HealthAlerts enables immediate alert messages when hardware
+	// health readings breach their thresholds.
+	HealthAlerts HealthAlertsConfig `json:"health_alerts"`
+	// Discovery periodically searches the management network for
+	// unmanaged Redfish endpoints and reports candidates to the
+	// server.
+	Discovery DiscoveryConfig `json:"discovery"`
+	// Terminal bounds interactive terminal sessions.
+	Terminal TerminalConfig `json:"terminal"`
+	// CommandPolicy constrains the commands remote operators may run
+	// through terminal sessions and remote execution.
+	CommandPolicy CommandPolicyConfig `json:"command_policy"`
+	// Exec allows non-interactive remote command execution.
+	Exec ExecConfig `json:"exec"`
+	// FileTransfer allows chunked file upload and download within the
+	// configured path allowlist.
+	FileTransfer FileTransferConfig `json:"file_transfer"`
+	// DrainTimeout is how many seconds shutdown waits for queued
+	// messages to flush before closing the connection.
+	DrainTimeout int `json:"drain_timeout"`
+	// PingInterval is the keepalive ping period in seconds. Some
+	// middleboxes kill idle connections quickly and need a short
+	// interval; others rate-limit pings and need a long one.
+	PingInterval int `json:"ping_interval"`
+	// ReadDeadline is how many seconds of silence are tolerated before
+	// the connection is treated as dead. Must exceed PingInterval.
+	ReadDeadline int `json:"read_deadline"`
+
+	// LogLevel is one of debug, info, warn, error.
+	LogLevel string `json:"log_level"`
+
+	// MaxMessageSize is the largest frame, in bytes, sent without
+	// chunking. Larger messages are split transparently and reassembled
+	// by the peer.
+	MaxMessageSize int `json:"max_message_size"`
+
+	// Labels are attached to every outbound message (site, rack,
+	// environment, owner, ...), letting the server slice the fleet
+	// without maintaining its own mapping.
+	Labels map[string]string `json:"labels"`
+
+	// Headers are extra HTTP headers sent with the WebSocket handshake,
+	// for gateways that route or authenticate on headers.
+	Headers map[string]string `json:"headers"`
+	// QueryParams are extra query parameters appended to the dial URL.
+	QueryParams map[string]string `json:"query_params"`
+
+	// RateLimit throttles the outbound WebSocket message path. Zero
+	// values disable the corresponding limit.
+	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// Dedup skips resending messages whose payload is unchanged.
+	Dedup DedupConfig `json:"dedup"`
+
+	// Delta sends inventory_delta messages carrying only changed fields
+	// between periodic full syncs, instead of the full snapshot every
+	// cycle.
+	Delta DeltaConfig `json:"delta"`
+
+	// SendQueue sizes the outbound queues and selects per-type overflow
+	// behavior.
+	SendQueue SendQueueConfig `json:"send_queue"`
+
+	// Bootstrap fetches the remainder of the configuration from the
+	// server on first connect, overlaying the cached copy at load.
+	Bootstrap BootstrapConfig `json:"bootstrap"`
+
+	// Vault, when configured, supplies the client token and BMC
+	// credentials from a central Vault server.
+	Vault VaultConfig `json:"vault"`
+
+	// BMC lists the baseboard management controllers to collect
+	// hardware inventory from. A single mapping is also accepted for
+	// the common one-BMC case. No targets (or empty IPs) disables BMC
+	// collection.
+	BMC BMCList `json:"bmc"`
+	// BMCMaxConcurrent bounds how many BMC targets are polled at once
+	// in fleet/proxy mode. Default 4.
+	BMCMaxConcurrent int `json:"bmc_max_concurrent"`
+}
+
+// DedupConfig controls suppression of unchanged outbound payloads.
+// With thousands of idle servers, identical inventory every cycle is
+// pure waste.
+type DedupConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxAgeSeconds forces a full send when the last delivery of a
+	// type is older than this, even if unchanged. Default 3600.
+	MaxAgeSeconds int `json:"max_age_seconds"`
+	// Types lists the message types to deduplicate. Defaults to
+	// inventory and bmc_inventory.
+	Types []string `json:"types"`
+}
+
+// DeltaConfig controls differential inventory reporting. Static
+// servers resend the same large snapshot every cycle; deltas cut that
+// to a few bytes while full syncs bound any drift.
+type DeltaConfig struct {
+	Enabled bool `json:"enabled"`
+	// FullSyncSeconds forces a full inventory send when the last one is
+	// older than this. Default 3600.
+	FullSyncSeconds int `json:"full_sync_seconds"`
+}
+
+// SendQueueConfig tunes the outbound send queues.
+type SendQueueConfig struct {
+	// Size is the per-priority queue capacity. Default 256.
+	Size int `json:"size"`
+	// Overflow maps a message type to its policy when the queue is
+	// full: drop_new (default), drop_oldest, or block.
+	Overflow map[string]string `json:"overflow"`
+	// BlockTimeoutMS bounds how long a block policy waits for room.
+	// Default 1000.
+	BlockTimeoutMS int `json:"block_timeout_ms"`
+}
+
+// CollectorConfig tunes one named collector.
+type CollectorConfig struct {
+	// Enabled defaults to true when omitted.
+	Enabled *bool `json:"enabled"`
+	// Interval is the collection period, as integer seconds or a
+	// duration string; 0 falls back to collect_interval.
+	Interval Seconds `json:"interval"`
+	// JitterPercent overrides the global jitter_percent for this
+	// collector. Nil inherits the global setting.
+	JitterPercent *int `json:"jitter_percent"`
+}
+
+// CustomCollectorConfig describes one external collector command.
+type CustomCollectorConfig struct {
+	// Name keys the command's output in the inventory payload.
+	Name string `json:"name"`
+	// Command is run through the shell; its stdout must be JSON.
+	Command string `json:"command"`
+	// Interval between runs, as integer seconds or a duration string;
+	// 0 falls back to collect_interval.
+	Interval Seconds `json:"interval"`
+	// TimeoutSeconds bounds each run. Default 30.
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// PowerControlConfig is the policy for server-initiated power actions.
+type PowerControlConfig struct {
+	// Enabled allows power_action commands at all.
+	Enabled bool `json:"enabled"`
+	// AllowedActions restricts which reset types may run (On,
+	// ForceOff, GracefulShutdown, ForceRestart, PowerCycle). Empty
+	// allows all of them.
+	AllowedActions []string `json:"allowed_actions"`
+}
+
+// ActionAllowed reports whether the policy permits a reset type.
+func (p PowerControlConfig) ActionAllowed(action string) bool {
+	if !p.Enabled {
+		return false
+	}
+	if len(p.AllowedActions) == 0 {
+		return true
+	}
+	for _, a := range p.AllowedActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// FirmwareUpdateConfig is the policy for server-initiated firmware
+// updates.
+type FirmwareUpdateConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BIOSControlConfig is the policy for server-initiated BIOS setting
+// changes.
+type BIOSControlConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BootControlConfig is the policy for server-initiated boot override
+// changes.
+type BootControlConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BMCAccountsConfig is the policy for server-initiated BMC account
+// management.
+type BMCAccountsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BMCCertsConfig is the policy for server-initiated BMC HTTPS
+// certificate management.
+type BMCCertsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SOLConsoleConfig is the policy for Serial-over-LAN terminal
+// sessions.
+type SOLConsoleConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// HealthAlertsConfig controls threshold-based hardware health
+// alerting. Redfish-provided sensor thresholds apply on their own;
+// the config thresholds tighten or supply them where the BMC reports
+// none.
+type HealthAlertsConfig struct {
+	Enabled bool `json:"enabled"`
+	// TemperatureCelsius alerts when any temperature sensor reads at or
+	// above this value. Zero leaves only the BMC's own thresholds.
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+	// FanMinRPM alerts when any fan reads below this value.
+	FanMinRPM int `json:"fan_min_rpm"`
+}
+
+// DiscoveryConfig controls BMC auto-discovery, the bootstrap for
+// fleet/proxy mode: candidates are reported without credentials so
+// the server can assign them.
+type DiscoveryConfig struct {
+	Enabled bool `json:"enabled"`
+	// CIDR is the IPv4 subnet to sweep for Redfish endpoints.
+	CIDR string `json:"cidr"`
+	// SSDP searches by multicast instead of sweeping a subnet; only
+	// BMCs with SSDP enabled answer.
+	SSDP bool `json:"ssdp"`
+	// Interval between discovery rounds, as integer seconds or a
+	// duration string. Default one hour.
+	Interval Seconds `json:"interval"`
+	// TimeoutSeconds bounds each probe. Default 5.
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// TerminalConfig bounds interactive terminal sessions so abandoned
+// shells do not live until agent restart.
+type TerminalConfig struct {
+	// IdleTimeout closes a session after this long without input, as
+	// integer seconds or a duration string. Zero means no idle limit.
+	IdleTimeout Seconds `json:"idle_timeout"`
+	// MaxDuration closes a session this long after it started,
+	// regardless of activity. Zero means no limit.
+	MaxDuration Seconds `json:"max_duration"`
+	// AuditLog, when set, appends one JSON record per session
+	// lifecycle event to this local file, independent of the server.
+	AuditLog string `json:"audit_log"`
+	// AuditSyslog mirrors audit records to the local syslog daemon.
+	AuditSyslog bool `json:"audit_syslog"`
+	// TranscriptDir, when set, stores the full output of each audited
+	// session; without it only a transcript hash is recorded.
+	TranscriptDir string `json:"transcript_dir"`
+	// MaxSessions caps simultaneous sessions. Zero means no limit.
+	MaxSessions int `json:"max_sessions"`
+	// MaxSessionsPerUser caps simultaneous sessions per requesting
+	// user, as passed in the init command data. Zero means no limit.
+	MaxSessionsPerUser int `json:"max_sessions_per_user"`
+	// ScrollbackBytes bounds the recent output kept per session and
+	// replayed on reattach. Default 64 KiB; -1 disables scrollback.
+	ScrollbackBytes int `json:"scrollback_bytes"`
+}
+
+// CommandPolicyConfig constrains what remote operators may run. Deny
+// patterns win over allow patterns.
+type CommandPolicyConfig struct {
+	Enabled bool `json:"enabled"`
+	// Allow, when non-empty, permits only commands matching at least
+	// one of these regular expressions.
+	Allow []string `json:"allow"`
+	// Deny rejects commands matching any of these regular expressions.
+	Deny []string `json:"deny"`
+	// ReadOnly permits only a built-in set of inspection commands and
+	// forbids output redirection.
+	ReadOnly bool `json:"read_only"`
+	// NoSudo rejects privilege escalation (sudo, su, doas).
+	NoSudo bool `json:"no_sudo"`
+	// EnforceInteractive applies the policy to each interactive
+	// terminal input line, not just remote-executed commands.
+	EnforceInteractive bool `json:"enforce_interactive"`
+}
+
+// FileTransferConfig governs file upload and download over the
+// WebSocket connection.
+type FileTransferConfig struct {
+	Enabled bool `json:"enabled"`
+	// AllowedPaths are the absolute directory prefixes within which
+	// files may be read or written. Required when enabled.
+	AllowedPaths []string `json:"allowed_paths"`
+	// MaxSizeBytes caps one transfer in either direction. Default
+	// 64 MiB.
+	MaxSizeBytes int64 `json:"max_size_bytes"`
+	// ChunkSizeBytes sets the download chunk size. Default 64 KiB.
+	ChunkSizeBytes int `json:"chunk_size_bytes"`
+}
+
+// ExecConfig governs non-interactive remote command execution.
+type ExecConfig struct {
+	Enabled bool `json:"enabled"`
+	// TimeoutSeconds caps how long one command may run; per-request
+	// timeouts are clamped to it. Default 300.
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// MetricsConfig controls the realtime metrics stream.
+type MetricsConfig struct {
+	Enabled bool `json:"enabled"`
+	// Interval between samples, as integer seconds or a duration
+	// string. Default 10 seconds.
+	Interval Seconds `json:"interval"`
+}
+
+// CertScanConfig selects TLS certificates for expiry reporting.
+type CertScanConfig struct {
+	// Files are PEM certificate paths.
+	Files []string `json:"files"`
+	// Ports are local TCP ports probed with a TLS handshake.
+	Ports []int `json:"ports"`
+}
+
+// ServicesConfig selects which systemd units are reported.
+type ServicesConfig struct {
+	// Units restricts reporting to matching unit names; glob patterns
+	// like "ssh*" are accepted. Empty reports every service unit.
+	Units []string `json:"units"`
+}
+
+// CollectorEnabled reports whether the named collector should run.
+func (c *Config) CollectorEnabled(name string) bool {
+	cc, ok := c.Collectors[name]
+	if !ok || cc.Enabled == nil {
+		return true
+	}
+	return *cc.Enabled
+}
+
+// CollectorOptedIn reports whether the named collector was explicitly
+// enabled. Used for collectors that default off, like the process
+// snapshot.
+func (c *Config) CollectorOptedIn(name string) bool {
+	cc, ok := c.Collectors[name]
+	return ok && cc.Enabled != nil && *cc.Enabled
+}
+
+// CollectorInterval returns the named collector's period, falling
+// back to the global collect_interval.
+func (c *Config) CollectorInterval(name string) Seconds {
+	if cc, ok := c.Collectors[name]; ok && cc.Interval > 0 {
+		return cc.Interval
+	}
+	return c.CollectInterval
+}
+
+// ProxyEnabled reports whether proxy settings should be read from the
+// environment. Absent config means yes, matching most HTTP tooling.
+func (c *Config) ProxyEnabled() bool {
+	return c.ProxyFromEnv == nil || *c.ProxyFromEnv
+}
+
+// CollectorJitter returns the named collector's jitter percentage,
+// falling back to the global jitter_percent.
+func (c *Config) CollectorJitter(name string) int {
+	if cc, ok := c.Collectors[name]; ok && cc.JitterPercent != nil {
+		return *cc.JitterPercent
+	}
+	return c.JitterPercent
+}
+
+// RateLimitConfig bounds outbound bandwidth so agents on constrained
+// management networks do not saturate links with large payloads.
+type RateLimitConfig struct {
+	BytesPerSec    int `json:"bytes_per_sec"`
+	MessagesPerSec int `json:"messages_per_sec"`
+}
+
+// BMCList holds the configured BMC targets. It decodes from either a
+// single YAML mapping (the original form) or a list of targets, so one
+// agent can proxy collection for a whole rack.
+type BMCList []BMCConfig
+
+// UnmarshalJSON accepts an object or an array of objects.
+func (l *BMCList) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var single BMCConfig
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return err
+		}
+		*l = BMCList{single}
+		return nil
+	}
+	var many []BMCConfig
+	if err := json.Unmarshal(trimmed, &many); err != nil {
+		return err
+	}
+	*l = many
+	return nil
+}
+
+// BMCConfig holds the connection details for a BMC.
+type BMCConfig struct {
+	// Name labels the target in per-target inventory messages.
+	// Defaults to the IP.
+	Name string `json:"name"`
+	IP   string `json:"ip"`
+	// Username and Password may be secret references (file:, env:,
+	// exec:).
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Protocol selects the collection protocol: "redfish" (default) or
+	// "ipmi".
+	Protocol string `json:"protocol"`
+	// PinSHA256 pins the BMC's certificate by SPKI SHA-256 fingerprint.
+	PinSHA256 []string `json:"pin_sha256"`
+	// TLS tunes the connection to this BMC. BMCs ship self-signed
+	// certificates, so insecure_skip_verify defaults to true here;
+	// set a ca_file or pins to verify.
+	TLS *TLSConfig `json:"tls"`
+	// TimeoutSeconds bounds each Redfish request. Default 30.
+	TimeoutSeconds int `json:"timeout_seconds"`
+	// MaxRetries is how often a request is retried after a timeout or
+	// transient 5xx, with exponential backoff. Default 2; -1 disables
+	// retries.
+	MaxRetries int `json:"max_retries"`
+	// BreakerCooldownSeconds is how long requests to this BMC are
+	// short-circuited after repeated failures, so a sick BMC does not
+	// stretch every collection cycle. Default 300.
+	BreakerCooldownSeconds int `json:"breaker_cooldown_seconds"`
+	// CollectBudgetSeconds bounds one whole inventory collection; when
+	// spent, the snapshot goes out with whatever was gathered and the
+	// skipped sections listed under collection_errors. Zero means no
+	// budget.
+	CollectBudgetSeconds int `json:"collect_budget_seconds"`
+	// RecordDir, when set, mirrors every Redfish response from this
+	// target into the directory as replayable fixtures. A development
+	// aid for capturing vendor behaviors; leave unset in production.
+	RecordDir string `json:"record_dir"`
+}
+
+// Default values applied by LoadConfig when fields are unset.
+const (
+	DefaultHeartbeatInterval = 30
+	DefaultReconnectInterval = 5
+	DefaultCollectInterval   = 60
+	DefaultLogLevel          = "info"
+	DefaultMaxMessageSize    = 512 * 1024
+	DefaultDrainTimeout      = 5
+	DefaultPingInterval      = 30
+	DefaultReadDeadline      = 60
+)
+
+// LoadConfig reads the configuration at path (YAML, TOML, or JSON by
+// extension), merges any conf.d drop-in fragments beside it, applies
+// environment overrides and defaults, and validates the result. The
+// AGENT_PROFILE environment variable (or the -profile flag, which sets
+// it) selects a named profile.
+func LoadConfig(path string) (*Config, error) {
+	return LoadConfigProfile(path, os.Getenv("AGENT_PROFILE"))
+}
+
+// LoadConfigProfile is LoadConfig with an explicit profile. The
+// top-level keys form the base; the selected entry under profiles:
+// overlays them, so one file can describe several control planes.
+func LoadConfigProfile(path, profile string) (*Config, error) {
+	tree, err := parseConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeConfDir(tree, filepath.Join(filepath.Dir(path), "conf.d")); err != nil {
+		return nil, err
+	}
+	profiles, _ := tree["profiles"].(map[string]interface{})
+	delete(tree, "profiles")
+	if profile != "" {
+		overlay, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found in %s", profile, path)
+		}
+		deepMerge(tree, overlay)
+	}
+	cfg := &Config{}
+	if err := decodeTree(tree, cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if cfg.Bootstrap.Enabled {
+		if cfg.Bootstrap.CachePath == "" {
+			cfg.Bootstrap.CachePath = DefaultBootstrapCachePath
+		}
+		cached, err := LoadBootstrapCache(cfg.Bootstrap.CachePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(cached) > 0 {
+			// The local file wins over the cached server copy, so a
+			// host-level override survives bootstrap refreshes.
+			deepMerge(cached, tree)
+			cfg = &Config{}
+			if err := decodeTree(cached, cfg); err != nil {
+				return nil, fmt.Errorf("bootstrap cache: %w", err)
+			}
+		}
+	}
+	cfg.applyEnv()
+	cfg.applyDefaults()
+	if err := cfg.ensureClientID(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyVault(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseConfigFile parses one file into a generic tree, selecting the
+// format by extension: .toml and .json are detected, anything else is
+// treated as YAML.
+func parseConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var tree interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		tree, err = tomlx.Parse(data)
+	case ".json":
+		var m map[string]interface{}
+		err = json.Unmarshal(data, &m)
+		tree = m
+	default:
+		tree, err = yamlx.Parse(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	m, ok := tree.(map[string]interface{})
+	if !ok {
+		if tree == nil {
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("parse %s: top level must be a mapping", path)
+	}
+	return m, nil
+}
+
+// mergeConfDir overlays drop-in fragments from dir onto tree in
+// lexical order, so later fragments win. A missing directory is fine.
+func mergeConfDir(tree map[string]interface{}, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".toml", ".json":
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		frag, err := parseConfigFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		deepMerge(tree, frag)
+	}
+	return nil
+}
+
+// decodeTree converts a generic tree into a Config via the json tags.
+func decodeTree(tree map[string]interface{}, cfg *Config) error {
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, cfg)
+}
+
+// applyEnv overrides configuration fields from AGENT_* environment
+// variables, so containerized deployments can avoid editing the file.
+func (c *Config) applyEnv() {
+	if v := os.Getenv("AGENT_SERVER_URL"); v != "" {
+		c.ServerURL = v
+	}
+	if v := os.Getenv("AGENT_CLIENT_ID"); v != "" {
+		c.ClientID = v
+	}
+	if v := os.Getenv("AGENT_CLIENT_TOKEN"); v != "" {
+		c.ClientToken = v
+	}
+	if v := os.Getenv("AGENT_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("AGENT_HEARTBEAT_INTERVAL"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.HeartbeatInterval = Seconds(n)
+		}
+	}
+}
+
+func (c *Config) applyDefaults() {
+	if c.HeartbeatInterval == 0 {
+		c.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if c.ReconnectInterval == 0 {
+		c.ReconnectInterval = DefaultReconnectInterval
+	}
+	if c.CollectInterval == 0 {
+		c.CollectInterval = DefaultCollectInterval
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = DefaultLogLevel
+	}
+	if c.MaxMessageSize == 0 {
+		c.MaxMessageSize = DefaultMaxMessageSize
+	}
+	if c.DrainTimeout == 0 {
+		c.DrainTimeout = DefaultDrainTimeout
+	}
+	if c.Dedup.MaxAgeSeconds == 0 {
+		c.Dedup.MaxAgeSeconds = 3600
+	}
+	if c.Delta.FullSyncSeconds == 0 {
+		c.Delta.FullSyncSeconds = 3600
+	}
+	if c.Metrics.Interval == 0 {
+		c.Metrics.Interval = 10
+	}
+	if c.Discovery.Interval == 0 {
+		c.Discovery.Interval = 3600
+	}
+	if c.Discovery.TimeoutSeconds == 0 {
+		c.Discovery.TimeoutSeconds = 5
+	}
+	if c.Exec.TimeoutSeconds == 0 {
+		c.Exec.TimeoutSeconds = 300
+	}
+	if c.Terminal.ScrollbackBytes == 0 {
+		c.Terminal.ScrollbackBytes = 64 << 10
+	}
+	if c.FileTransfer.MaxSizeBytes == 0 {
+		c.FileTransfer.MaxSizeBytes = 64 << 20
+	}
+	if c.FileTransfer.ChunkSizeBytes == 0 {
+		c.FileTransfer.ChunkSizeBytes = 64 << 10
+	}
+	if c.SendQueue.Size == 0 {
+		c.SendQueue.Size = 256
+	}
+	if c.SendQueue.BlockTimeoutMS == 0 {
+		c.SendQueue.BlockTimeoutMS = 1000
+	}
+	if c.SendQueue.Overflow == nil {
+		c.SendQueue.Overflow = map[string]string{
+			"inventory":       "drop_oldest",
+			"bmc_inventory":   "drop_oldest",
+			"metrics":         "drop_oldest",
+			"terminal_output": "block",
+		}
+	}
+	if len(c.Dedup.Types) == 0 {
+		c.Dedup.Types = []string{"inventory", "bmc_inventory"}
+	}
+	if c.PingInterval == 0 {
+		c.PingInterval = DefaultPingInterval
+	}
+	if c.ReadDeadline == 0 {
+		c.ReadDeadline = DefaultReadDeadline
+	}
+	for i := range c.BMC {
+		if c.BMC[i].Protocol == "" {
+			c.BMC[i].Protocol = "redfish"
+		}
+		if c.BMC[i].Name == "" {
+			c.BMC[i].Name = c.BMC[i].IP
+		}
+		if c.BMC[i].TimeoutSeconds == 0 {
+			c.BMC[i].TimeoutSeconds = 30
+		}
+		if c.BMC[i].MaxRetries == 0 {
+			c.BMC[i].MaxRetries = 2
+		}
+		if c.BMC[i].BreakerCooldownSeconds == 0 {
+			c.BMC[i].BreakerCooldownSeconds = 300
+		}
+	}
+	if c.BMCMaxConcurrent == 0 {
+		c.BMCMaxConcurrent = 4
+	}
+	if c.Sysctls == nil {
+		c.Sysctls = []string{
+			"net.ipv4.ip_forward",
+			"net.ipv4.tcp_syncookies",
+			"vm.swappiness",
+			"vm.overcommit_memory",
+			"kernel.randomize_va_space",
+		}
+	}
+	if c.ClientIDFile == "" {
+		c.ClientIDFile = DefaultClientIDPath
+	}
+	if c.Bootstrap.CachePath == "" {
+		c.Bootstrap.CachePath = DefaultBootstrapCachePath
+	}
+	if c.CredentialsKeyFile == "" {
+		c.CredentialsKeyFile = DefaultCredentialsKeyPath
+	}
+	if c.Vault.Mount == "" {
+		c.Vault.Mount = "secret"
+	}
+	if c.Vault.RenewIntervalSeconds == 0 {
+		c.Vault.RenewIntervalSeconds = 900
+	}
+	for i := range c.CustomCollectors {
+		if c.CustomCollectors[i].TimeoutSeconds == 0 {
+			c.CustomCollectors[i].TimeoutSeconds = 30
+		}
+	}
+}
+
+// Redacted returns a copy of the configuration with secret values
+// masked, suitable for printing or logging.
+func (c *Config) Redacted() *Config {
+	out := *c
+	redact := func(s *string) {
+		if *s != "" {
+			*s = "[redacted]"
+		}
+	}
+	redact(&out.ClientToken)
+	redact(&out.Vault.Token)
+	redact(&out.Vault.SecretID)
+	out.BMC = append(BMCList(nil), c.BMC...)
+	for i := range out.BMC {
+		redact(&out.BMC[i].Password)
+	}
+	return &out
+}
+
+// Validate collects every problem found with the configuration, so an
+// operator fixes one round of errors instead of replaying them one at
+// a time.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch {
+	case c.ServerURL == "":
+		add("server_url is required")
+	default:
+		u, err := url.Parse(c.ServerURL)
+		switch {
+		case err != nil:
+			add("server_url: %v", err)
+		case u.Scheme != "ws" && u.Scheme != "wss":
+			add("server_url must be a ws:// or wss:// URL, got scheme %q", u.Scheme)
+		case u.Host == "":
+			add("server_url has no host")
+		}
+	}
+	if c.ClientID == "" {
+		add("client_id is required")
+	}
+
+	intervals := []struct {
+		name string
+		val  int
+	}{
+		{"heartbeat_interval", int(c.HeartbeatInterval)},
+		{"reconnect_interval", int(c.ReconnectInterval)},
+		{"collect_interval", int(c.CollectInterval)},
+		{"drain_timeout", c.DrainTimeout},
+		{"ping_interval", c.PingInterval},
+		{"read_deadline", c.ReadDeadline},
+	}
+	for _, iv := range intervals {
+		if iv.val <= 0 {
+			add("%s must be positive, got %d", iv.name, iv.val)
+		}
+	}
+	if c.PingInterval > 0 && c.ReadDeadline > 0 && c.PingInterval >= c.ReadDeadline {
+		add("ping_interval (%ds) must be shorter than read_deadline (%ds)",
+			c.PingInterval, c.ReadDeadline)
+	}
+	if c.MaxMessageSize <= 0 {
+		add("max_message_size must be positive, got %d", c.MaxMessageSize)
+	}
+	if c.RateLimit.BytesPerSec < 0 || c.RateLimit.MessagesPerSec < 0 {
+		add("rate_limit values must not be negative")
+	}
+	if c.SendQueue.Size <= 0 {
+		add("send_queue.size must be positive, got %d", c.SendQueue.Size)
+	}
+	for msgType, policy := range c.SendQueue.Overflow {
+		switch policy {
+		case "drop_new", "drop_oldest", "block":
+		default:
+			add("send_queue.overflow.%s: unknown policy %q (want drop_new, drop_oldest, or block)",
+				msgType, policy)
+		}
+	}
+	if c.BMCMaxConcurrent <= 0 {
+		add("bmc_max_concurrent must be positive, got %d", c.BMCMaxConcurrent)
+	}
+	if c.JitterPercent < 0 || c.JitterPercent > 50 {
+		add("jitter_percent must be between 0 and 50, got %d", c.JitterPercent)
+	}
+	for name, cc := range c.Collectors {
+		if cc.Interval < 0 {
+			add("collectors.%s.interval must not be negative", name)
+		}
+		if cc.JitterPercent != nil && (*cc.JitterPercent < 0 || *cc.JitterPercent > 50) {
+			add("collectors.%s.jitter_percent must be between 0 and 50, got %d", name, *cc.JitterPercent)
+		}
+	}
+
+	customNames := make(map[string]bool, len(c.CustomCollectors))
+	for i := range c.CustomCollectors {
+		cc := &c.CustomCollectors[i]
+		if cc.Name == "" {
+			add("custom_collectors[%d]: name is required", i)
+		} else if customNames[cc.Name] {
+			add("custom_collectors: duplicate name %q", cc.Name)
+		} else {
+			customNames[cc.Name] = true
+		}
+		if cc.Command == "" {
+			add("custom_collectors[%d]: command is required", i)
+		}
+		if cc.Interval < 0 {
+			add("custom_collectors[%d]: interval must not be negative", i)
+		}
+		if cc.TimeoutSeconds <= 0 {
+			add("custom_collectors[%d]: timeout_seconds must be positive, got %d", i, cc.TimeoutSeconds)
+		}
+	}
+	for _, a := range c.PowerControl.AllowedActions {
+		switch a {
+		case "On", "ForceOff", "GracefulShutdown", "ForceRestart", "PowerCycle":
+		default:
+			add("power_control.allowed_actions: unknown action %q", a)
+		}
+	}
+	if c.Metrics.Interval <= 0 {
+		add("metrics.interval must be positive, got %d", c.Metrics.Interval)
+	}
+
+	if c.Terminal.IdleTimeout < 0 {
+		add("terminal.idle_timeout must not be negative, got %d", c.Terminal.IdleTimeout)
+	}
+	if c.Terminal.MaxDuration < 0 {
+		add("terminal.max_duration must not be negative, got %d", c.Terminal.MaxDuration)
+	}
+	if c.Terminal.MaxSessions < 0 {
+		add("terminal.max_sessions must not be negative, got %d", c.Terminal.MaxSessions)
+	}
+	if c.Terminal.MaxSessionsPerUser < 0 {
+		add("terminal.max_sessions_per_user must not be negative, got %d", c.Terminal.MaxSessionsPerUser)
+	}
+	if c.Terminal.ScrollbackBytes < -1 {
+		add("terminal.scrollback_bytes must be -1, 0, or positive, got %d", c.Terminal.ScrollbackBytes)
+	}
+	if c.Terminal.TranscriptDir != "" && c.Terminal.AuditLog == "" && !c.Terminal.AuditSyslog {
+		add("terminal.transcript_dir requires terminal.audit_log or terminal.audit_syslog")
+	}
+
+	if c.Exec.TimeoutSeconds < 0 {
+		add("exec.timeout_seconds must not be negative, got %d", c.Exec.TimeoutSeconds)
+	}
+
+	if c.FileTransfer.Enabled {
+		if len(c.FileTransfer.AllowedPaths) == 0 {
+			add("file_transfer: enabled but allowed_paths is empty")
+		}
+		for _, p := range c.FileTransfer.AllowedPaths {
+			if !filepath.IsAbs(p) {
+				add("file_transfer.allowed_paths: %q is not absolute", p)
+			}
+		}
+	}
+	if c.FileTransfer.MaxSizeBytes < 0 {
+		add("file_transfer.max_size_bytes must not be negative, got %d", c.FileTransfer.MaxSizeBytes)
+	}
+	if c.FileTransfer.ChunkSizeBytes < 0 {
+		add("file_transfer.chunk_size_bytes must not be negative, got %d", c.FileTransfer.ChunkSizeBytes)
+	}
+
+	if c.CommandPolicy.Enabled {
+		for _, pat := range c.CommandPolicy.Allow {
+			if _, err := regexp.Compile(pat); err != nil {
+				add("command_policy.allow pattern %q: %v", pat, err)
+			}
+		}
+		for _, pat := range c.CommandPolicy.Deny {
+			if _, err := regexp.Compile(pat); err != nil {
+				add("command_policy.deny pattern %q: %v", pat, err)
+			}
+		}
+	}
+
+	if c.Discovery.Enabled {
+		if c.Discovery.CIDR == "" && !c.Discovery.SSDP {
+			add("discovery: enabled but neither cidr nor ssdp is set")
+		}
+		if c.Discovery.CIDR != "" {
+			if _, ipnet, err := net.ParseCIDR(c.Discovery.CIDR); err != nil {
+				add("discovery: bad cidr %q: %v", c.Discovery.CIDR, err)
+			} else if ones, bits := ipnet.Mask.Size(); bits-ones > 12 {
+				// A sweep larger than a /20 (4096 addresses) is almost
+				// certainly a typo and would hammer the network.
+				add("discovery: cidr %s spans more than 4096 addresses", c.Discovery.CIDR)
+			}
+		}
+	}
+	for _, port := range c.CertScan.Ports {
+		if port < 1 || port > 65535 {
+			add("cert_scan.ports: invalid port %d", port)
+		}
+	}
+
+	if _, err := c.TLS.Build(); err != nil {
+		errs = append(errs, err)
+	}
+	for i := range c.BMC {
+		t := &c.BMC[i]
+		if t.IP != "" {
+			if err := validateHostPort(t.IP); err != nil {
+				add("bmc %s: %v", t.Name, err)
+			}
+		}
+		switch t.Protocol {
+		case "", "redfish", "ipmi":
+		default:
+			add("bmc %s: unknown protocol %q (want redfish or ipmi)", t.Name, t.Protocol)
+		}
+		if t.TLS != nil {
+			if _, err := t.TLS.Build(); err != nil {
+				add("bmc %s: %v", t.Name, err)
+			}
+		}
+		if t.TimeoutSeconds < 0 {
+			add("bmc %s: timeout_seconds must not be negative, got %d", t.Name, t.TimeoutSeconds)
+		}
+		if t.MaxRetries < -1 {
+			add("bmc %s: max_retries must be -1 or higher, got %d", t.Name, t.MaxRetries)
+		}
+		if t.BreakerCooldownSeconds < 0 {
+			add("bmc %s: breaker_cooldown_seconds must not be negative, got %d", t.Name, t.BreakerCooldownSeconds)
+		}
+		if t.CollectBudgetSeconds < 0 {
+			add("bmc %s: collect_budget_seconds must not be negative, got %d", t.Name, t.CollectBudgetSeconds)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &validationError{errs: errs}
+}
+
+// validationError aggregates everything Validate found wrong, rendered
+// as one bullet per problem so the CLI output reads as a checklist.
+type validationError struct {
+	errs []error
+}
+
+func (e *validationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e.errs {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap exposes the individual problems to errors.Is / errors.As.
+func (e *validationError) Unwrap() []error {
+	return e.errs
+}
+
+// validateHostPort checks a host or host:port BMC address.
+func validateHostPort(addr string) error {
+	host := addr
+	if h, port, err := net.SplitHostPort(addr); err == nil {
+		host = h
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid port %q in address %q", port, addr)
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("empty host in address %q", addr)
+	}
+	return nil
+}
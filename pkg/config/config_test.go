@@ -0,0 +1,328 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	path := writeConfig(t, `
+server_url: wss://mgmt.example.com/agent
+client_id: node-01
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.HeartbeatInterval != DefaultHeartbeatInterval {
+		t.Errorf("HeartbeatInterval = %d, want default %d", cfg.HeartbeatInterval, DefaultHeartbeatInterval)
+	}
+	if cfg.LogLevel != DefaultLogLevel {
+		t.Errorf("LogLevel = %q, want %q", cfg.LogLevel, DefaultLogLevel)
+	}
+	if len(cfg.BMC) != 0 {
+		t.Errorf("BMC = %v, want no targets", cfg.BMC)
+	}
+	if cfg.BMCMaxConcurrent != 4 {
+		t.Errorf("BMCMaxConcurrent = %d, want 4", cfg.BMCMaxConcurrent)
+	}
+}
+
+func TestLoadConfigProfiles(t *testing.T) {
+	path := writeConfig(t, `
+server_url: wss://base/agent
+client_id: n1
+log_level: info
+profiles:
+  lab:
+    server_url: wss://lab/agent
+    log_level: debug
+  prod:
+    server_url: wss://prod/agent
+`)
+	base, err := LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfigProfile base: %v", err)
+	}
+	if base.ServerURL != "wss://base/agent" {
+		t.Errorf("base ServerURL = %q", base.ServerURL)
+	}
+
+	lab, err := LoadConfigProfile(path, "lab")
+	if err != nil {
+		t.Fatalf("LoadConfigProfile lab: %v", err)
+	}
+	if lab.ServerURL != "wss://lab/agent" || lab.LogLevel != "debug" {
+		t.Errorf("lab config = %q %q", lab.ServerURL, lab.LogLevel)
+	}
+	// Inherited from the base section.
+	if lab.ClientID != "n1" {
+		t.Errorf("lab ClientID = %q, want inherited n1", lab.ClientID)
+	}
+
+	if _, err := LoadConfigProfile(path, "staging"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
+
+func TestLoadConfigProfileFromEnv(t *testing.T) {
+	t.Setenv("AGENT_PROFILE", "lab")
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://base/agent
+client_id: n1
+profiles:
+  lab:
+    server_url: wss://lab/agent
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ServerURL != "wss://lab/agent" {
+		t.Errorf("ServerURL = %q, want profile from env", cfg.ServerURL)
+	}
+}
+
+func TestLoadConfigTOMLAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	toml := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(toml, []byte(`
+server_url = "wss://x/agent"
+client_id = "n1"
+heartbeat_interval = 15
+
+[[bmc]]
+ip = "10.0.0.1"
+username = "admin"
+password = "pw"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(toml)
+	if err != nil {
+		t.Fatalf("LoadConfig toml: %v", err)
+	}
+	if cfg.HeartbeatInterval != 15 || len(cfg.BMC) != 1 {
+		t.Errorf("toml config = %+v", cfg)
+	}
+
+	jsonPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(jsonPath,
+		[]byte(`{"server_url": "wss://x/agent", "client_id": "n2"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadConfig json: %v", err)
+	}
+	if cfg.ClientID != "n2" {
+		t.Errorf("json ClientID = %q", cfg.ClientID)
+	}
+}
+
+func TestLoadConfigMergesConfD(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(main, []byte(
+		"server_url: wss://x/agent\nclient_id: n1\nlog_level: info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	confd := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Lexical order: 20- overrides 10-.
+	if err := os.WriteFile(filepath.Join(confd, "10-logging.yaml"),
+		[]byte("log_level: warn\ncollect_interval: 120\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(confd, "20-logging.toml"),
+		[]byte("log_level = \"debug\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(main)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want debug from last fragment", cfg.LogLevel)
+	}
+	if cfg.CollectInterval != 120 {
+		t.Errorf("CollectInterval = %d, want 120 from fragment", cfg.CollectInterval)
+	}
+}
+
+func TestRedactedMasksSecrets(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+client_token: topsecret
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: also-secret
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	red := cfg.Redacted()
+	if red.ClientToken != "[redacted]" || red.BMC[0].Password != "[redacted]" {
+		t.Errorf("secrets not masked: %q %q", red.ClientToken, red.BMC[0].Password)
+	}
+	if cfg.ClientToken != "topsecret" || cfg.BMC[0].Password != "also-secret" {
+		t.Error("Redacted mutated the original config")
+	}
+	if red.BMC[0].Username != "admin" {
+		t.Errorf("non-secret field changed: %q", red.BMC[0].Username)
+	}
+}
+
+func TestCollectorOverrides(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+collect_interval: 60
+collectors:
+  bmc:
+    interval: 3600
+  disk:
+    enabled: false
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := cfg.CollectorInterval("bmc"); got != 3600 {
+		t.Errorf("CollectorInterval(bmc) = %d, want 3600", got)
+	}
+	if got := cfg.CollectorInterval("local"); got != 60 {
+		t.Errorf("CollectorInterval(local) = %d, want default 60", got)
+	}
+	if cfg.CollectorEnabled("disk") {
+		t.Error("disk collector not disabled")
+	}
+	if !cfg.CollectorEnabled("cpu") {
+		t.Error("unconfigured collector not enabled by default")
+	}
+}
+
+func TestBMCSingleMappingAndList(t *testing.T) {
+	single, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: pw
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig single: %v", err)
+	}
+	if len(single.BMC) != 1 || single.BMC[0].IP != "10.0.0.1" {
+		t.Fatalf("single mapping BMC = %v", single.BMC)
+	}
+	if single.BMC[0].Protocol != "redfish" || single.BMC[0].Name != "10.0.0.1" {
+		t.Errorf("target defaults not applied: %+v", single.BMC[0])
+	}
+
+	many, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+bmc:
+  - name: rack1-u1
+    ip: 10.0.0.1
+    username: admin
+    password: pw
+  - ip: 10.0.0.2
+    username: admin
+    password: pw
+    protocol: ipmi
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig list: %v", err)
+	}
+	if len(many.BMC) != 2 {
+		t.Fatalf("list BMC = %v", many.BMC)
+	}
+	if many.BMC[0].Name != "rack1-u1" || many.BMC[1].Name != "10.0.0.2" {
+		t.Errorf("target names = %q, %q", many.BMC[0].Name, many.BMC[1].Name)
+	}
+	if many.BMC[1].Protocol != "ipmi" {
+		t.Errorf("explicit protocol overridden: %q", many.BMC[1].Protocol)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{"missing server_url", "client_id: node-01\n"},
+		{"bad scheme", "server_url: https://x\nclient_id: node-01\n"},
+		{"ping not shorter than deadline",
+			"server_url: wss://x/agent\nclient_id: n\nping_interval: 60\nread_deadline: 60\n"},
+		{"negative interval",
+			"server_url: wss://x/agent\nclient_id: n\nheartbeat_interval: -5\n"},
+		{"bad overflow policy",
+			"server_url: wss://x/agent\nclient_id: n\nsend_queue:\n  overflow:\n    heartbeat: drop_random\n"},
+		{"bad bmc port",
+			"server_url: wss://x/agent\nclient_id: n\nbmc:\n  ip: \"10.0.0.5:99999\"\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadConfig(writeConfig(t, tc.content)); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestValidateCollectsAllProblems(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, `
+server_url: https://x
+client_id: n
+heartbeat_interval: -5
+bmc:
+  ip: "10.0.0.5:99999"
+  protocol: snmp
+`))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		"ws:// or wss://",
+		"heartbeat_interval must be positive",
+		"invalid port",
+		"unknown protocol",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%s", want, err)
+		}
+	}
+	if strings.Count(err.Error(), "\n  - ") < 4 {
+		t.Errorf("error not rendered as a bullet list:\n%s", err)
+	}
+}
+
+func TestLoadConfigEnvOverride(t *testing.T) {
+	t.Setenv("AGENT_CLIENT_ID", "from-env")
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://mgmt.example.com/agent
+client_id: node-01
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ClientID != "from-env" {
+		t.Errorf("ClientID = %q, want env override", cfg.ClientID)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Encrypted-at-rest credentials: sensitive fields may hold
+// "enc:<base64>" values produced by `agent encrypt`, sealed with
+// AES-256-GCM under a machine-local key file. The loader decrypts
+// transparently, so the YAML can be checked into provisioning systems
+// without exposing passwords.
+
+// DefaultCredentialsKeyPath is where the machine-local key lives when
+// credentials_key_file is not set.
+const DefaultCredentialsKeyPath = "/etc/agent/credentials.key"
+
+const encPrefix = "enc:"
+
+// LoadOrCreateKey reads the base64 key at path, generating and writing
+// a fresh 32-byte key when the file does not exist.
+func LoadOrCreateKey(path string) ([]byte, error) {
+	key, err := loadKey(path)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0o600); err != nil {
+		return nil, fmt.Errorf("write key: %w", err)
+	}
+	return key, nil
+}
+
+// loadKey reads and decodes the base64 key file at path.
+func loadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode key %s: %w", path, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key %s: want 32 bytes, got %d", path, len(key))
+	}
+	return key, nil
+}
+
+// EncryptValue seals plaintext under key, returning the enc: form to
+// paste into the configuration file.
+func EncryptValue(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptValue reverses EncryptValue.
+func decryptValue(key []byte, value string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted value too short")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
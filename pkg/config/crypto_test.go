@@ -0,0 +1,80 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "creds.key")
+	key, err := LoadOrCreateKey(keyPath)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+	enc, err := EncryptValue(key, "hunter2")
+	if err != nil {
+		t.Fatalf("EncryptValue: %v", err)
+	}
+	if !strings.HasPrefix(enc, "enc:") {
+		t.Fatalf("encrypted value %q missing enc: prefix", enc)
+	}
+	plain, err := decryptValue(key, enc)
+	if err != nil || plain != "hunter2" {
+		t.Errorf("decryptValue = %q, %v", plain, err)
+	}
+
+	// The same key file loads back identically.
+	key2, err := LoadOrCreateKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != string(key2) {
+		t.Error("key file not stable across loads")
+	}
+}
+
+func TestDecryptWithWrongKeyFails(t *testing.T) {
+	dir := t.TempDir()
+	k1, _ := LoadOrCreateKey(filepath.Join(dir, "k1"))
+	k2, _ := LoadOrCreateKey(filepath.Join(dir, "k2"))
+	enc, _ := EncryptValue(k1, "secret")
+	if _, err := decryptValue(k2, enc); err == nil {
+		t.Error("decryption with wrong key succeeded")
+	}
+}
+
+func TestLoadConfigDecryptsEncValues(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "creds.key")
+	key, _ := LoadOrCreateKey(keyPath)
+	enc, _ := EncryptValue(key, "bmc-pw")
+	path := writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+credentials_key_file: `+keyPath+`
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: "`+enc+`"
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.BMC[0].Password != "bmc-pw" {
+		t.Errorf("BMC.Password = %q, want decrypted bmc-pw", cfg.BMC[0].Password)
+	}
+}
+
+func TestLoadConfigMissingKeyFileFails(t *testing.T) {
+	path := writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+credentials_key_file: `+filepath.Join(t.TempDir(), "nope.key")+`
+client_token: enc:AAAA
+`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("enc: value without key file did not fail")
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Seconds is an interval stored as whole seconds. It decodes from a
+// plain integer (the historical form) or a duration string such as
+// "30s", "5m", or "1h", so configs can say what they mean instead of
+// counting seconds.
+type Seconds int
+
+// Duration converts the interval to a time.Duration.
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// UnmarshalJSON accepts a number of seconds or a duration string.
+func (s *Seconds) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch v := raw.(type) {
+	case float64:
+		*s = Seconds(v)
+		return nil
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			*s = Seconds(n)
+			return nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q: %w", v, err)
+		}
+		if d%time.Second != 0 {
+			return fmt.Errorf("invalid interval %q: must be whole seconds", v)
+		}
+		*s = Seconds(d / time.Second)
+		return nil
+	default:
+		return fmt.Errorf("invalid interval %v", raw)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalsAcceptDurationStrings(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+heartbeat_interval: 30s
+reconnect_interval: "5"
+collect_interval: 5m
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.HeartbeatInterval != 30 {
+		t.Errorf("HeartbeatInterval = %d, want 30", cfg.HeartbeatInterval)
+	}
+	if cfg.ReconnectInterval != 5 {
+		t.Errorf("ReconnectInterval = %d, want 5", cfg.ReconnectInterval)
+	}
+	if cfg.CollectInterval != 300 {
+		t.Errorf("CollectInterval = %d, want 300", cfg.CollectInterval)
+	}
+	if cfg.CollectInterval.Duration() != 5*time.Minute {
+		t.Errorf("Duration() = %v", cfg.CollectInterval.Duration())
+	}
+}
+
+func TestIntervalsAcceptIntegers(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+heartbeat_interval: 45
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.HeartbeatInterval != 45 {
+		t.Errorf("HeartbeatInterval = %d, want 45", cfg.HeartbeatInterval)
+	}
+}
+
+func TestIntervalRejectsBadStrings(t *testing.T) {
+	for _, bad := range []string{"fast", "30x", "1.5s"} {
+		_, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+heartbeat_interval: "`+bad+`"
+`))
+		if err == nil {
+			t.Errorf("interval %q accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Flag binding generates a command-line flag for every scalar
+// configuration field from the json struct tags (server_url becomes
+// -server-url, rate_limit.bytes_per_sec becomes
+// -rate-limit-bytes-per-sec), so containerized deployments can
+// configure the agent entirely from the command line. Flags win over
+// file and environment values.
+
+// FlagBinding tracks the registered flags so explicitly set ones can
+// be applied over a loaded configuration.
+type FlagBinding struct {
+	fs      *flag.FlagSet
+	entries map[string]flagEntry
+}
+
+type flagEntry struct {
+	path []int       // field index path into Config
+	bmc  bool        // path is into BMC[0] instead
+	val  interface{} // *string, *int, or *bool owned by the FlagSet
+}
+
+// BindFlags registers override flags on fs and returns the binding to
+// apply after the configuration file is loaded.
+func BindFlags(fs *flag.FlagSet) *FlagBinding {
+	b := &FlagBinding{fs: fs, entries: map[string]flagEntry{}}
+	b.bindStruct(reflect.TypeOf(Config{}), "", nil, false)
+	// The common single-BMC case gets flat flags targeting the first
+	// (or only) BMC entry.
+	b.bindStruct(reflect.TypeOf(BMCConfig{}), "bmc", nil, true)
+	return b
+}
+
+func (b *FlagBinding) bindStruct(t reflect.Type, prefix string, path []int, bmc bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		flagName := strings.ReplaceAll(name, "_", "-")
+		if prefix != "" {
+			flagName = prefix + "-" + flagName
+		}
+		fieldPath := append(append([]int(nil), path...), i)
+		usage := "override " + strings.ReplaceAll(flagName, "-", " ")
+		switch field.Type.Kind() {
+		case reflect.String:
+			b.entries[flagName] = flagEntry{fieldPath, bmc, b.fs.String(flagName, "", usage)}
+		case reflect.Int:
+			b.entries[flagName] = flagEntry{fieldPath, bmc, b.fs.Int(flagName, 0, usage)}
+		case reflect.Bool:
+			b.entries[flagName] = flagEntry{fieldPath, bmc, b.fs.Bool(flagName, false, usage)}
+		case reflect.Struct:
+			b.bindStruct(field.Type, flagName, fieldPath, bmc)
+		}
+	}
+}
+
+// Apply copies every flag the user explicitly set into cfg and
+// re-validates the result.
+func (b *FlagBinding) Apply(cfg *Config) error {
+	applied := false
+	b.fs.Visit(func(f *flag.Flag) {
+		entry, ok := b.entries[f.Name]
+		if !ok {
+			return
+		}
+		target := reflect.ValueOf(cfg).Elem()
+		if entry.bmc {
+			if len(cfg.BMC) == 0 {
+				cfg.BMC = BMCList{{}}
+			}
+			target = reflect.ValueOf(&cfg.BMC[0]).Elem()
+		}
+		field := target.FieldByIndex(entry.path)
+		switch v := entry.val.(type) {
+		case *string:
+			field.SetString(*v)
+		case *int:
+			field.SetInt(int64(*v))
+		case *bool:
+			field.SetBool(*v)
+		}
+		applied = true
+	})
+	if !applied {
+		return nil
+	}
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("flag overrides: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestFlagOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	b := BindFlags(fs)
+	if err := fs.Parse([]string{
+		"-server-url", "wss://flag/agent",
+		"-heartbeat-interval", "7",
+		"-insecure-skip-verify",
+		"-rate-limit-bytes-per-sec", "2048",
+		"-bmc-ip", "10.0.0.9",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	if err := b.Apply(cfg); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if cfg.ServerURL != "wss://flag/agent" {
+		t.Errorf("ServerURL = %q", cfg.ServerURL)
+	}
+	if cfg.HeartbeatInterval != 7 {
+		t.Errorf("HeartbeatInterval = %d", cfg.HeartbeatInterval)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify not set")
+	}
+	if cfg.RateLimit.BytesPerSec != 2048 {
+		t.Errorf("RateLimit.BytesPerSec = %d", cfg.RateLimit.BytesPerSec)
+	}
+	if len(cfg.BMC) != 1 || cfg.BMC[0].IP != "10.0.0.9" {
+		t.Errorf("BMC = %+v", cfg.BMC)
+	}
+}
+
+func TestFlagOverridesLeaveUnsetFieldsAlone(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	b := BindFlags(fs)
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.HeartbeatInterval = 42
+	if err := b.Apply(cfg); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q", cfg.LogLevel)
+	}
+	if cfg.HeartbeatInterval != 42 {
+		t.Errorf("unset flag changed HeartbeatInterval to %d", cfg.HeartbeatInterval)
+	}
+}
+
+func TestFlagOverridesRevalidate(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	b := BindFlags(fs)
+	if err := fs.Parse([]string{"-server-url", "http://nope"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Apply(testConfig()); err == nil {
+		t.Error("invalid flag override not rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultClientIDPath is where the auto-generated client ID is
+// persisted when client_id_file is not set.
+const DefaultClientIDPath = "/var/lib/agent/client-id"
+
+// machineIDSources are tried in order when deriving a stable
+// identifier for this host.
+var machineIDSources = []string{
+	"/etc/machine-id",
+	"/var/lib/dbus/machine-id",
+	"/sys/class/dmi/id/product_uuid",
+}
+
+// ensureClientID fills in an empty client_id with a stable generated
+// identifier: a previously persisted one if present, otherwise one
+// derived from the machine ID (or DMI UUID) and persisted so the host
+// keeps its identity across reinstalls.
+func (c *Config) ensureClientID() error {
+	if c.ClientID != "" {
+		return nil
+	}
+	path := c.ClientIDFile
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			c.ClientID = id
+			return nil
+		}
+	}
+	id, err := deriveClientID()
+	if err != nil {
+		return fmt.Errorf("generate client_id: %w", err)
+	}
+	c.ClientID = id
+	// Persistence is best-effort: a read-only filesystem should not
+	// stop the agent, the ID is still stable as long as the machine ID
+	// survives.
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+		err = os.WriteFile(path, []byte(id+"\n"), 0o644)
+		if err == nil {
+			return nil
+		}
+	}
+	slog.Warn("could not persist generated client_id", "path", path)
+	return nil
+}
+
+// deriveClientID hashes the machine ID so the raw value is not exposed
+// to the server, falling back to a random identifier when no source is
+// readable.
+func deriveClientID() (string, error) {
+	for _, src := range machineIDSources {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSpace(string(data))
+		if id == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte("agent-id:" + id))
+		return "agent-" + hex.EncodeToString(sum[:8]), nil
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "agent-" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsureClientIDGeneratesAndPersists(t *testing.T) {
+	idFile := filepath.Join(t.TempDir(), "client-id")
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id_file: `+idFile+`
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if !strings.HasPrefix(cfg.ClientID, "agent-") {
+		t.Fatalf("ClientID = %q, want generated agent-*", cfg.ClientID)
+	}
+	persisted, err := os.ReadFile(idFile)
+	if err != nil {
+		t.Fatalf("client ID not persisted: %v", err)
+	}
+	if strings.TrimSpace(string(persisted)) != cfg.ClientID {
+		t.Errorf("persisted %q != %q", persisted, cfg.ClientID)
+	}
+
+	// A second load reuses the persisted ID.
+	cfg2, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id_file: `+idFile+`
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg2.ClientID != cfg.ClientID {
+		t.Errorf("second load ClientID = %q, want stable %q", cfg2.ClientID, cfg.ClientID)
+	}
+}
+
+func TestEnsureClientIDPrefersPersistedFile(t *testing.T) {
+	idFile := filepath.Join(t.TempDir(), "client-id")
+	if err := os.WriteFile(idFile, []byte("node-kept\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id_file: `+idFile+`
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientID != "node-kept" {
+		t.Errorf("ClientID = %q, want persisted node-kept", cfg.ClientID)
+	}
+}
+
+func TestExplicitClientIDWins(t *testing.T) {
+	idFile := filepath.Join(t.TempDir(), "client-id")
+	cfg, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: explicit-01
+client_id_file: `+idFile+`
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientID != "explicit-01" {
+		t.Errorf("ClientID = %q", cfg.ClientID)
+	}
+	if _, err := os.Stat(idFile); !os.IsNotExist(err) {
+		t.Error("ID file written despite explicit client_id")
+	}
+}
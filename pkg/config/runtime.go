@@ -0,0 +1,173 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ChangeListener is notified with the new configuration after a runtime
+// update has been applied.
+type ChangeListener func(*Config)
+
+// Manager holds the live configuration and applies server-pushed
+// updates at runtime. Updates are validated against a merged copy
+// before being swapped in, so an invalid push never replaces a working
+// configuration.
+type Manager struct {
+	mu        sync.RWMutex
+	cfg       *Config
+	listeners []ChangeListener
+	// overridesPath is where persisted runtime overrides accumulate;
+	// empty disables persistence.
+	overridesPath string
+	overrides     map[string]interface{}
+	// path is the configuration file Watch/Reload re-read; empty until
+	// Watch is called.
+	path string
+}
+
+// NewManager wraps a loaded configuration. overridesPath, when
+// non-empty, is read for previously persisted overrides and written
+// when a pushed update requests persistence.
+func NewManager(cfg *Config, overridesPath string) (*Manager, error) {
+	m := &Manager{
+		cfg:           cfg,
+		overridesPath: overridesPath,
+		overrides:     map[string]interface{}{},
+	}
+	if overridesPath != "" {
+		data, err := os.ReadFile(overridesPath)
+		if err == nil {
+			var saved map[string]interface{}
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return nil, fmt.Errorf("parse overrides %s: %w", overridesPath, err)
+			}
+			if _, err := m.Apply(saved, false); err != nil {
+				return nil, fmt.Errorf("apply persisted overrides: %w", err)
+			}
+			m.overrides = saved
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Current returns the live configuration. Callers must treat it as
+// read-only.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg
+}
+
+// Path returns the configuration file being watched; empty until Watch
+// is called.
+func (m *Manager) Path() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.path
+}
+
+// Subscribe registers a listener invoked after each applied update.
+func (m *Manager) Subscribe(l ChangeListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, l)
+}
+
+// Apply merges updates into a copy of the current configuration,
+// validates it, and swaps it in, returning the hash of the applied
+// configuration. The current configuration is kept on any error.
+func (m *Manager) Apply(updates map[string]interface{}, persist bool) (string, error) {
+	m.mu.Lock()
+	merged, err := mergeConfig(m.cfg, updates)
+	if err != nil {
+		m.mu.Unlock()
+		return "", err
+	}
+	merged.applyDefaults()
+	if err := merged.Validate(); err != nil {
+		m.mu.Unlock()
+		return "", fmt.Errorf("rejected update: %w", err)
+	}
+	m.cfg = merged
+	listeners := append([]ChangeListener(nil), m.listeners...)
+	if persist {
+		for k, v := range updates {
+			m.overrides[k] = v
+		}
+		if m.overridesPath != "" {
+			if err := writeJSONFile(m.overridesPath, m.overrides); err != nil {
+				m.mu.Unlock()
+				return "", fmt.Errorf("persist overrides: %w", err)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, l := range listeners {
+		l(merged)
+	}
+	return merged.Hash(), nil
+}
+
+// Hash returns a stable digest of the configuration, used to ack
+// applied updates.
+func (c *Config) Hash() string {
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// mergeConfig overlays updates onto a JSON round-trip copy of cfg.
+func mergeConfig(cfg *Config, updates map[string]interface{}) (*Config, error) {
+	base, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(base, &tree); err != nil {
+		return nil, err
+	}
+	deepMerge(tree, updates)
+	mergedRaw, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	merged := &Config{}
+	if err := json.Unmarshal(mergedRaw, merged); err != nil {
+		return nil, fmt.Errorf("invalid update: %w", err)
+	}
+	return merged, nil
+}
+
+// deepMerge overlays src onto dst, recursing into nested maps.
+func deepMerge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sv, ok := v.(map[string]interface{}); ok {
+			if dv, ok := dst[k].(map[string]interface{}); ok {
+				deepMerge(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// writeJSONFile writes v as JSON with an atomic rename.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testConfig() *Config {
+	c := &Config{ServerURL: "wss://x/agent", ClientID: "n1"}
+	c.applyDefaults()
+	return c
+}
+
+func TestManagerApplyUpdatesAndNotifies(t *testing.T) {
+	mgr, err := NewManager(testConfig(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notified *Config
+	mgr.Subscribe(func(c *Config) { notified = c })
+
+	hash, err := mgr.Apply(map[string]interface{}{
+		"log_level":        "debug",
+		"collect_interval": 120,
+	}, false)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if hash == "" {
+		t.Error("Apply returned empty hash")
+	}
+	cur := mgr.Current()
+	if cur.LogLevel != "debug" || cur.CollectInterval != 120 {
+		t.Errorf("Current = %+v", cur)
+	}
+	if notified == nil || notified.LogLevel != "debug" {
+		t.Error("listener not notified with new config")
+	}
+}
+
+func TestManagerRejectsInvalidUpdate(t *testing.T) {
+	mgr, _ := NewManager(testConfig(), "")
+	before := mgr.Current().Hash()
+	if _, err := mgr.Apply(map[string]interface{}{"server_url": "http://nope"}, false); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if mgr.Current().Hash() != before {
+		t.Error("invalid update replaced the live config")
+	}
+}
+
+func TestManagerPersistsAndReloadsOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	mgr, _ := NewManager(testConfig(), path)
+	if _, err := mgr.Apply(map[string]interface{}{"log_level": "warn"}, true); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("overrides not written: %v", err)
+	}
+
+	mgr2, err := NewManager(testConfig(), path)
+	if err != nil {
+		t.Fatalf("NewManager with overrides: %v", err)
+	}
+	if mgr2.Current().LogLevel != "warn" {
+		t.Errorf("persisted override not applied, LogLevel = %q", mgr2.Current().LogLevel)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SampleConfig returns a configuration populated with defaults and
+// placeholder connection details, used by `agent config init` so the
+// generated sample always reflects the current struct, not a stale
+// static file.
+func SampleConfig() *Config {
+	c := &Config{
+		ServerURL:   "wss://mgmt.example.com/agent",
+		ClientID:    "node-01",
+		ClientToken: "changeme",
+		Collectors: map[string]CollectorConfig{
+			"bmc": {Interval: 3600},
+		},
+		BMC: BMCList{{
+			Name:     "local",
+			Username: "admin",
+			Password: "changeme",
+		}},
+	}
+	c.applyDefaults()
+	return c
+}
+
+// MarshalYAML renders cfg as YAML, with keys taken from the json
+// struct tags in declaration order. Only the subset of YAML the
+// in-tree parser reads back is emitted.
+func MarshalYAML(cfg *Config) []byte {
+	var b strings.Builder
+	b.WriteString("# Agent configuration generated by `agent config init`.\n")
+	writeYAML(&b, reflect.ValueOf(cfg).Elem(), 0)
+	return []byte(b.String())
+}
+
+func writeYAML(b *strings.Builder, v reflect.Value, indent int) {
+	pad := strings.Repeat("  ", indent)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Struct:
+			fmt.Fprintf(b, "%s%s:\n", pad, name)
+			writeYAML(b, fv, indent+1)
+		case reflect.Map:
+			// The in-tree parser has no inline {} form; omit empty maps.
+			if fv.Len() == 0 {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:\n", pad, name)
+			writeYAMLMap(b, fv, indent+1)
+		case reflect.Slice:
+			writeYAMLSlice(b, name, fv, indent)
+		case reflect.Ptr:
+			if fv.IsNil() {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", pad, name, scalarYAML(fv.Elem()))
+		default:
+			fmt.Fprintf(b, "%s%s: %s\n", pad, name, scalarYAML(fv))
+		}
+	}
+}
+
+func writeYAMLMap(b *strings.Builder, v reflect.Value, indent int) {
+	pad := strings.Repeat("  ", indent)
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mv := v.MapIndex(reflect.ValueOf(k))
+		if mv.Kind() == reflect.Struct {
+			fmt.Fprintf(b, "%s%s:\n", pad, k)
+			writeYAML(b, mv, indent+1)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", pad, k, scalarYAML(mv))
+	}
+}
+
+func writeYAMLSlice(b *strings.Builder, name string, v reflect.Value, indent int) {
+	pad := strings.Repeat("  ", indent)
+	if v.Len() == 0 {
+		fmt.Fprintf(b, "%s%s: []\n", pad, name)
+		return
+	}
+	fmt.Fprintf(b, "%s%s:\n", pad, name)
+	for i := 0; i < v.Len(); i++ {
+		ev := v.Index(i)
+		if ev.Kind() == reflect.Struct {
+			// First field on the dash line, the rest indented under it.
+			var inner strings.Builder
+			writeYAML(&inner, ev, indent+2)
+			lines := strings.Split(strings.TrimRight(inner.String(), "\n"), "\n")
+			for j, line := range lines {
+				if j == 0 {
+					fmt.Fprintf(b, "%s  - %s\n", pad, strings.TrimLeft(line, " "))
+				} else {
+					fmt.Fprintf(b, "%s\n", line)
+				}
+			}
+			continue
+		}
+		fmt.Fprintf(b, "%s  - %s\n", pad, scalarYAML(ev))
+	}
+}
+
+// scalarYAML formats a scalar value, quoting strings that could be
+// misread by the parser.
+func scalarYAML(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if s == "" || strings.ContainsAny(s, ":#{}[]") {
+			return fmt.Sprintf("%q", s)
+		}
+		return s
+	case reflect.Bool:
+		return fmt.Sprintf("%t", v.Bool())
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSampleRoundTrips ensures the generated sample is parseable by
+// LoadConfig, so `agent config init` output always works as-is.
+func TestSampleRoundTrips(t *testing.T) {
+	out := MarshalYAML(SampleConfig())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("generated sample does not load: %v\n%s", err, out)
+	}
+	if cfg.ServerURL != "wss://mgmt.example.com/agent" {
+		t.Errorf("ServerURL = %q", cfg.ServerURL)
+	}
+	if cfg.HeartbeatInterval != DefaultHeartbeatInterval {
+		t.Errorf("HeartbeatInterval = %d", cfg.HeartbeatInterval)
+	}
+	if len(cfg.BMC) != 1 || cfg.BMC[0].Username != "admin" {
+		t.Errorf("BMC = %+v", cfg.BMC)
+	}
+}
+
+// TestSampleCoversNewFields guards against the sample silently missing
+// sections as the Config struct grows.
+func TestSampleCoversNewFields(t *testing.T) {
+	out := string(MarshalYAML(SampleConfig()))
+	for _, key := range []string{
+		"server_url:", "send_queue:", "dedup:", "rate_limit:",
+		"vault:", "bootstrap:", "bmc:", "collectors:",
+	} {
+		if !strings.Contains(out, key) {
+			t.Errorf("sample missing %s\n%s", key, out)
+		}
+	}
+}
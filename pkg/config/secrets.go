@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Secret-bearing fields may use an indirect reference instead of a
+// plaintext value:
+//
+//	file:/run/secrets/token   read from a file
+//	env:AGENT_TOKEN           read from an environment variable
+//	exec:/usr/local/bin/tok   stdout of a command
+//
+// References are resolved by LoadConfig, so they are re-read on every
+// reload and the YAML on disk never holds the credential itself.
+
+// resolveSecrets dereferences secret references in place. Values with
+// the enc: prefix are decrypted with the machine-local key.
+func (c *Config) resolveSecrets() error {
+	var key []byte
+	type secretField struct {
+		name string
+		val  *string
+	}
+	fields := []secretField{{"client_token", &c.ClientToken}}
+	for i := range c.BMC {
+		fields = append(fields,
+			secretField{fmt.Sprintf("bmc[%d].username", i), &c.BMC[i].Username},
+			secretField{fmt.Sprintf("bmc[%d].password", i), &c.BMC[i].Password})
+	}
+	for _, f := range fields {
+		if strings.HasPrefix(*f.val, encPrefix) {
+			if key == nil {
+				var err error
+				key, err = loadKey(c.CredentialsKeyFile)
+				if err != nil {
+					return fmt.Errorf("%s: %w", f.name, err)
+				}
+			}
+			plain, err := decryptValue(key, *f.val)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+			*f.val = plain
+			continue
+		}
+		resolved, err := resolveSecret(*f.val)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.val = resolved
+	}
+	return nil
+}
+
+// RotateBMCPasswordRef persists a rotated BMC password for the named
+// target. Rotation is only persisted when the configured password is a
+// file: reference, which is rewritten atomically; with any other
+// storage (literal, env:, exec:, enc:) it returns false and the
+// credential store must be updated out of band. The reference is read
+// from the raw configuration file so the resolved plaintext never has
+// to be written back into it.
+func RotateBMCPasswordRef(configPath, targetName, newPassword string) (bool, error) {
+	if configPath == "" {
+		return false, fmt.Errorf("no configuration file to update")
+	}
+	tree, err := parseConfigFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	// The bmc key accepts a single mapping or a list of them.
+	var list []interface{}
+	switch v := tree["bmc"].(type) {
+	case []interface{}:
+		list = v
+	case map[string]interface{}:
+		list = []interface{}{v}
+	}
+	for _, e := range list {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := entry["name"].(string)
+		ip, _ := entry["ip"].(string)
+		// An unnamed target defaults its name to the IP.
+		if name != targetName && (name != "" || ip != targetName) {
+			continue
+		}
+		ref, _ := entry["password"].(string)
+		if !strings.HasPrefix(ref, "file:") {
+			return false, nil
+		}
+		secretPath := strings.TrimPrefix(ref, "file:")
+		tmp := secretPath + ".tmp"
+		if err := os.WriteFile(tmp, []byte(newPassword+"\n"), 0o600); err != nil {
+			return false, err
+		}
+		if err := os.Rename(tmp, secretPath); err != nil {
+			os.Remove(tmp)
+			return false, err
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("no bmc target %q in %s", targetName, configPath)
+}
+
+// resolveSecret dereferences a single value. Values without a known
+// prefix are returned unchanged.
+func resolveSecret(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(v, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("read secret: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case strings.HasPrefix(v, "env:"):
+		name := strings.TrimPrefix(v, "env:")
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		return strings.TrimSpace(val), nil
+	case strings.HasPrefix(v, "exec:"):
+		out, err := exec.Command("/bin/sh", "-c", strings.TrimPrefix(v, "exec:")).Output()
+		if err != nil {
+			return "", fmt.Errorf("exec secret: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := resolveSecret("file:" + path)
+	if err != nil {
+		t.Fatalf("resolveSecret: %v", err)
+	}
+	if got != "s3cret" {
+		t.Errorf("resolved %q, want s3cret", got)
+	}
+}
+
+func TestResolveSecretEnv(t *testing.T) {
+	t.Setenv("TEST_AGENT_SECRET", "from-env")
+	got, err := resolveSecret("env:TEST_AGENT_SECRET")
+	if err != nil {
+		t.Fatalf("resolveSecret: %v", err)
+	}
+	if got != "from-env" {
+		t.Errorf("resolved %q, want from-env", got)
+	}
+	if _, err := resolveSecret("env:TEST_AGENT_SECRET_UNSET"); err == nil {
+		t.Error("unset env reference did not fail")
+	}
+}
+
+func TestResolveSecretExec(t *testing.T) {
+	got, err := resolveSecret("exec:echo from-exec")
+	if err != nil {
+		t.Fatalf("resolveSecret: %v", err)
+	}
+	if got != "from-exec" {
+		t.Errorf("resolved %q, want from-exec", got)
+	}
+}
+
+func TestResolveSecretPlainPassthrough(t *testing.T) {
+	got, err := resolveSecret("plain-token")
+	if err != nil || got != "plain-token" {
+		t.Errorf("resolveSecret(plain) = %q, %v", got, err)
+	}
+}
+
+func TestLoadConfigResolvesSecretReferences(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "bmc-pass")
+	if err := os.WriteFile(secret, []byte("bmc-pw"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_AGENT_TOKEN", "tok-from-env")
+	path := writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+client_token: env:TEST_AGENT_TOKEN
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: file:`+secret+`
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ClientToken != "tok-from-env" {
+		t.Errorf("ClientToken = %q", cfg.ClientToken)
+	}
+	if cfg.BMC[0].Password != "bmc-pw" {
+		t.Errorf("BMC.Password = %q", cfg.BMC[0].Password)
+	}
+}
+
+func TestRotateBMCPasswordRef(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "bmc-pass")
+	if err := os.WriteFile(secret, []byte("old-pw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+client_token: tok
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: file:`+secret+`
+`)
+	persisted, err := RotateBMCPasswordRef(path, "10.0.0.1", "new-pw")
+	if err != nil {
+		t.Fatalf("RotateBMCPasswordRef: %v", err)
+	}
+	if !persisted {
+		t.Fatal("file: reference was not persisted")
+	}
+	data, err := os.ReadFile(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new-pw\n" {
+		t.Errorf("secret file = %q, want new-pw", data)
+	}
+	if _, err := RotateBMCPasswordRef(path, "absent", "x"); err == nil {
+		t.Error("unknown target did not fail")
+	}
+}
+
+func TestRotateBMCPasswordRefNonFileRef(t *testing.T) {
+	path := writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+client_token: tok
+bmc:
+  ip: 10.0.0.1
+  username: admin
+  password: literal-pw
+`)
+	persisted, err := RotateBMCPasswordRef(path, "10.0.0.1", "new-pw")
+	if err != nil {
+		t.Fatalf("RotateBMCPasswordRef: %v", err)
+	}
+	if persisted {
+		t.Error("literal password reported as persisted")
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig is the shared TLS settings block consumed by the WebSocket
+// dialer and the BMC HTTP client, replacing scattered boolean knobs.
+type TLSConfig struct {
+	// CAFile is a PEM bundle used instead of the system roots.
+	CAFile string `json:"ca_file"`
+	// MinVersion is the lowest accepted protocol version: "1.0" to
+	// "1.3". Empty uses the crypto/tls default.
+	MinVersion string `json:"min_version"`
+	// CipherSuites restricts TLS 1.2 suites to the named ones (Go
+	// names, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). TLS 1.3
+	// suites are not configurable.
+	CipherSuites []string `json:"cipher_suites"`
+	// InsecureSkipVerify disables certificate verification for this
+	// endpoint only.
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+}
+
+// Build converts the settings into a *tls.Config.
+func (t TLSConfig) Build() (*tls.Config, error) {
+	out := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca_file %s: no certificates found", t.CAFile)
+		}
+		out.RootCAs = pool
+	}
+	if t.MinVersion != "" {
+		v, err := tlsVersion(t.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		out.MinVersion = v
+	}
+	for _, name := range t.CipherSuites {
+		id, err := cipherSuiteID(name)
+		if err != nil {
+			return nil, err
+		}
+		out.CipherSuites = append(out.CipherSuites, id)
+	}
+	return out, nil
+}
+
+func tlsVersion(s string) (uint16, error) {
+	switch s {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("tls min_version %q: want 1.0, 1.1, 1.2, or 1.3", s)
+	}
+}
+
+func cipherSuiteID(name string) (uint16, error) {
+	for _, cs := range tls.CipherSuites() {
+		if cs.Name == name {
+			return cs.ID, nil
+		}
+	}
+	for _, cs := range tls.InsecureCipherSuites() {
+		if cs.Name == name {
+			return cs.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("tls cipher_suites: unknown suite %q", name)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	cfg := TLSConfig{
+		CAFile:       writeTestCA(t),
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}
+	built, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if built.RootCAs == nil {
+		t.Error("RootCAs not set from ca_file")
+	}
+	if built.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x", built.MinVersion)
+	}
+	if len(built.CipherSuites) != 1 {
+		t.Errorf("CipherSuites = %v", built.CipherSuites)
+	}
+}
+
+func TestTLSConfigBuildErrors(t *testing.T) {
+	if _, err := (TLSConfig{MinVersion: "1.4"}).Build(); err == nil {
+		t.Error("bad min_version accepted")
+	}
+	if _, err := (TLSConfig{CipherSuites: []string{"TLS_BOGUS"}}).Build(); err == nil {
+		t.Error("unknown cipher suite accepted")
+	}
+	if _, err := (TLSConfig{CAFile: "/nonexistent/ca.pem"}).Build(); err == nil {
+		t.Error("missing ca_file accepted")
+	}
+}
+
+func TestValidateChecksTLSBlocks(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, `
+server_url: wss://x/agent
+client_id: n1
+tls:
+  min_version: "9.9"
+`))
+	if err == nil {
+		t.Error("invalid tls block accepted at load")
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/simonschuang/demo/internal/vault"
+)
+
+// VaultConfig points the agent at a HashiCorp Vault KV v2 secret that
+// holds its credentials, so large fleets can rotate BMC passwords
+// centrally instead of editing YAML per host.
+type VaultConfig struct {
+	// Addr is the Vault server URL; empty disables Vault integration.
+	Addr string `json:"addr"`
+	// Token authenticates directly; alternatively set RoleID/SecretID
+	// for AppRole login.
+	Token    string `json:"token"`
+	RoleID   string `json:"role_id"`
+	SecretID string `json:"secret_id"`
+	// Mount is the KV v2 mount point. Default "secret".
+	Mount string `json:"mount"`
+	// Path is the secret path under the mount. Recognized keys:
+	// client_token, bmc_username, bmc_password.
+	Path string `json:"path"`
+	// RenewIntervalSeconds is how often credentials are re-fetched and
+	// the token lease renewed. Default 900.
+	RenewIntervalSeconds int  `json:"renew_interval_seconds"`
+	InsecureSkipVerify   bool `json:"insecure_skip_verify"`
+}
+
+// applyVault fetches credentials from Vault and overlays them onto the
+// configuration. Called at load, so periodic reloads pick up rotated
+// secrets.
+func (c *Config) applyVault() error {
+	v := c.Vault
+	if v.Addr == "" || v.Path == "" {
+		return nil
+	}
+	cl := vault.New(v.Addr, v.Token, v.InsecureSkipVerify)
+	if v.RoleID != "" {
+		if err := cl.Login(v.RoleID, v.SecretID); err != nil {
+			return fmt.Errorf("vault: %w", err)
+		}
+	}
+	data, err := cl.ReadKV(v.Mount, v.Path)
+	if err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	if s, ok := data["client_token"]; ok {
+		c.ClientToken = s
+	}
+	for i := range c.BMC {
+		if s, ok := data["bmc_username"]; ok {
+			c.BMC[i].Username = s
+		}
+		if s, ok := data["bmc_password"]; ok {
+			c.BMC[i].Password = s
+		}
+	}
+	return nil
+}
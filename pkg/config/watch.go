@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// defaultWatchInterval is how often Watch polls the file for changes.
+const defaultWatchInterval = 2 * time.Second
+
+// Reload re-reads the watched configuration file, re-applies any
+// persisted runtime overrides on top, and swaps the result in. The
+// current configuration is kept when the new file fails to parse or
+// validate, so a bad edit never takes down a working agent.
+func (m *Manager) Reload() (string, error) {
+	m.mu.RLock()
+	path := m.path
+	m.mu.RUnlock()
+	if path == "" {
+		return "", fmt.Errorf("no configuration file is being watched")
+	}
+	fresh, err := LoadConfig(path)
+	if err != nil {
+		return "", fmt.Errorf("reload rejected: %w", err)
+	}
+
+	m.mu.Lock()
+	merged := fresh
+	if len(m.overrides) > 0 {
+		merged, err = mergeConfig(fresh, m.overrides)
+		if err == nil {
+			merged.applyDefaults()
+			err = merged.Validate()
+		}
+		if err != nil {
+			m.mu.Unlock()
+			return "", fmt.Errorf("reload rejected: %w", err)
+		}
+	}
+	m.cfg = merged
+	listeners := append([]ChangeListener(nil), m.listeners...)
+	m.mu.Unlock()
+
+	for _, l := range listeners {
+		l(merged)
+	}
+	return merged.Hash(), nil
+}
+
+// Watch starts polling path for changes and reloads the configuration
+// whenever the file's modification time or size changes. interval <= 0
+// uses a sensible default. The returned function stops the watcher.
+// Reload may also be called directly, e.g. from a SIGHUP handler.
+func (m *Manager) Watch(path string, interval time.Duration) func() {
+	m.mu.Lock()
+	m.path = path
+	m.mu.Unlock()
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	stop := make(chan struct{})
+	go func() {
+		var lastMod time.Time
+		var lastSize int64
+		if fi, err := os.Stat(path); err == nil {
+			lastMod, lastSize = fi.ModTime(), fi.Size()
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fi, err := os.Stat(path)
+				if err != nil {
+					continue
+				}
+				if fi.ModTime().Equal(lastMod) && fi.Size() == lastSize {
+					continue
+				}
+				lastMod, lastSize = fi.ModTime(), fi.Size()
+				if hash, err := m.Reload(); err != nil {
+					slog.Warn("config reload failed, keeping previous", "error", err)
+				} else {
+					slog.Info("config reloaded", "hash", hash)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
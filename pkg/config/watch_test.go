@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFileT(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadPicksUpFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFileT(t, path, "server_url: wss://x/agent\nclient_id: n1\nlog_level: info\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr, _ := NewManager(cfg, "")
+	stop := mgr.Watch(path, time.Hour) // register path; ticker never fires
+	defer stop()
+
+	var notified *Config
+	mgr.Subscribe(func(c *Config) { notified = c })
+
+	writeFileT(t, path, "server_url: wss://x/agent\nclient_id: n1\nlog_level: debug\n")
+	if _, err := mgr.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if mgr.Current().LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want debug", mgr.Current().LogLevel)
+	}
+	if notified == nil || notified.LogLevel != "debug" {
+		t.Error("listener not notified on reload")
+	}
+}
+
+func TestReloadRejectsInvalidFileKeepsOld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFileT(t, path, "server_url: wss://x/agent\nclient_id: n1\n")
+	cfg, _ := LoadConfig(path)
+	mgr, _ := NewManager(cfg, "")
+	stop := mgr.Watch(path, time.Hour)
+	defer stop()
+	before := mgr.Current().Hash()
+
+	writeFileT(t, path, "server_url: http://nope\nclient_id: n1\n")
+	if _, err := mgr.Reload(); err == nil {
+		t.Fatal("expected reload of invalid config to fail")
+	}
+	if mgr.Current().Hash() != before {
+		t.Error("invalid reload replaced the live config")
+	}
+}
+
+func TestReloadKeepsPersistedOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFileT(t, path, "server_url: wss://x/agent\nclient_id: n1\n")
+	cfg, _ := LoadConfig(path)
+	mgr, _ := NewManager(cfg, filepath.Join(dir, "overrides.json"))
+	stop := mgr.Watch(path, time.Hour)
+	defer stop()
+	if _, err := mgr.Apply(map[string]interface{}{"log_level": "warn"}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFileT(t, path, "server_url: wss://x/agent\nclient_id: n1\ncollect_interval: 120\n")
+	if _, err := mgr.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	cur := mgr.Current()
+	if cur.CollectInterval != 120 {
+		t.Errorf("CollectInterval = %d, want 120", cur.CollectInterval)
+	}
+	if cur.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, want persisted override warn", cur.LogLevel)
+	}
+}
+
+func TestReloadWithoutWatchFails(t *testing.T) {
+	mgr, _ := NewManager(testConfig(), "")
+	if _, err := mgr.Reload(); err == nil {
+		t.Error("Reload without a watched path should fail")
+	}
+}
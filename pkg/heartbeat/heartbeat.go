@@ -0,0 +1,82 @@
+// Package heartbeat periodically reports agent liveness to the server.
+package heartbeat
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/simonschuang/demo/internal/jitter"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// Heartbeat sends a heartbeat message on a fixed interval while the
+// connection is up. It is started once at boot and reacts to connection
+// state events, surviving reconnects without being re-instantiated.
+type Heartbeat struct {
+	client        *websocket.Client
+	interval      time.Duration
+	jitterPercent int
+	stop          chan struct{}
+	started       time.Time
+}
+
+// New builds a heartbeat sender for the given client. jitterPercent
+// randomizes each period so fleets of agents do not phase-lock.
+func New(client *websocket.Client, interval time.Duration, jitterPercent int) *Heartbeat {
+	return &Heartbeat{
+		client:        client,
+		interval:      interval,
+		jitterPercent: jitterPercent,
+		stop:          make(chan struct{}),
+		started:       time.Now(),
+	}
+}
+
+// Start begins sending heartbeats until Stop is called.
+func (h *Heartbeat) Start() {
+	go h.run()
+}
+
+// Stop halts the heartbeat loop.
+func (h *Heartbeat) Stop() {
+	close(h.stop)
+}
+
+func (h *Heartbeat) run() {
+	events, unsubscribe := h.client.SubscribeState(4)
+	defer unsubscribe()
+	ticker := jitter.NewTicker(h.interval, h.jitterPercent)
+	defer ticker.Stop()
+	if h.client.Connected() {
+		h.send()
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if h.client.Connected() {
+				h.send()
+			}
+		case ev := <-events:
+			// Announce liveness immediately after each (re)connect
+			// rather than waiting out the ticker.
+			if ev.State == websocket.StateConnected {
+				h.send()
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *Heartbeat) send() {
+	hostname, _ := os.Hostname()
+	msg := websocket.NewMessage(websocket.TypeHeartbeat, map[string]interface{}{
+		"hostname":       hostname,
+		"uptime_seconds": int64(time.Since(h.started).Seconds()),
+		"stats":          h.client.Stats(),
+	})
+	if err := h.client.Send(msg); err != nil {
+		slog.Warn("heartbeat send failed", "error", err)
+	}
+}
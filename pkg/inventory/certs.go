@@ -0,0 +1,90 @@
+package inventory
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// certNow is a variable so tests get deterministic days-remaining.
+var certNow = time.Now
+
+// certDialTimeout bounds each local endpoint probe.
+const certDialTimeout = 2 * time.Second
+
+// ScanCertificates inspects the configured certificate files and local
+// TLS ports and reports subject and expiry for each. Scan failures are
+// reported per entry rather than dropping it, so a deleted cert file is
+// as visible as an expiring one.
+func ScanCertificates(files []string, ports []int) []CertificateInfo {
+	var out []CertificateInfo
+	for _, path := range files {
+		info := CertificateInfo{Source: path}
+		if cert, err := loadPEMCertificate(path); err != nil {
+			info.Error = err.Error()
+		} else {
+			fillCertificate(&info, cert)
+		}
+		out = append(out, info)
+	}
+	for _, port := range ports {
+		info := CertificateInfo{Source: fmt.Sprintf("port:%d", port)}
+		if cert, err := fetchPortCertificate(port); err != nil {
+			info.Error = err.Error()
+		} else {
+			fillCertificate(&info, cert)
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+func fillCertificate(info *CertificateInfo, cert *x509.Certificate) {
+	info.Subject = cert.Subject.String()
+	info.Issuer = cert.Issuer.String()
+	info.NotAfter = cert.NotAfter.Unix()
+	info.DaysRemaining = int(cert.NotAfter.Sub(certNow()).Hours() / 24)
+}
+
+// loadPEMCertificate reads the first certificate block from a PEM file.
+func loadPEMCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return nil, fmt.Errorf("no certificate in %s", path)
+}
+
+// fetchPortCertificate handshakes with a local TLS endpoint and returns
+// its leaf certificate. Verification is skipped on purpose: the point
+// is to read the cert, not to trust it.
+func fetchPortCertificate(port int) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: certDialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp",
+		net.JoinHostPort("127.0.0.1", fmt.Sprint(port)),
+		&tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("port %d presented no certificate", port)
+	}
+	return certs[0], nil
+}
@@ -0,0 +1,113 @@
+package inventory
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeTestCert returns a self-signed certificate expiring after the
+// given lifetime, as PEM bytes and a tls.Certificate.
+func makeTestCert(t *testing.T, cn string, lifetime time.Duration) ([]byte, tls.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(lifetime),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return pemBytes, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestScanCertificatesFile(t *testing.T) {
+	pemBytes, _ := makeTestCert(t, "web.example.com", 40*24*time.Hour)
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(path, pemBytes, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ScanCertificates([]string{path}, nil)
+	if len(got) != 1 {
+		t.Fatalf("certs = %+v", got)
+	}
+	c := got[0]
+	if c.Source != path || c.Error != "" {
+		t.Fatalf("cert = %+v", c)
+	}
+	if !strings.Contains(c.Subject, "web.example.com") {
+		t.Errorf("Subject = %q", c.Subject)
+	}
+	if c.DaysRemaining < 38 || c.DaysRemaining > 40 {
+		t.Errorf("DaysRemaining = %d, want ~39", c.DaysRemaining)
+	}
+}
+
+func TestScanCertificatesExpired(t *testing.T) {
+	pemBytes, _ := makeTestCert(t, "old.example.com", -24*time.Hour)
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(path, pemBytes, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := ScanCertificates([]string{path}, nil)
+	if len(got) != 1 || got[0].DaysRemaining >= 0 {
+		t.Errorf("certs = %+v, want negative days remaining", got)
+	}
+}
+
+func TestScanCertificatesMissingFile(t *testing.T) {
+	got := ScanCertificates([]string{"/nonexistent/cert.pem"}, nil)
+	if len(got) != 1 || got[0].Error == "" {
+		t.Errorf("certs = %+v, want per-entry error", got)
+	}
+}
+
+func TestScanCertificatesPort(t *testing.T) {
+	_, serverCert := makeTestCert(t, "local.example.com", 10*24*time.Hour)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0",
+		&tls.Config{Certificates: []tls.Certificate{serverCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	got := ScanCertificates(nil, []int{port})
+	if len(got) != 1 {
+		t.Fatalf("certs = %+v", got)
+	}
+	c := got[0]
+	if c.Error != "" || !strings.Contains(c.Subject, "local.example.com") {
+		t.Errorf("cert = %+v", c)
+	}
+}
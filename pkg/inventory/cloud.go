@@ -0,0 +1,155 @@
+package inventory
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metadata service endpoints, overridable in tests. All three clouds
+// serve the link-local address; GCP also answers on
+// metadata.google.internal.
+var (
+	awsMetadataURL   = "http://169.254.169.254"
+	gcpMetadataURL   = "http://169.254.169.254"
+	azureMetadataURL = "http://169.254.169.254"
+)
+
+// metadataTimeout keeps on-prem hosts from stalling collection while
+// the link-local probe times out.
+const metadataTimeout = time.Second
+
+var (
+	cloudOnce sync.Once
+	cloudInfo *CloudInfo
+)
+
+// readCloud queries the cloud metadata service for instance identity.
+// The result cannot change while the host is up, so only the first
+// collection cycle pays for the probe; on-prem hosts pay it once and
+// then report nothing.
+func readCloud() *CloudInfo {
+	cloudOnce.Do(func() { cloudInfo = detectCloud() })
+	return cloudInfo
+}
+
+func detectCloud() *CloudInfo {
+	client := &http.Client{Timeout: metadataTimeout}
+	for _, probe := range []func(*http.Client) *CloudInfo{awsMetadata, gcpMetadata, azureMetadata} {
+		if info := probe(client); info != nil {
+			return info
+		}
+	}
+	return nil
+}
+
+// awsMetadata speaks IMDSv2: a session token first, then the
+// individual metadata paths.
+func awsMetadata(client *http.Client) *CloudInfo {
+	req, err := http.NewRequest(http.MethodPut, awsMetadataURL+"/latest/api/token", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token := fetch(client, req)
+	if token == "" {
+		return nil
+	}
+	get := func(p string) string {
+		req, err := http.NewRequest(http.MethodGet, awsMetadataURL+"/latest/meta-data/"+p, nil)
+		if err != nil {
+			return ""
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+		return fetch(client, req)
+	}
+	id := get("instance-id")
+	if id == "" {
+		return nil
+	}
+	return &CloudInfo{
+		Provider:     "aws",
+		InstanceID:   id,
+		InstanceType: get("instance-type"),
+		Region:       get("placement/region"),
+		Zone:         get("placement/availability-zone"),
+	}
+}
+
+func gcpMetadata(client *http.Client) *CloudInfo {
+	get := func(p string) string {
+		req, err := http.NewRequest(http.MethodGet, gcpMetadataURL+"/computeMetadata/v1/instance/"+p, nil)
+		if err != nil {
+			return ""
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		return fetch(client, req)
+	}
+	id := get("id")
+	if id == "" {
+		return nil
+	}
+	// zone and machine-type come as full resource paths like
+	// projects/123/zones/us-central1-a; keep the last element.
+	zone := path.Base(get("zone"))
+	region := zone
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		region = zone[:i]
+	}
+	return &CloudInfo{
+		Provider:     "gcp",
+		InstanceID:   id,
+		InstanceType: path.Base(get("machine-type")),
+		Region:       region,
+		Zone:         zone,
+	}
+}
+
+func azureMetadata(client *http.Client) *CloudInfo {
+	req, err := http.NewRequest(http.MethodGet,
+		azureMetadataURL+"/metadata/instance/compute?api-version=2021-02-01&format=json", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Metadata", "true")
+	body := fetch(client, req)
+	if body == "" {
+		return nil
+	}
+	var compute struct {
+		VMID     string `json:"vmId"`
+		VMSize   string `json:"vmSize"`
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+	}
+	if err := json.Unmarshal([]byte(body), &compute); err != nil || compute.VMID == "" {
+		return nil
+	}
+	return &CloudInfo{
+		Provider:     "azure",
+		InstanceID:   compute.VMID,
+		InstanceType: compute.VMSize,
+		Region:       compute.Location,
+		Zone:         compute.Zone,
+	}
+}
+
+func fetch(client *http.Client, req *http.Request) string {
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(body))
+}
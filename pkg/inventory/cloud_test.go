@@ -0,0 +1,94 @@
+package inventory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAWSMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			w.Write([]byte("tok123"))
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != "tok123" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/instance-id":
+			w.Write([]byte("i-0abc"))
+		case "/latest/meta-data/instance-type":
+			w.Write([]byte("m5.large"))
+		case "/latest/meta-data/placement/region":
+			w.Write([]byte("us-east-1"))
+		case "/latest/meta-data/placement/availability-zone":
+			w.Write([]byte("us-east-1b"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	old := awsMetadataURL
+	awsMetadataURL = srv.URL
+	defer func() { awsMetadataURL = old }()
+
+	info := awsMetadata(srv.Client())
+	want := &CloudInfo{Provider: "aws", InstanceID: "i-0abc",
+		InstanceType: "m5.large", Region: "us-east-1", Zone: "us-east-1b"}
+	if info == nil || *info != *want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+}
+
+func TestGCPMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/id":
+			w.Write([]byte("1234567890"))
+		case "/computeMetadata/v1/instance/machine-type":
+			w.Write([]byte("projects/42/machineTypes/e2-standard-4"))
+		case "/computeMetadata/v1/instance/zone":
+			w.Write([]byte("projects/42/zones/us-central1-a"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	old := gcpMetadataURL
+	gcpMetadataURL = srv.URL
+	defer func() { gcpMetadataURL = old }()
+
+	info := gcpMetadata(srv.Client())
+	want := &CloudInfo{Provider: "gcp", InstanceID: "1234567890",
+		InstanceType: "e2-standard-4", Region: "us-central1", Zone: "us-central1-a"}
+	if info == nil || *info != *want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+}
+
+func TestAzureMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"vmId":"vm-xyz","vmSize":"Standard_D4s_v3","location":"westeurope","zone":"2"}`))
+	}))
+	defer srv.Close()
+	old := azureMetadataURL
+	azureMetadataURL = srv.URL
+	defer func() { azureMetadataURL = old }()
+
+	info := azureMetadata(srv.Client())
+	want := &CloudInfo{Provider: "azure", InstanceID: "vm-xyz",
+		InstanceType: "Standard_D4s_v3", Region: "westeurope", Zone: "2"}
+	if info == nil || *info != *want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// containerSockets are tried in order; the first one that answers the
+// Engine API is used. Podman exposes the same API on its own socket.
+var containerSockets = []string{
+	"/var/run/docker.sock",
+	"/run/podman/podman.sock",
+}
+
+// apiContainer mirrors the fields we use from GET /containers/json.
+type apiContainer struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"`
+	Image   string   `json:"Image"`
+	ImageID string   `json:"ImageID"`
+	State   string   `json:"State"`
+	Status  string   `json:"Status"`
+	Ports   []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	Mounts []struct {
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+	} `json:"Mounts"`
+}
+
+// readContainers reports the containers running on this host. Hosts
+// without a container runtime (or without socket access) report
+// nothing.
+func readContainers() []ContainerInfo {
+	for _, socket := range containerSockets {
+		if out := queryContainers(socket); out != nil {
+			return out
+		}
+	}
+	return nil
+}
+
+func queryContainers(socket string) []ContainerInfo {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	var listed []apiContainer
+	if err := engineGet(client, "/containers/json?all=1", &listed); err != nil {
+		return nil
+	}
+	out := make([]ContainerInfo, 0, len(listed))
+	for _, c := range listed {
+		info := ContainerInfo{
+			ID:          c.ID,
+			Image:       c.Image,
+			ImageDigest: c.ImageID,
+			State:       c.State,
+			Status:      c.Status,
+		}
+		if len(c.Names) > 0 {
+			info.Name = trimSlash(c.Names[0])
+		}
+		for _, p := range c.Ports {
+			if p.PublicPort == 0 {
+				continue
+			}
+			info.Ports = append(info.Ports,
+				fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+		}
+		sort.Strings(info.Ports)
+		for _, m := range c.Mounts {
+			info.Mounts = append(info.Mounts, m.Source+":"+m.Destination)
+		}
+		// RestartCount is only available from the inspect endpoint.
+		var detail struct {
+			RestartCount int `json:"RestartCount"`
+		}
+		if err := engineGet(client, "/containers/"+c.ID+"/json", &detail); err == nil {
+			info.RestartCount = detail.RestartCount
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// engineGet performs a GET against the Engine API over the unix socket.
+// The host in the URL is ignored by the dialer but required by net/http.
+func engineGet(client *http.Client, path string, v interface{}) error {
+	resp, err := client.Get("http://docker" + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func trimSlash(name string) string {
+	if len(name) > 0 && name[0] == '/' {
+		return name[1:]
+	}
+	return name
+}
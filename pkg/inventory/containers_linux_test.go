@@ -0,0 +1,66 @@
+//go:build linux
+
+package inventory
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func serveFakeEngine(t *testing.T) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{
+			"Id": "abc123",
+			"Names": ["/web"],
+			"Image": "nginx:1.25",
+			"ImageID": "sha256:deadbeef",
+			"State": "running",
+			"Status": "Up 3 days",
+			"Ports": [{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}],
+			"Mounts": [{"Source": "/srv/www", "Destination": "/usr/share/nginx/html"}]
+		}]`))
+	})
+	mux.HandleFunc("/containers/abc123/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"RestartCount": 2}`))
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return socket
+}
+
+func TestQueryContainers(t *testing.T) {
+	socket := serveFakeEngine(t)
+	out := queryContainers(socket)
+	if len(out) != 1 {
+		t.Fatalf("got %d containers, want 1", len(out))
+	}
+	c := out[0]
+	if c.Name != "web" || c.Image != "nginx:1.25" || c.ImageDigest != "sha256:deadbeef" {
+		t.Errorf("container = %+v", c)
+	}
+	if c.State != "running" || c.RestartCount != 2 {
+		t.Errorf("state = %q, restarts = %d", c.State, c.RestartCount)
+	}
+	if len(c.Ports) != 1 || c.Ports[0] != "0.0.0.0:8080->80/tcp" {
+		t.Errorf("ports = %v", c.Ports)
+	}
+	if len(c.Mounts) != 1 || c.Mounts[0] != "/srv/www:/usr/share/nginx/html" {
+		t.Errorf("mounts = %v", c.Mounts)
+	}
+}
+
+func TestQueryContainersMissingSocket(t *testing.T) {
+	if out := queryContainers(filepath.Join(t.TempDir(), "nope.sock")); out != nil {
+		t.Errorf("got %v from missing socket, want nil", out)
+	}
+}
@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Diff compares two marshaled inventory snapshots and returns the
+// fields that changed (dotted paths to their new values) and the paths
+// that disappeared. Nested objects are descended into; arrays are
+// treated atomically, since diffing reordered slices produces noise
+// rather than insight.
+func Diff(prev, curr map[string]interface{}) (changed map[string]interface{}, removed []string) {
+	changed = make(map[string]interface{})
+	diffInto("", prev, curr, changed, &removed)
+	sort.Strings(removed)
+	if len(changed) == 0 {
+		changed = nil
+	}
+	return changed, removed
+}
+
+func diffInto(prefix string, prev, curr map[string]interface{}, changed map[string]interface{}, removed *[]string) {
+	for key, cv := range curr {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		pv, ok := prev[key]
+		if !ok {
+			changed[path] = cv
+			continue
+		}
+		pm, prevIsMap := pv.(map[string]interface{})
+		cm, currIsMap := cv.(map[string]interface{})
+		if prevIsMap && currIsMap {
+			diffInto(path, pm, cm, changed, removed)
+			continue
+		}
+		if !reflect.DeepEqual(pv, cv) {
+			changed[path] = cv
+		}
+	}
+	for key := range prev {
+		if _, ok := curr[key]; !ok {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			*removed = append(*removed, path)
+		}
+	}
+}
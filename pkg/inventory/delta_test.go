@@ -0,0 +1,57 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNestedAndRemoved(t *testing.T) {
+	prev := map[string]interface{}{
+		"hostname": "node-01",
+		"memory": map[string]interface{}{
+			"total": float64(64),
+			"used":  float64(10),
+		},
+		"dmi": map[string]interface{}{"serial_number": "ABC"},
+	}
+	curr := map[string]interface{}{
+		"hostname": "node-01",
+		"memory": map[string]interface{}{
+			"total": float64(64),
+			"used":  float64(12),
+		},
+		"uptime_seconds": float64(99),
+	}
+
+	changed, removed := Diff(prev, curr)
+	wantChanged := map[string]interface{}{
+		"memory.used":    float64(12),
+		"uptime_seconds": float64(99),
+	}
+	if !reflect.DeepEqual(changed, wantChanged) {
+		t.Errorf("changed = %v, want %v", changed, wantChanged)
+	}
+	if len(removed) != 1 || removed[0] != "dmi" {
+		t.Errorf("removed = %v, want [dmi]", removed)
+	}
+}
+
+func TestDiffArraysAreAtomic(t *testing.T) {
+	prev := map[string]interface{}{"disks": []interface{}{"a", "b"}}
+	curr := map[string]interface{}{"disks": []interface{}{"a", "c"}}
+	changed, removed := Diff(prev, curr)
+	if !reflect.DeepEqual(changed["disks"], []interface{}{"a", "c"}) {
+		t.Errorf("changed = %v", changed)
+	}
+	if removed != nil {
+		t.Errorf("removed = %v", removed)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	snap := map[string]interface{}{"hostname": "n", "cpu": map[string]interface{}{"cores": float64(8)}}
+	changed, removed := Diff(snap, snap)
+	if changed != nil || removed != nil {
+		t.Errorf("diff of identical snapshots = %v, %v", changed, removed)
+	}
+}
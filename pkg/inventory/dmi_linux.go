@@ -0,0 +1,52 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dmiRoot is a variable so tests can point the reader at a fixture
+// tree.
+var dmiRoot = "/sys/class/dmi/id"
+
+// readDMI reads the SMBIOS identity exported by the kernel. Serial
+// numbers and asset tags are root-only in sysfs, so those fields stay
+// empty for unprivileged agents. Returns nil on hosts without DMI
+// (most VMs expose it; some containers do not).
+func readDMI() *DMIInfo {
+	dmi := &DMIInfo{
+		Manufacturer: dmiAttr("sys_vendor"),
+		ProductName:  dmiAttr("product_name"),
+		SerialNumber: dmiAttr("product_serial"),
+		UUID:         dmiAttr("product_uuid"),
+		AssetTag:     dmiAttr("chassis_asset_tag"),
+		ChassisType:  dmiAttr("chassis_type"),
+		BoardVendor:  dmiAttr("board_vendor"),
+		BoardName:    dmiAttr("board_name"),
+		BoardSerial:  dmiAttr("board_serial"),
+		BIOSVersion:  dmiAttr("bios_version"),
+		BIOSDate:     dmiAttr("bios_date"),
+	}
+	if *dmi == (DMIInfo{}) {
+		return nil
+	}
+	return dmi
+}
+
+// dmiAttr reads one attribute, dropping the placeholder strings some
+// vendors ship instead of leaving the field empty.
+func dmiAttr(name string) string {
+	data, err := os.ReadFile(filepath.Join(dmiRoot, name))
+	if err != nil {
+		return ""
+	}
+	val := strings.TrimSpace(string(data))
+	switch strings.ToLower(val) {
+	case "to be filled by o.e.m.", "not specified", "none", "default string":
+		return ""
+	}
+	return val
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDMI(t *testing.T) {
+	old := dmiRoot
+	dmiRoot = t.TempDir()
+	defer func() { dmiRoot = old }()
+
+	attrs := map[string]string{
+		"sys_vendor":        "Dell Inc.\n",
+		"product_name":      "PowerEdge R750\n",
+		"product_serial":    "ABC1234\n",
+		"chassis_type":      "23\n",
+		"chassis_asset_tag": "To Be Filled By O.E.M.\n",
+		"board_vendor":      "Dell Inc.\n",
+		"board_name":        "0H3K7P\n",
+	}
+	for name, content := range attrs {
+		if err := os.WriteFile(filepath.Join(dmiRoot, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dmi := readDMI()
+	if dmi == nil {
+		t.Fatal("readDMI returned nil")
+	}
+	if dmi.Manufacturer != "Dell Inc." || dmi.ProductName != "PowerEdge R750" ||
+		dmi.SerialNumber != "ABC1234" || dmi.ChassisType != "23" {
+		t.Errorf("dmi = %+v", dmi)
+	}
+	if dmi.AssetTag != "" {
+		t.Errorf("AssetTag = %q, want OEM placeholder dropped", dmi.AssetTag)
+	}
+}
+
+func TestReadDMIAbsent(t *testing.T) {
+	old := dmiRoot
+	dmiRoot = filepath.Join(t.TempDir(), "nodmi")
+	defer func() { dmiRoot = old }()
+	if dmi := readDMI(); dmi != nil {
+		t.Errorf("dmi = %+v, want nil without /sys/class/dmi", dmi)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Roots are variables so tests can point the readers at fixtures.
+var (
+	efiRoot = "/sys/firmware/efi"
+	tpmRoot = "/sys/class/tpm"
+)
+
+// secureBootVar is the SecureBoot EFI variable; the final data byte is
+// 1 when enforcement is on.
+const secureBootVar = "efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// readFirmware reports boot mode, Secure Boot state, and TPM presence.
+// BIOS identity comes from the DMI section already collected.
+func readFirmware(dmi *DMIInfo) *FirmwareInfo {
+	fw := &FirmwareInfo{BootMode: "bios"}
+	if dmi != nil {
+		fw.BIOSVersion = dmi.BIOSVersion
+		fw.BIOSDate = dmi.BIOSDate
+	}
+	if _, err := os.Stat(efiRoot); err == nil {
+		fw.BootMode = "uefi"
+		fw.SecureBoot = readSecureBoot()
+	}
+	fw.TPMPresent, fw.TPMVersion = readTPM()
+	return fw
+}
+
+// readSecureBoot decodes the SecureBoot EFI variable: four attribute
+// bytes followed by the state byte.
+func readSecureBoot() string {
+	data, err := os.ReadFile(filepath.Join(efiRoot, secureBootVar))
+	if err != nil || len(data) < 5 {
+		return ""
+	}
+	if data[4] == 1 {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// readTPM looks for a TPM character device and its spec version.
+func readTPM() (present bool, version string) {
+	entries, err := os.ReadDir(tpmRoot)
+	if err != nil {
+		return false, ""
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "tpm") {
+			continue
+		}
+		dir := filepath.Join(tpmRoot, e.Name())
+		switch readTrimmed(filepath.Join(dir, "tpm_version_major")) {
+		case "2":
+			return true, "2.0"
+		case "1":
+			return true, "1.2"
+		}
+		// Older kernels only expose a caps file, and only for TPM 1.2
+		// devices.
+		if _, err := os.Stat(filepath.Join(dir, "device", "caps")); err == nil {
+			return true, "1.2"
+		}
+		return true, ""
+	}
+	return false, ""
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFirmwareUEFISecureBoot(t *testing.T) {
+	oldEFI, oldTPM := efiRoot, tpmRoot
+	dir := t.TempDir()
+	efiRoot = filepath.Join(dir, "efi")
+	tpmRoot = filepath.Join(dir, "tpm")
+	defer func() { efiRoot, tpmRoot = oldEFI, oldTPM }()
+
+	varPath := filepath.Join(efiRoot, secureBootVar)
+	if err := os.MkdirAll(filepath.Dir(varPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(varPath, []byte{6, 0, 0, 0, 1}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tpm := filepath.Join(tpmRoot, "tpm0")
+	if err := os.MkdirAll(tpm, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tpm, "tpm_version_major"), []byte("2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fw := readFirmware(&DMIInfo{BIOSVersion: "1.2.3", BIOSDate: "01/15/2024"})
+	if fw.BootMode != "uefi" {
+		t.Errorf("BootMode = %q", fw.BootMode)
+	}
+	if fw.SecureBoot != "enabled" {
+		t.Errorf("SecureBoot = %q", fw.SecureBoot)
+	}
+	if !fw.TPMPresent || fw.TPMVersion != "2.0" {
+		t.Errorf("TPM = %v %q", fw.TPMPresent, fw.TPMVersion)
+	}
+	if fw.BIOSVersion != "1.2.3" || fw.BIOSDate != "01/15/2024" {
+		t.Errorf("BIOS = %q %q", fw.BIOSVersion, fw.BIOSDate)
+	}
+}
+
+func TestReadFirmwareLegacy(t *testing.T) {
+	oldEFI, oldTPM := efiRoot, tpmRoot
+	dir := t.TempDir()
+	efiRoot = filepath.Join(dir, "missing-efi")
+	tpmRoot = filepath.Join(dir, "missing-tpm")
+	defer func() { efiRoot, tpmRoot = oldEFI, oldTPM }()
+
+	fw := readFirmware(nil)
+	if fw.BootMode != "bios" {
+		t.Errorf("BootMode = %q", fw.BootMode)
+	}
+	if fw.SecureBoot != "" {
+		t.Errorf("SecureBoot = %q", fw.SecureBoot)
+	}
+	if fw.TPMPresent {
+		t.Error("TPMPresent on host without a TPM")
+	}
+}
@@ -0,0 +1,54 @@
+package inventory
+
+import (
+	"net"
+	"os"
+	"runtime"
+)
+
+// Collector gathers the local inventory for this host.
+type Collector struct{}
+
+// NewCollector builds a local inventory collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Collect gathers a fresh inventory snapshot. Collection is best-effort:
+// sections that cannot be read are left empty rather than failing the
+// whole snapshot.
+func (c *Collector) Collect() (*Inventory, error) {
+	inv := &Inventory{
+		SchemaVersion: SchemaVersion,
+		OS:            runtime.GOOS,
+		Platform:      runtime.GOARCH,
+	}
+	inv.Hostname, _ = os.Hostname()
+	inv.Network = collectNetwork()
+	inv.Cloud = readCloud()
+	collectPlatform(inv)
+	return inv, nil
+}
+
+func collectNetwork() []NetworkInfo {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var out []NetworkInfo
+	for _, iface := range ifaces {
+		ni := NetworkInfo{
+			Name: iface.Name,
+			MAC:  iface.HardwareAddr.String(),
+			Up:   iface.Flags&net.FlagUp != 0,
+			MTU:  iface.MTU,
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, a := range addrs {
+				ni.Addresses = append(ni.Addresses, a.String())
+			}
+		}
+		out = append(out, ni)
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// collectPlatform fills in the Linux-specific inventory sections by
+// reading /proc and /sys directly.
+func collectPlatform(inv *Inventory) {
+	inv.KernelVersion = kernelVersion()
+	enrichNetworkTopology(inv.Network)
+	inv.Uptime = readUptime()
+	inv.CPU = readCPUInfo()
+	inv.Memory = readMemInfo()
+	inv.Disks = readMounts()
+	inv.PCIDevices = readPCIDevices()
+	inv.Services = readServices()
+	inv.Containers = readContainers()
+	inv.Processes = readProcesses()
+	inv.ListeningPorts = readListeningPorts()
+	inv.DMI = readDMI()
+	inv.Users = readUsers()
+	inv.KernelModules = readKernelModules()
+	inv.Virtualization = detectVirtualization(inv.DMI)
+	inv.NetworkConfig = readNetworkConfig()
+	inv.NUMA = readNUMA()
+	inv.Sensors = readSensors()
+	inv.Batteries = readBatteries()
+	inv.UPS = readUPS()
+	inv.Firmware = readFirmware(inv.DMI)
+	inv.TimeSync = readTimeSync()
+}
+
+func kernelVersion() string {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return ""
+	}
+	return utsString(uts.Release)
+}
+
+func utsString(f [65]int8) string {
+	b := make([]byte, 0, len(f))
+	for _, c := range f {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+func readUptime() int64 {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return int64(secs)
+}
+
+func readCPUInfo() CPUInfo {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return CPUInfo{}
+	}
+	defer f.Close()
+	info := CPUInfo{}
+	physical := map[string]bool{}
+	coresPerSocket := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, val, found := strings.Cut(sc.Text(), ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		switch key {
+		case "model name":
+			info.Model = val
+		case "processor":
+			info.Threads++
+		case "physical id":
+			physical[val] = true
+		case "cpu cores":
+			coresPerSocket, _ = strconv.Atoi(val)
+		case "cpu MHz":
+			if info.MHz == 0 {
+				info.MHz, _ = strconv.ParseFloat(val, 64)
+			}
+		}
+	}
+	sockets := len(physical)
+	if sockets == 0 {
+		sockets = 1
+	}
+	info.Cores = sockets * coresPerSocket
+	if info.Cores == 0 {
+		info.Cores = info.Threads
+	}
+	return info
+}
+
+func readMemInfo() MemoryInfo {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return MemoryInfo{}
+	}
+	defer f.Close()
+	mem := MemoryInfo{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			mem.Total = kb * 1024
+		case "MemAvailable:":
+			mem.Available = kb * 1024
+		}
+	}
+	if mem.Total >= mem.Available {
+		mem.Used = mem.Total - mem.Available
+	}
+	return mem
+}
+
+// pseudoFilesystems are skipped when reporting disk usage.
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true,
+	"tmpfs": true, "cgroup": true, "cgroup2": true, "overlay": true,
+	"squashfs": true, "debugfs": true, "tracefs": true, "securityfs": true,
+	"pstore": true, "bpf": true, "autofs": true, "mqueue": true,
+	"hugetlbfs": true, "fusectl": true, "configfs": true, "ramfs": true,
+	"binfmt_misc": true, "rpc_pipefs": true, "nsfs": true,
+}
+
+func readMounts() []DiskInfo {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []DiskInfo
+	seen := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		device, mount, fstype := fields[0], fields[1], fields[2]
+		if pseudoFilesystems[fstype] || seen[device] {
+			continue
+		}
+		var options []string
+		if len(fields) > 3 {
+			options = strings.Split(fields[3], ",")
+		}
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(mount, &st); err != nil {
+			continue
+		}
+		bsize := uint64(st.Bsize)
+		total := st.Blocks * bsize
+		if total == 0 {
+			continue
+		}
+		seen[device] = true
+		d := DiskInfo{
+			Device:     device,
+			Mountpoint: mount,
+			FSType:     fstype,
+			Total:      total,
+			Free:       st.Bavail * bsize,
+			Used:       (st.Blocks - st.Bfree) * bsize,
+			Options:    options,
+			ReadOnly:   st.Flags&syscall.MS_RDONLY != 0,
+		}
+		// btrfs and friends report no inode limit; leave the counts zero.
+		if st.Files >= st.Ffree {
+			d.InodesTotal = st.Files
+			d.InodesUsed = st.Files - st.Ffree
+		}
+		out = append(out, d)
+	}
+	return out
+}
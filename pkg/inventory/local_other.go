@@ -0,0 +1,7 @@
+//go:build !linux && !windows
+
+package inventory
+
+// collectPlatform is a no-op on platforms without a dedicated
+// implementation; only the portable sections are reported.
+func collectPlatform(inv *Inventory) {}
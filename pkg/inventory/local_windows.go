@@ -0,0 +1,162 @@
+//go:build windows
+
+package inventory
+
+import (
+	"os/exec"
+)
+
+// runCIMQuery executes the PowerShell pipeline for one CIM class. A
+// variable so tests could stub the exec boundary.
+var runCIMQuery = func(class string, properties []string) ([]byte, error) {
+	return exec.Command("powershell", "-NoProfile", "-NonInteractive",
+		"-Command", cimScript(class, properties)).Output()
+}
+
+// queryCIM fetches a CIM class into out (pointer to slice). Best
+// effort like the Linux readers: errors leave out empty.
+func queryCIM(class string, properties []string, out interface{}) {
+	data, err := runCIMQuery(class, properties)
+	if err != nil {
+		return
+	}
+	decodeCIM(data, out) //nolint:errcheck // best effort
+}
+
+// collectPlatform fills the Windows-specific inventory sections
+// through WMI, so Windows hosts report hardware identity, services,
+// volumes, and patch level like Linux ones do.
+func collectPlatform(inv *Inventory) {
+	collectWindowsSystem(inv)
+	inv.CPU = readWindowsCPU()
+	inv.Disks = readWindowsVolumes()
+	inv.Services = readWindowsServices()
+	inv.Hotfixes = readWindowsHotfixes()
+}
+
+func collectWindowsSystem(inv *Inventory) {
+	var oses []struct {
+		Version                string
+		TotalVisibleMemorySize uint64
+		FreePhysicalMemory     uint64
+	}
+	queryCIM("Win32_OperatingSystem",
+		[]string{"Version", "TotalVisibleMemorySize", "FreePhysicalMemory"}, &oses)
+	if len(oses) > 0 {
+		inv.KernelVersion = oses[0].Version
+		inv.Memory = MemoryInfo{
+			Total:     oses[0].TotalVisibleMemorySize * 1024,
+			Available: oses[0].FreePhysicalMemory * 1024,
+		}
+		if inv.Memory.Total >= inv.Memory.Available {
+			inv.Memory.Used = inv.Memory.Total - inv.Memory.Available
+		}
+	}
+
+	var systems []struct {
+		Manufacturer string
+		Model        string
+	}
+	queryCIM("Win32_ComputerSystem", []string{"Manufacturer", "Model"}, &systems)
+	var bioses []struct {
+		SerialNumber      string
+		SMBIOSBIOSVersion string
+	}
+	queryCIM("Win32_BIOS", []string{"SerialNumber", "SMBIOSBIOSVersion"}, &bioses)
+	dmi := &DMIInfo{}
+	if len(systems) > 0 {
+		dmi.Manufacturer = systems[0].Manufacturer
+		dmi.ProductName = systems[0].Model
+	}
+	if len(bioses) > 0 {
+		dmi.SerialNumber = bioses[0].SerialNumber
+		dmi.BIOSVersion = bioses[0].SMBIOSBIOSVersion
+	}
+	if *dmi != (DMIInfo{}) {
+		inv.DMI = dmi
+	}
+}
+
+func readWindowsCPU() CPUInfo {
+	var cpus []struct {
+		Name                      string
+		NumberOfCores             int
+		NumberOfLogicalProcessors int
+	}
+	queryCIM("Win32_Processor",
+		[]string{"Name", "NumberOfCores", "NumberOfLogicalProcessors"}, &cpus)
+	info := CPUInfo{}
+	for _, c := range cpus {
+		info.Model = c.Name
+		info.Cores += c.NumberOfCores
+		info.Threads += c.NumberOfLogicalProcessors
+	}
+	return info
+}
+
+func readWindowsVolumes() []DiskInfo {
+	var disks []struct {
+		DeviceID   string
+		FileSystem string
+		Size       uint64
+		FreeSpace  uint64
+		DriveType  int
+	}
+	queryCIM("Win32_LogicalDisk",
+		[]string{"DeviceID", "FileSystem", "Size", "FreeSpace", "DriveType"}, &disks)
+	var out []DiskInfo
+	for _, d := range disks {
+		// DriveType 3 is a local fixed disk; skip removable and network
+		// volumes.
+		if d.DriveType != 3 || d.Size == 0 {
+			continue
+		}
+		out = append(out, DiskInfo{
+			Device:     d.DeviceID,
+			Mountpoint: d.DeviceID,
+			FSType:     d.FileSystem,
+			Total:      d.Size,
+			Free:       d.FreeSpace,
+			Used:       d.Size - d.FreeSpace,
+		})
+	}
+	return out
+}
+
+func readWindowsServices() []ServiceInfo {
+	var services []struct {
+		Name      string
+		State     string
+		StartMode string
+	}
+	queryCIM("Win32_Service",
+		[]string{"Name", "State", "StartMode"}, &services)
+	var out []ServiceInfo
+	for _, s := range services {
+		out = append(out, ServiceInfo{
+			Name:        s.Name,
+			ActiveState: s.State,
+			Enabled:     s.StartMode,
+		})
+	}
+	return out
+}
+
+func readWindowsHotfixes() []HotfixInfo {
+	var fixes []struct {
+		HotFixID    string
+		Description string
+		InstalledOn string
+	}
+	queryCIM("Win32_QuickFixEngineering",
+		[]string{"HotFixID", "Description", "InstalledOn"}, &fixes)
+	var out []HotfixInfo
+	for _, f := range fixes {
+		out = append(out, HotfixInfo{
+			ID:          f.HotFixID,
+			Description: f.Description,
+			InstalledOn: f.InstalledOn,
+		})
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package inventory
+
+import "encoding/json"
+
+// ToMap renders a typed inventory as the open map format carried in
+// Message.Data. It is the compatibility shim between the typed schema
+// and the wire format today's servers parse; both sides can grow
+// fields without breaking the other.
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package inventory
+
+import "time"
+
+// Metrics is one lightweight utilization sample, streamed at a short
+// interval separately from the full inventory payload.
+type Metrics struct {
+	Timestamp int64 `json:"timestamp"`
+	// CPUPercent is overall CPU utilization since the previous sample.
+	CPUPercent float64 `json:"cpu_percent"`
+	Load1      float64 `json:"load1"`
+	Load5      float64 `json:"load5"`
+	Load15     float64 `json:"load15"`
+	// MemoryUsedPercent is used/total physical memory.
+	MemoryUsedPercent float64 `json:"memory_used_percent"`
+	// Disk and network rates since the previous sample, summed over
+	// all real devices.
+	DiskReadBytesPerSec  uint64 `json:"disk_read_bytes_per_sec"`
+	DiskWriteBytesPerSec uint64 `json:"disk_write_bytes_per_sec"`
+	NetRxBytesPerSec     uint64 `json:"net_rx_bytes_per_sec"`
+	NetTxBytesPerSec     uint64 `json:"net_tx_bytes_per_sec"`
+}
+
+// metricCounters are the raw kernel counters a rate is computed from.
+type metricCounters struct {
+	at        time.Time
+	cpuBusy   uint64
+	cpuTotal  uint64
+	diskRead  uint64
+	diskWrite uint64
+	netRx     uint64
+	netTx     uint64
+}
+
+// MetricsSampler produces utilization samples. Rates need two
+// observations, so the first Sample after construction reports only
+// the gauges (load, memory).
+type MetricsSampler struct {
+	prev *metricCounters
+}
+
+// NewMetricsSampler builds a sampler and primes the counter baseline.
+func NewMetricsSampler() *MetricsSampler {
+	s := &MetricsSampler{}
+	if c := readMetricCounters(); c != nil {
+		s.prev = c
+	}
+	return s
+}
+
+// Sample reads the current counters and returns utilization relative
+// to the previous call.
+func (s *MetricsSampler) Sample() *Metrics {
+	m := &Metrics{Timestamp: time.Now().Unix()}
+	m.Load1, m.Load5, m.Load15 = readLoadAvg()
+	if mem := readMemInfoPortable(); mem.Total > 0 {
+		m.MemoryUsedPercent = float64(mem.Used) / float64(mem.Total) * 100
+	}
+	curr := readMetricCounters()
+	if curr == nil {
+		return m
+	}
+	if prev := s.prev; prev != nil {
+		elapsed := curr.at.Sub(prev.at).Seconds()
+		if elapsed > 0 {
+			m.DiskReadBytesPerSec = rate(curr.diskRead, prev.diskRead, elapsed)
+			m.DiskWriteBytesPerSec = rate(curr.diskWrite, prev.diskWrite, elapsed)
+			m.NetRxBytesPerSec = rate(curr.netRx, prev.netRx, elapsed)
+			m.NetTxBytesPerSec = rate(curr.netTx, prev.netTx, elapsed)
+		}
+		if dt := curr.cpuTotal - prev.cpuTotal; curr.cpuTotal > prev.cpuTotal {
+			m.CPUPercent = float64(curr.cpuBusy-prev.cpuBusy) / float64(dt) * 100
+		}
+	}
+	s.prev = curr
+	return m
+}
+
+// rate converts a counter difference to a per-second value, tolerating
+// counter resets.
+func rate(curr, prev uint64, elapsed float64) uint64 {
+	if curr < prev {
+		return 0
+	}
+	return uint64(float64(curr-prev) / elapsed)
+}
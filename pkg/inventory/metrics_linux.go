@@ -0,0 +1,140 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sectorSize is the unit /proc/diskstats counts in, regardless of the
+// device's real sector size.
+const sectorSize = 512
+
+// partitionName matches partition entries in /proc/diskstats, which
+// would otherwise double-count their parent device.
+var partitionName = regexp.MustCompile(`^((sd|vd|hd|xvd)[a-z]+\d+|nvme\d+n\d+p\d+|mmcblk\d+p\d+)$`)
+
+// skippedDisks are virtual or removable devices excluded from IO rates.
+var skippedDisks = []string{"loop", "ram", "zram", "sr", "fd", "dm-", "md"}
+
+func readLoadAvg() (load1, load5, load15 float64) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return 0, 0, 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, 0, 0
+	}
+	load1, _ = strconv.ParseFloat(fields[0], 64)
+	load5, _ = strconv.ParseFloat(fields[1], 64)
+	load15, _ = strconv.ParseFloat(fields[2], 64)
+	return load1, load5, load15
+}
+
+func readMemInfoPortable() MemoryInfo {
+	return readMemInfo()
+}
+
+// readMetricCounters snapshots the kernel counters the metrics rates
+// are derived from.
+func readMetricCounters() *metricCounters {
+	c := &metricCounters{at: time.Now()}
+	readCPUCounters(c)
+	readDiskCounters(c)
+	readNetCounters(c)
+	return c
+}
+
+// readCPUCounters sums the aggregate cpu line of /proc/stat; busy is
+// everything except idle and iowait.
+func readCPUCounters(c *metricCounters) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				break
+			}
+			c.cpuTotal += v
+			// Fields 4 and 5 (0-based 3 and 4) are idle and iowait.
+			if i != 3 && i != 4 {
+				c.cpuBusy += v
+			}
+		}
+		return
+	}
+}
+
+func readDiskCounters(c *metricCounters) {
+	f, err := os.Open(filepath.Join(procRoot, "diskstats"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		name := fields[2]
+		if partitionName.MatchString(name) || skippedDisk(name) {
+			continue
+		}
+		read, _ := strconv.ParseUint(fields[5], 10, 64)
+		written, _ := strconv.ParseUint(fields[9], 10, 64)
+		c.diskRead += read * sectorSize
+		c.diskWrite += written * sectorSize
+	}
+}
+
+func skippedDisk(name string) bool {
+	for _, prefix := range skippedDisks {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func readNetCounters(c *metricCounters) {
+	f, err := os.Open(filepath.Join(procRoot, "net", "dev"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		name, rest, found := strings.Cut(sc.Text(), ":")
+		if !found {
+			continue
+		}
+		if strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 9 {
+			continue
+		}
+		rx, _ := strconv.ParseUint(fields[0], 10, 64)
+		tx, _ := strconv.ParseUint(fields[8], 10, 64)
+		c.netRx += rx
+		c.netTx += tx
+	}
+}
@@ -0,0 +1,90 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeMetricsFixture(t *testing.T, root string, stat, diskstats, netdev string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"stat":      stat,
+		"diskstats": diskstats,
+		"loadavg":   "0.50 0.40 0.30 1/234 5678\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "net", "dev"), []byte(netdev), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMetricsSamplerRates(t *testing.T) {
+	old := procRoot
+	procRoot = t.TempDir()
+	defer func() { procRoot = old }()
+
+	netdevHeader := "Inter-|   Receive\n face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n"
+	writeMetricsFixture(t, procRoot,
+		"cpu  100 0 100 800 0 0 0 0 0 0\n",
+		"   8  0 sda 100 0 1000 50 200 0 2000 80 0 0 0\n"+
+			"   8  1 sda1 100 0 1000 50 200 0 2000 80 0 0 0\n"+
+			"   7  0 loop0 5 0 50 1 0 0 0 0 0 0 0\n",
+		netdevHeader+
+			"    lo:  999999    100    0    0    0     0          0         0   999999    100    0    0    0     0       0          0\n"+
+			"  eth0: 1000000    500    0    0    0     0          0         0   500000    400    0    0    0     0       0          0\n")
+
+	s := NewMetricsSampler()
+	// Rewrite the counters as if time had passed and work had happened.
+	writeMetricsFixture(t, procRoot,
+		"cpu  200 0 200 900 0 0 0 0 0 0\n",
+		"   8  0 sda 100 0 3000 50 200 0 6000 80 0 0 0\n",
+		netdevHeader+
+			"  eth0: 2000000    900    0    0    0     0          0         0   1500000    700    0    0    0     0       0          0\n")
+	s.prev.at = s.prev.at.Add(-time.Second)
+
+	m := s.Sample()
+	if m.Load1 != 0.5 || m.Load5 != 0.4 || m.Load15 != 0.3 {
+		t.Errorf("load = %v %v %v", m.Load1, m.Load5, m.Load15)
+	}
+	// Busy went 200->400 of total 1000->1300: 200/300.
+	if m.CPUPercent < 66 || m.CPUPercent > 67 {
+		t.Errorf("CPUPercent = %v", m.CPUPercent)
+	}
+	if m.DiskReadBytesPerSec == 0 || m.DiskWriteBytesPerSec == 0 {
+		t.Errorf("disk rates = %d %d", m.DiskReadBytesPerSec, m.DiskWriteBytesPerSec)
+	}
+	if m.NetRxBytesPerSec == 0 || m.NetTxBytesPerSec == 0 {
+		t.Errorf("net rates = %d %d", m.NetRxBytesPerSec, m.NetTxBytesPerSec)
+	}
+}
+
+func TestReadDiskCountersSkipsPartitionsAndLoop(t *testing.T) {
+	old := procRoot
+	procRoot = t.TempDir()
+	defer func() { procRoot = old }()
+	writeMetricsFixture(t, procRoot, "",
+		"   8  0 sda 100 0 1000 50 200 0 2000 80 0 0 0\n"+
+			"   8  1 sda1 100 0 9999 50 200 0 9999 80 0 0 0\n"+
+			"   7  0 loop0 5 0 9999 1 0 0 9999 0 0 0 0\n"+
+			" 259  0 nvme0n1 10 0 500 5 20 0 700 8 0 0 0\n"+
+			" 259  1 nvme0n1p1 10 0 9999 5 20 0 9999 8 0 0 0\n", "")
+
+	var c metricCounters
+	readDiskCounters(&c)
+	if c.diskRead != (1000+500)*sectorSize {
+		t.Errorf("diskRead = %d", c.diskRead)
+	}
+	if c.diskWrite != (2000+700)*sectorSize {
+		t.Errorf("diskWrite = %d", c.diskWrite)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package inventory
+
+// The metrics counters have no implementation on other platforms;
+// samples carry only the zero gauges.
+
+func readLoadAvg() (load1, load5, load15 float64) { return 0, 0, 0 }
+
+func readMemInfoPortable() MemoryInfo { return MemoryInfo{} }
+
+func readMetricCounters() *metricCounters { return nil }
@@ -0,0 +1,109 @@
+//go:build linux
+
+package inventory
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readNetworkConfig gathers routing, DNS, and NTP configuration.
+// Returns nil only when nothing at all is readable.
+func readNetworkConfig() *NetworkConfig {
+	nc := &NetworkConfig{
+		DefaultRoutes: readDefaultRoutes(),
+	}
+	if data, err := os.ReadFile(filepath.Join(etcRoot, "resolv.conf")); err == nil {
+		nc.DNSServers, nc.SearchDomains = parseResolvConf(string(data))
+	}
+	nc.NTPServers = readNTPServers()
+	if len(nc.DefaultRoutes) == 0 && len(nc.DNSServers) == 0 &&
+		len(nc.SearchDomains) == 0 && len(nc.NTPServers) == 0 {
+		return nil
+	}
+	return nc
+}
+
+// readDefaultRoutes parses /proc/net/route for destination 00000000
+// entries. The gateway is hex in little-endian byte order.
+func readDefaultRoutes() []RouteInfo {
+	data, err := os.ReadFile(filepath.Join(procRoot, "net", "route"))
+	if err != nil {
+		return nil
+	}
+	var routes []RouteInfo
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines[1:] { // first line is the header
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || gw == 0 {
+			continue
+		}
+		ip := net.IPv4(byte(gw), byte(gw>>8), byte(gw>>16), byte(gw>>24))
+		routes = append(routes, RouteInfo{Interface: fields[0], Gateway: ip.String()})
+	}
+	return routes
+}
+
+func parseResolvConf(data string) (servers, search []string) {
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			servers = append(servers, fields[1])
+		case "search", "domain":
+			search = append(search, fields[1:]...)
+		}
+	}
+	return servers, search
+}
+
+// ntpConfigFiles are checked in order; chrony and ntpd use server/pool
+// directives, timesyncd uses NTP=/FallbackNTP= assignments.
+var ntpConfigFiles = []string{
+	"chrony.conf",
+	"chrony/chrony.conf",
+	"ntp.conf",
+	"systemd/timesyncd.conf",
+}
+
+func readNTPServers() []string {
+	for _, name := range ntpConfigFiles {
+		data, err := os.ReadFile(filepath.Join(etcRoot, name))
+		if err != nil {
+			continue
+		}
+		if servers := parseNTPConf(string(data)); servers != nil {
+			return servers
+		}
+	}
+	return nil
+}
+
+func parseNTPConf(data string) []string {
+	var servers []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) >= 2 &&
+			(fields[0] == "server" || fields[0] == "pool") {
+			servers = append(servers, fields[1])
+			continue
+		}
+		if val, ok := strings.CutPrefix(line, "NTP="); ok {
+			servers = append(servers, strings.Fields(val)...)
+		}
+	}
+	return servers
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDefaultRoutes(t *testing.T) {
+	old := procRoot
+	procRoot = t.TempDir()
+	defer func() { procRoot = old }()
+
+	if err := os.MkdirAll(filepath.Join(procRoot, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	route := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\n" +
+		"eth0\t00000000\t0101A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n" +
+		"eth0\t0000FEA9\t00000000\t0001\t0\t0\t1000\t0000FFFF\t0\t0\t0\n"
+	if err := os.WriteFile(filepath.Join(procRoot, "net", "route"), []byte(route), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := readDefaultRoutes()
+	if len(routes) != 1 {
+		t.Fatalf("got %d routes, want 1", len(routes))
+	}
+	if routes[0] != (RouteInfo{Interface: "eth0", Gateway: "192.168.1.1"}) {
+		t.Errorf("route = %+v", routes[0])
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	servers, search := parseResolvConf(`# comment
+nameserver 10.0.0.2
+nameserver 10.0.0.3
+search corp.example.com lab.example.com
+`)
+	if len(servers) != 2 || servers[0] != "10.0.0.2" {
+		t.Errorf("servers = %v", servers)
+	}
+	if len(search) != 2 || search[1] != "lab.example.com" {
+		t.Errorf("search = %v", search)
+	}
+}
+
+func TestParseNTPConf(t *testing.T) {
+	chrony := parseNTPConf("pool 2.pool.ntp.org iburst\nserver ntp1.corp iburst\n# server commented\n")
+	if len(chrony) != 2 || chrony[0] != "2.pool.ntp.org" || chrony[1] != "ntp1.corp" {
+		t.Errorf("chrony = %v", chrony)
+	}
+	timesyncd := parseNTPConf("[Time]\nNTP=ntp1.corp ntp2.corp\n")
+	if len(timesyncd) != 2 || timesyncd[1] != "ntp2.corp" {
+		t.Errorf("timesyncd = %v", timesyncd)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// classNetRoot is a variable so tests can point the topology reader at
+// a fixture tree.
+var classNetRoot = "/sys/class/net"
+
+// enrichNetworkTopology annotates the flat interface list with bond,
+// bridge, and VLAN relationships from sysfs.
+func enrichNetworkTopology(ifaces []NetworkInfo) {
+	vlans := readVLANConfig()
+	for i := range ifaces {
+		ni := &ifaces[i]
+		dir := filepath.Join(classNetRoot, ni.Name)
+		if mode := readTrimmed(filepath.Join(dir, "bonding", "mode")); mode != "" {
+			ni.Kind = "bond"
+			// The mode file reads "802.3ad 4"; keep the name.
+			ni.BondMode, _, _ = strings.Cut(mode, " ")
+		} else if _, err := os.Stat(filepath.Join(dir, "bridge")); err == nil {
+			ni.Kind = "bridge"
+		}
+		if v, ok := vlans[ni.Name]; ok {
+			ni.Kind = "vlan"
+			ni.VLANParent = v.parent
+			ni.VLANID = v.id
+		}
+		// master points at the enslaving bond or bridge device.
+		if target, err := os.Readlink(filepath.Join(dir, "master")); err == nil {
+			ni.Master = filepath.Base(target)
+		}
+	}
+}
+
+type vlanEntry struct {
+	parent string
+	id     int
+}
+
+// readVLANConfig parses /proc/net/vlan/config, whose data lines look
+// like "eth0.100  | 100  | eth0".
+func readVLANConfig() map[string]vlanEntry {
+	f, err := os.Open(filepath.Join(procRoot, "net", "vlan", "config"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	out := map[string]vlanEntry{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.Split(sc.Text(), "|")
+		if len(parts) != 3 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		id, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			continue
+		}
+		out[name] = vlanEntry{parent: strings.TrimSpace(parts[2]), id: id}
+	}
+	return out
+}
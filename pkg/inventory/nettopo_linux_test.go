@@ -0,0 +1,63 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnrichNetworkTopology(t *testing.T) {
+	oldNet, oldProc := classNetRoot, procRoot
+	classNetRoot = t.TempDir()
+	procRoot = t.TempDir()
+	defer func() { classNetRoot, procRoot = oldNet, oldProc }()
+
+	mkdir := func(parts ...string) string {
+		t.Helper()
+		dir := filepath.Join(parts...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	bond := mkdir(classNetRoot, "bond0", "bonding")
+	if err := os.WriteFile(filepath.Join(bond, "mode"), []byte("802.3ad 4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mkdir(classNetRoot, "br0", "bridge")
+	eth0 := mkdir(classNetRoot, "eth0")
+	if err := os.Symlink("../bond0", filepath.Join(eth0, "master")); err != nil {
+		t.Fatal(err)
+	}
+	mkdir(classNetRoot, "eth1.100")
+
+	vlanDir := mkdir(procRoot, "net", "vlan")
+	vlanConfig := "VLAN Dev name\t | VLAN ID\nName-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD\neth1.100       | 100  | eth1\n"
+	if err := os.WriteFile(filepath.Join(vlanDir, "config"), []byte(vlanConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ifaces := []NetworkInfo{
+		{Name: "bond0"},
+		{Name: "br0"},
+		{Name: "eth0"},
+		{Name: "eth1.100"},
+	}
+	enrichNetworkTopology(ifaces)
+
+	if ifaces[0].Kind != "bond" || ifaces[0].BondMode != "802.3ad" {
+		t.Errorf("bond0 = %+v", ifaces[0])
+	}
+	if ifaces[1].Kind != "bridge" {
+		t.Errorf("br0 = %+v", ifaces[1])
+	}
+	if ifaces[2].Master != "bond0" {
+		t.Errorf("eth0 = %+v", ifaces[2])
+	}
+	if ifaces[3].Kind != "vlan" || ifaces[3].VLANParent != "eth1" || ifaces[3].VLANID != 100 {
+		t.Errorf("eth1.100 = %+v", ifaces[3])
+	}
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Roots are variables so tests can point the readers at fixtures.
+var (
+	numaRoot     = "/sys/devices/system/node"
+	hugepageRoot = "/sys/kernel/mm/hugepages"
+)
+
+// readNUMA reports NUMA topology and hugepage configuration. Returns
+// nil when the sysfs node tree is absent (non-NUMA kernels).
+func readNUMA() *NUMATopology {
+	entries, err := os.ReadDir(numaRoot)
+	if err != nil {
+		return nil
+	}
+	topo := &NUMATopology{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "node") {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(name, "node"))
+		if err != nil {
+			continue
+		}
+		node := NUMANode{ID: id}
+		dir := filepath.Join(numaRoot, name)
+		node.MemoryTotal, node.MemoryFree = readNodeMeminfo(filepath.Join(dir, "meminfo"))
+		if data, err := os.ReadFile(filepath.Join(dir, "cpulist")); err == nil {
+			node.CPUs = parseCPUList(strings.TrimSpace(string(data)))
+		}
+		topo.Nodes = append(topo.Nodes, node)
+	}
+	if len(topo.Nodes) == 0 {
+		return nil
+	}
+	sort.Slice(topo.Nodes, func(i, j int) bool { return topo.Nodes[i].ID < topo.Nodes[j].ID })
+	topo.Hugepages = readHugepages()
+	return topo
+}
+
+// readNodeMeminfo extracts MemTotal and MemFree (bytes) from a per-node
+// meminfo file, whose lines look like "Node 0 MemTotal: 16384 kB".
+func readNodeMeminfo(path string) (total, free uint64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[2] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemFree:":
+			free = kb * 1024
+		}
+	}
+	return total, free
+}
+
+// parseCPUList expands a kernel cpulist like "0-3,8-11,15" into CPU
+// numbers.
+func parseCPUList(s string) []int {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			out = append(out, cpu)
+		}
+	}
+	return out
+}
+
+// readHugepages reports the configured hugepage pools per page size.
+func readHugepages() []HugepageInfo {
+	entries, err := os.ReadDir(hugepageRoot)
+	if err != nil {
+		return nil
+	}
+	var out []HugepageInfo
+	for _, e := range entries {
+		name := e.Name()
+		sizeStr, ok := strings.CutPrefix(name, "hugepages-")
+		if !ok {
+			continue
+		}
+		sizeKB, err := strconv.Atoi(strings.TrimSuffix(sizeStr, "kB"))
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(hugepageRoot, name)
+		out = append(out, HugepageInfo{
+			SizeKB: sizeKB,
+			Total:  readIntFile(filepath.Join(dir, "nr_hugepages")),
+			Free:   readIntFile(filepath.Join(dir, "free_hugepages")),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SizeKB < out[j].SizeKB })
+	return out
+}
+
+func readIntFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return n
+}
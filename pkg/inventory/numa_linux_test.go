@@ -0,0 +1,81 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeNUMAFixture(t *testing.T, dir, node, meminfo, cpulist string) {
+	t.Helper()
+	d := filepath.Join(dir, node)
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d, "meminfo"), []byte(meminfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d, "cpulist"), []byte(cpulist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadNUMA(t *testing.T) {
+	oldNode, oldHuge := numaRoot, hugepageRoot
+	numaRoot = t.TempDir()
+	hugepageRoot = filepath.Join(numaRoot, "hugepages")
+	defer func() { numaRoot, hugepageRoot = oldNode, oldHuge }()
+
+	writeNUMAFixture(t, numaRoot, "node0",
+		"Node 0 MemTotal:       16384 kB\nNode 0 MemFree:         8192 kB\n", "0-3\n")
+	writeNUMAFixture(t, numaRoot, "node1",
+		"Node 1 MemTotal:       16384 kB\nNode 1 MemFree:         4096 kB\n", "4-7\n")
+	huge := filepath.Join(hugepageRoot, "hugepages-2048kB")
+	if err := os.MkdirAll(huge, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(huge, "nr_hugepages"), []byte("512\n"), 0o644)
+	os.WriteFile(filepath.Join(huge, "free_hugepages"), []byte("500\n"), 0o644)
+
+	topo := readNUMA()
+	if topo == nil {
+		t.Fatal("readNUMA returned nil")
+	}
+	if len(topo.Nodes) != 2 {
+		t.Fatalf("nodes = %d", len(topo.Nodes))
+	}
+	n0 := topo.Nodes[0]
+	if n0.ID != 0 || n0.MemoryTotal != 16384*1024 || n0.MemoryFree != 8192*1024 {
+		t.Errorf("node0 = %+v", n0)
+	}
+	if !reflect.DeepEqual(n0.CPUs, []int{0, 1, 2, 3}) {
+		t.Errorf("node0 CPUs = %v", n0.CPUs)
+	}
+	if len(topo.Hugepages) != 1 {
+		t.Fatalf("hugepages = %+v", topo.Hugepages)
+	}
+	hp := topo.Hugepages[0]
+	if hp.SizeKB != 2048 || hp.Total != 512 || hp.Free != 500 {
+		t.Errorf("hugepage = %+v", hp)
+	}
+}
+
+func TestReadNUMAAbsent(t *testing.T) {
+	old := numaRoot
+	numaRoot = filepath.Join(t.TempDir(), "missing")
+	defer func() { numaRoot = old }()
+	if topo := readNUMA(); topo != nil {
+		t.Errorf("readNUMA = %+v, want nil", topo)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	got := parseCPUList("0-2,8,10-11")
+	want := []int{0, 1, 2, 8, 10, 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCPUList = %v, want %v", got, want)
+	}
+}
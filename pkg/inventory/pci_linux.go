@@ -0,0 +1,101 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pciSysfsRoot is a variable so tests can point the walker at a fixture
+// tree.
+var pciSysfsRoot = "/sys/bus/pci/devices"
+
+// readPCIDevices enumerates the PCI bus from sysfs, falling back to
+// lspci on systems where /sys/bus/pci is unavailable (some containers
+// and minimal kernels).
+func readPCIDevices() []PCIDevice {
+	if devs := readPCISysfs(); devs != nil {
+		return devs
+	}
+	return readPCILspci()
+}
+
+func readPCISysfs() []PCIDevice {
+	entries, err := os.ReadDir(pciSysfsRoot)
+	if err != nil {
+		return nil
+	}
+	var out []PCIDevice
+	for _, e := range entries {
+		dir := filepath.Join(pciSysfsRoot, e.Name())
+		dev := PCIDevice{
+			Address:  e.Name(),
+			VendorID: readHexID(filepath.Join(dir, "vendor")),
+			DeviceID: readHexID(filepath.Join(dir, "device")),
+			Class:    readHexID(filepath.Join(dir, "class")),
+			NUMANode: -1,
+		}
+		if dev.VendorID == "" && dev.DeviceID == "" {
+			continue
+		}
+		if target, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			dev.Driver = filepath.Base(target)
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, "numa_node")); err == nil {
+			if n, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				dev.NUMANode = n
+			}
+		}
+		out = append(out, dev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// readHexID reads a sysfs attribute like "0x8086\n" and strips the 0x
+// prefix.
+func readHexID(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+}
+
+// readPCILspci parses `lspci -n` output, one device per line:
+//
+//	00:1f.2 0106: 8086:a102 (rev 31)
+func readPCILspci() []PCIDevice {
+	data, err := exec.Command("lspci", "-n").Output()
+	if err != nil {
+		return nil
+	}
+	return parseLspci(string(data))
+}
+
+func parseLspci(out string) []PCIDevice {
+	var devs []PCIDevice
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		vendor, device, found := strings.Cut(fields[2], ":")
+		if !found {
+			continue
+		}
+		devs = append(devs, PCIDevice{
+			Address:  fields[0],
+			Class:    strings.TrimSuffix(fields[1], ":"),
+			VendorID: vendor,
+			DeviceID: device,
+			NUMANode: -1,
+		})
+	}
+	return devs
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPCISysfs(t *testing.T) {
+	root := t.TempDir()
+	dev := filepath.Join(root, "0000:3b:00.0")
+	if err := os.Mkdir(dev, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writes := map[string]string{
+		"vendor":    "0x8086\n",
+		"device":    "0x1572\n",
+		"class":     "0x020000\n",
+		"numa_node": "1\n",
+	}
+	for name, content := range writes {
+		if err := os.WriteFile(filepath.Join(dev, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../../../bus/pci/drivers/i40e", filepath.Join(dev, "driver")); err != nil {
+		t.Fatal(err)
+	}
+
+	old := pciSysfsRoot
+	pciSysfsRoot = root
+	defer func() { pciSysfsRoot = old }()
+
+	devs := readPCISysfs()
+	if len(devs) != 1 {
+		t.Fatalf("got %d devices, want 1", len(devs))
+	}
+	got := devs[0]
+	want := PCIDevice{
+		Address:  "0000:3b:00.0",
+		VendorID: "8086",
+		DeviceID: "1572",
+		Class:    "020000",
+		Driver:   "i40e",
+		NUMANode: 1,
+	}
+	if got != want {
+		t.Errorf("device = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseLspci(t *testing.T) {
+	out := "00:1f.2 0106: 8086:a102 (rev 31)\n3b:00.0 0200: 8086:1572\n\n"
+	devs := parseLspci(out)
+	if len(devs) != 2 {
+		t.Fatalf("got %d devices, want 2", len(devs))
+	}
+	if devs[1].Address != "3b:00.0" || devs[1].Class != "0200" ||
+		devs[1].VendorID != "8086" || devs[1].DeviceID != "1572" {
+		t.Errorf("device = %+v", devs[1])
+	}
+	if devs[0].NUMANode != -1 {
+		t.Errorf("NUMANode = %d, want -1 for lspci fallback", devs[0].NUMANode)
+	}
+}
@@ -0,0 +1,202 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Endpoints and roots are variables so tests can substitute fixtures
+// and local listeners.
+var (
+	powerSupplyRoot = "/sys/class/power_supply"
+	nutAddr         = "127.0.0.1:3493"
+	apcupsdAddr     = "127.0.0.1:3551"
+)
+
+// upsTimeout bounds each daemon conversation; both daemons are local,
+// so anything slower is effectively down.
+const upsTimeout = 2 * time.Second
+
+// readBatteries reports batteries from the power_supply class.
+func readBatteries() []BatteryInfo {
+	entries, err := os.ReadDir(powerSupplyRoot)
+	if err != nil {
+		return nil
+	}
+	var out []BatteryInfo
+	for _, e := range entries {
+		dir := filepath.Join(powerSupplyRoot, e.Name())
+		if readTrimmed(filepath.Join(dir, "type")) != "Battery" {
+			continue
+		}
+		b := BatteryInfo{
+			Name:   e.Name(),
+			Status: readTrimmed(filepath.Join(dir, "status")),
+		}
+		b.ChargePercent, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "capacity")))
+		b.CycleCount, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "cycle_count")))
+		out = append(out, b)
+	}
+	return out
+}
+
+// readUPS queries whichever UPS daemon answers locally. NUT is tried
+// first; apcupsd only when NUT reports nothing, so dual installs do
+// not double-report.
+func readUPS() []UPSInfo {
+	if ups := queryNUT(); len(ups) > 0 {
+		return ups
+	}
+	return queryApcupsd()
+}
+
+// queryNUT speaks the NUT text protocol to a local upsd.
+func queryNUT() []UPSInfo {
+	conn, err := net.DialTimeout("tcp", nutAddr, upsTimeout)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(upsTimeout))
+	r := bufio.NewReader(conn)
+	var out []UPSInfo
+	for _, name := range nutListUPS(conn, r) {
+		ups := UPSInfo{Name: name, Source: "nut"}
+		ups.Status = nutVar(conn, r, name, "ups.status")
+		ups.ChargePercent, _ = strconv.Atoi(nutVar(conn, r, name, "battery.charge"))
+		ups.RuntimeSeconds, _ = strconv.Atoi(nutVar(conn, r, name, "battery.runtime"))
+		out = append(out, ups)
+	}
+	return out
+}
+
+// nutListUPS runs LIST UPS and returns the configured UPS names.
+func nutListUPS(w io.Writer, r *bufio.Reader) []string {
+	if _, err := fmt.Fprintf(w, "LIST UPS\n"); err != nil {
+		return nil
+	}
+	var names []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "UPS "):
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				names = append(names, fields[1])
+			}
+		case strings.HasPrefix(line, "END LIST UPS"):
+			return names
+		case strings.HasPrefix(line, "ERR"):
+			return nil
+		}
+	}
+}
+
+// nutVar fetches one variable, e.g. `GET VAR myups battery.charge` ->
+// `VAR myups battery.charge "100"`.
+func nutVar(w io.Writer, r *bufio.Reader, ups, name string) string {
+	if _, err := fmt.Fprintf(w, "GET VAR %s %s\n", ups, name); err != nil {
+		return ""
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return ""
+	}
+	_, val, found := strings.Cut(line, `"`)
+	if !found {
+		return ""
+	}
+	val, _, _ = strings.Cut(val, `"`)
+	return val
+}
+
+// queryApcupsd speaks the apcupsd NIS protocol: length-prefixed
+// frames, a "status" request, then "KEY : value" lines until an empty
+// frame.
+func queryApcupsd() []UPSInfo {
+	conn, err := net.DialTimeout("tcp", apcupsdAddr, upsTimeout)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(upsTimeout))
+	if err := writeNISFrame(conn, "status"); err != nil {
+		return nil
+	}
+	ups := UPSInfo{Name: "apcupsd", Source: "apcupsd"}
+	for {
+		line, err := readNISFrame(conn)
+		if err != nil || line == "" {
+			break
+		}
+		key, val, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch key {
+		case "UPSNAME":
+			if val != "" {
+				ups.Name = val
+			}
+		case "STATUS":
+			ups.Status = val
+		case "BCHARGE":
+			// "100.0 Percent"
+			pct, _, _ := strings.Cut(val, " ")
+			if f, err := strconv.ParseFloat(pct, 64); err == nil {
+				ups.ChargePercent = int(f)
+			}
+		case "TIMELEFT":
+			// "44.0 Minutes"
+			mins, _, _ := strings.Cut(val, " ")
+			if f, err := strconv.ParseFloat(mins, 64); err == nil {
+				ups.RuntimeSeconds = int(f * 60)
+			}
+		}
+	}
+	if ups.Status == "" && ups.ChargePercent == 0 {
+		return nil
+	}
+	return []UPSInfo{ups}
+}
+
+func writeNISFrame(w io.Writer, s string) error {
+	var size [2]byte
+	binary.BigEndian.PutUint16(size[:], uint16(len(s)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readNISFrame(r io.Reader) (string, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return "", err
+	}
+	n := binary.BigEndian.Uint16(size[:])
+	if n == 0 {
+		return "", nil
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,131 @@
+//go:build linux
+
+package inventory
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadBatteries(t *testing.T) {
+	old := powerSupplyRoot
+	powerSupplyRoot = t.TempDir()
+	defer func() { powerSupplyRoot = old }()
+
+	write := func(dir, name, val string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bat := filepath.Join(powerSupplyRoot, "BAT0")
+	if err := os.MkdirAll(bat, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(bat, "type", "Battery")
+	write(bat, "status", "Discharging")
+	write(bat, "capacity", "73")
+	write(bat, "cycle_count", "312")
+	// AC adapters must not show up as batteries.
+	ac := filepath.Join(powerSupplyRoot, "AC")
+	if err := os.MkdirAll(ac, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(ac, "type", "Mains")
+
+	got := readBatteries()
+	if len(got) != 1 {
+		t.Fatalf("batteries = %+v", got)
+	}
+	b := got[0]
+	if b.Name != "BAT0" || b.Status != "Discharging" || b.ChargePercent != 73 || b.CycleCount != 312 {
+		t.Errorf("battery = %+v", b)
+	}
+}
+
+func TestQueryNUT(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			switch line := sc.Text(); {
+			case line == "LIST UPS":
+				conn.Write([]byte("BEGIN LIST UPS\nUPS myups \"Office UPS\"\nEND LIST UPS\n"))
+			case strings.HasPrefix(line, "GET VAR myups ups.status"):
+				conn.Write([]byte("VAR myups ups.status \"OL\"\n"))
+			case strings.HasPrefix(line, "GET VAR myups battery.charge"):
+				conn.Write([]byte("VAR myups battery.charge \"100\"\n"))
+			case strings.HasPrefix(line, "GET VAR myups battery.runtime"):
+				conn.Write([]byte("VAR myups battery.runtime \"2640\"\n"))
+			}
+		}
+	}()
+
+	old := nutAddr
+	nutAddr = ln.Addr().String()
+	defer func() { nutAddr = old }()
+
+	got := queryNUT()
+	if len(got) != 1 {
+		t.Fatalf("ups = %+v", got)
+	}
+	u := got[0]
+	if u.Name != "myups" || u.Source != "nut" || u.Status != "OL" ||
+		u.ChargePercent != 100 || u.RuntimeSeconds != 2640 {
+		t.Errorf("ups = %+v", u)
+	}
+}
+
+func TestQueryApcupsd(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if req, err := readNISFrame(conn); err != nil || req != "status" {
+			return
+		}
+		for _, line := range []string{
+			"UPSNAME  : rack-ups",
+			"STATUS   : ONLINE",
+			"BCHARGE  : 98.0 Percent",
+			"TIMELEFT : 44.5 Minutes",
+		} {
+			writeNISFrame(conn, line)
+		}
+		writeNISFrame(conn, "")
+	}()
+
+	old := apcupsdAddr
+	apcupsdAddr = ln.Addr().String()
+	defer func() { apcupsdAddr = old }()
+
+	got := queryApcupsd()
+	if len(got) != 1 {
+		t.Fatalf("ups = %+v", got)
+	}
+	u := got[0]
+	if u.Name != "rack-ups" || u.Source != "apcupsd" || u.Status != "ONLINE" ||
+		u.ChargePercent != 98 || u.RuntimeSeconds != 2670 {
+		t.Errorf("ups = %+v", u)
+	}
+}
@@ -0,0 +1,180 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// topProcessCount bounds how many processes are reported per sort
+	// key (CPU and memory), keeping the payload size-capped.
+	topProcessCount = 10
+	// maxCmdlineLen truncates long command lines.
+	maxCmdlineLen = 120
+	// clockTicks is the kernel's USER_HZ; fixed at 100 on every
+	// supported Linux architecture.
+	clockTicks = 100
+)
+
+// procRoot is a variable so tests can point the scanner at a fixture
+// tree.
+var procRoot = "/proc"
+
+// readProcesses reports the top processes by CPU and by memory, merged
+// and deduplicated. Collection is a single pass over /proc; CPU usage
+// is the lifetime average, which is what matters for spotting a process
+// that has been burning a core for an hour.
+func readProcesses() []ProcessInfo {
+	procs := scanProcesses()
+	return topProcesses(procs, topProcessCount)
+}
+
+func scanProcesses() []ProcessInfo {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+	btime := readBootTime()
+	now := nowUnix()
+	var out []ProcessInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if p, ok := readProcess(pid, btime, now); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// nowUnix is overridable in tests so CPU percentages are deterministic.
+var nowUnix = func() int64 {
+	var tv syscall.Timeval
+	syscall.Gettimeofday(&tv)
+	return int64(tv.Sec)
+}
+
+func readProcess(pid int, btime, now int64) (ProcessInfo, bool) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return ProcessInfo{}, false
+	}
+	// The comm field is parenthesized and may contain spaces; fields
+	// are counted from after the closing parenthesis.
+	rest := string(stat)
+	if i := strings.LastIndexByte(rest, ')'); i >= 0 {
+		rest = rest[i+1:]
+	}
+	fields := strings.Fields(rest)
+	// Fields (0-based after comm): 11=utime 12=stime 19=starttime 21=rss.
+	if len(fields) < 22 {
+		return ProcessInfo{}, false
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	starttime, _ := strconv.ParseUint(fields[19], 10, 64)
+	rssPages, _ := strconv.ParseUint(fields[21], 10, 64)
+
+	p := ProcessInfo{
+		PID: pid,
+		RSS: rssPages * uint64(os.Getpagesize()),
+	}
+	startedAt := btime + int64(starttime)/clockTicks
+	if elapsed := now - startedAt; elapsed > 0 {
+		cpuSecs := float64(utime+stime) / clockTicks
+		p.CPUPercent = cpuSecs / float64(elapsed) * 100
+	}
+	p.Cmdline = readCmdline(dir)
+	if p.Cmdline == "" {
+		// Kernel threads have no cmdline; skip them.
+		return ProcessInfo{}, false
+	}
+	p.User = processUser(dir)
+	return p, true
+}
+
+func readCmdline(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	cmdline := strings.TrimRight(strings.ReplaceAll(string(data), "\x00", " "), " ")
+	if len(cmdline) > maxCmdlineLen {
+		cmdline = cmdline[:maxCmdlineLen]
+	}
+	return cmdline
+}
+
+func processUser(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return ""
+		}
+		if u, err := user.LookupId(fields[1]); err == nil {
+			return u.Username
+		}
+		return fields[1]
+	}
+	return ""
+}
+
+func readBootTime() int64 {
+	data, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "btime" {
+			t, _ := strconv.ParseInt(fields[1], 10, 64)
+			return t
+		}
+	}
+	return 0
+}
+
+// topProcesses merges the top n processes by CPU and the top n by RSS,
+// deduplicated, ordered by CPU descending.
+func topProcesses(procs []ProcessInfo, n int) []ProcessInfo {
+	if len(procs) == 0 {
+		return nil
+	}
+	byCPU := make([]ProcessInfo, len(procs))
+	copy(byCPU, procs)
+	sort.Slice(byCPU, func(i, j int) bool { return byCPU[i].CPUPercent > byCPU[j].CPUPercent })
+	byRSS := make([]ProcessInfo, len(procs))
+	copy(byRSS, procs)
+	sort.Slice(byRSS, func(i, j int) bool { return byRSS[i].RSS > byRSS[j].RSS })
+
+	seen := make(map[int]bool)
+	var out []ProcessInfo
+	keep := func(list []ProcessInfo) {
+		for i := 0; i < n && i < len(list); i++ {
+			if !seen[list[i].PID] {
+				seen[list[i].PID] = true
+				out = append(out, list[i])
+			}
+		}
+	}
+	keep(byCPU)
+	keep(byRSS)
+	sort.Slice(out, func(i, j int) bool { return out[i].CPUPercent > out[j].CPUPercent })
+	return out
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeProcFixture(t *testing.T, pid int, utime uint64, rssPages uint64, cmdline string) {
+	t.Helper()
+	dir := filepath.Join(procRoot, fmt.Sprint(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// starttime 0 ticks, i.e. started at boot.
+	stat := fmt.Sprintf("%d (comm with spaces) S 1 1 1 0 -1 0 0 0 0 0 %d 0 0 0 20 0 1 0 0 0 %d 0 0 0",
+		pid, utime, rssPages)
+	files := map[string]string{
+		"stat":    stat,
+		"cmdline": strings.ReplaceAll(cmdline, " ", "\x00"),
+		"status":  "Name:\tx\nUid:\t0\t0\t0\t0\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanProcesses(t *testing.T) {
+	oldRoot, oldNow := procRoot, nowUnix
+	procRoot = t.TempDir()
+	defer func() { procRoot, nowUnix = oldRoot, oldNow }()
+
+	// Boot at t=1000, sampled at t=1100: 100s of wall clock.
+	if err := os.WriteFile(filepath.Join(procRoot, "stat"), []byte("cpu 0 0 0 0\nbtime 1000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nowUnix = func() int64 { return 1100 }
+
+	// 5000 ticks = 50s of CPU over 100s elapsed = 50%.
+	writeProcFixture(t, 42, 5000, 100, "/usr/bin/hog --threads 8")
+	writeProcFixture(t, 43, 100, 9000, "/usr/bin/bigmem")
+
+	procs := scanProcesses()
+	if len(procs) != 2 {
+		t.Fatalf("got %d processes, want 2", len(procs))
+	}
+	var hog ProcessInfo
+	for _, p := range procs {
+		if p.PID == 42 {
+			hog = p
+		}
+	}
+	if hog.CPUPercent < 49 || hog.CPUPercent > 51 {
+		t.Errorf("CPUPercent = %v, want ~50", hog.CPUPercent)
+	}
+	if hog.Cmdline != "/usr/bin/hog --threads 8" {
+		t.Errorf("Cmdline = %q", hog.Cmdline)
+	}
+	if hog.RSS != 100*uint64(os.Getpagesize()) {
+		t.Errorf("RSS = %d", hog.RSS)
+	}
+}
+
+func TestTopProcessesMergesCPUAndRSS(t *testing.T) {
+	var procs []ProcessInfo
+	for i := 0; i < 30; i++ {
+		procs = append(procs, ProcessInfo{
+			PID:        i,
+			CPUPercent: float64(i),       // high PIDs are CPU-hot
+			RSS:        uint64(1000 - i), // low PIDs are memory-hot
+		})
+	}
+	top := topProcesses(procs, 5)
+	if len(top) != 10 {
+		t.Fatalf("got %d processes, want 10 (5 by CPU + 5 by RSS)", len(top))
+	}
+	if top[0].PID != 29 {
+		t.Errorf("hottest process = %d, want 29", top[0].PID)
+	}
+	seen := map[int]bool{}
+	for _, p := range top {
+		if seen[p.PID] {
+			t.Errorf("duplicate pid %d", p.PID)
+		}
+		seen[p.PID] = true
+	}
+	if !seen[0] {
+		t.Error("top memory process not included")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hwmonRoot is a variable so tests can point readSensors at a fixture
+// tree.
+var hwmonRoot = "/sys/class/hwmon"
+
+// readSensors reports hwmon temperature, fan, and power readings.
+// Returns nil when no chips expose any, which is common in VMs.
+func readSensors() *SensorReadings {
+	entries, err := os.ReadDir(hwmonRoot)
+	if err != nil {
+		return nil
+	}
+	sensors := &SensorReadings{}
+	for _, e := range entries {
+		dir := filepath.Join(hwmonRoot, e.Name())
+		chip := readTrimmed(filepath.Join(dir, "name"))
+		if chip == "" {
+			chip = e.Name()
+		}
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			prefix, ok := strings.CutSuffix(f.Name(), "_input")
+			if !ok {
+				continue
+			}
+			raw, err := strconv.ParseFloat(readTrimmed(filepath.Join(dir, f.Name())), 64)
+			if err != nil {
+				continue
+			}
+			name := chip + ":" + prefix
+			if label := readTrimmed(filepath.Join(dir, prefix+"_label")); label != "" {
+				name = chip + ":" + label
+			}
+			switch {
+			case strings.HasPrefix(prefix, "temp"):
+				// Millidegrees Celsius.
+				sensors.Temperatures = append(sensors.Temperatures,
+					TempInfo{Name: name, Celsius: raw / 1000})
+			case strings.HasPrefix(prefix, "fan"):
+				sensors.Fans = append(sensors.Fans,
+					FanInfo{Name: name, RPM: int(raw)})
+			case strings.HasPrefix(prefix, "power"):
+				// Microwatts.
+				sensors.Power = append(sensors.Power,
+					PowerReading{Name: name, Watts: raw / 1e6})
+			}
+		}
+	}
+	if len(sensors.Temperatures) == 0 && len(sensors.Fans) == 0 && len(sensors.Power) == 0 {
+		return nil
+	}
+	return sensors
+}
+
+// readTrimmed reads a small sysfs attribute, empty on any error.
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
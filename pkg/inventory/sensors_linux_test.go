@@ -0,0 +1,57 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSensors(t *testing.T) {
+	old := hwmonRoot
+	hwmonRoot = t.TempDir()
+	defer func() { hwmonRoot = old }()
+
+	chip := filepath.Join(hwmonRoot, "hwmon0")
+	if err := os.MkdirAll(chip, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, val string) {
+		if err := os.WriteFile(filepath.Join(chip, name), []byte(val+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("name", "coretemp")
+	write("temp1_input", "45500")
+	write("temp1_label", "Package id 0")
+	write("fan1_input", "2400")
+	write("power1_input", "65000000")
+
+	sensors := readSensors()
+	if sensors == nil {
+		t.Fatal("readSensors returned nil")
+	}
+	if len(sensors.Temperatures) != 1 {
+		t.Fatalf("temperatures = %+v", sensors.Temperatures)
+	}
+	temp := sensors.Temperatures[0]
+	if temp.Name != "coretemp:Package id 0" || temp.Celsius != 45.5 {
+		t.Errorf("temp = %+v", temp)
+	}
+	if len(sensors.Fans) != 1 || sensors.Fans[0].RPM != 2400 {
+		t.Errorf("fans = %+v", sensors.Fans)
+	}
+	if len(sensors.Power) != 1 || sensors.Power[0].Watts != 65 {
+		t.Errorf("power = %+v", sensors.Power)
+	}
+}
+
+func TestReadSensorsEmpty(t *testing.T) {
+	old := hwmonRoot
+	hwmonRoot = t.TempDir()
+	defer func() { hwmonRoot = old }()
+	if sensors := readSensors(); sensors != nil {
+		t.Errorf("readSensors = %+v, want nil", sensors)
+	}
+}
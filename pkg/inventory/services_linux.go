@@ -0,0 +1,65 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// readServices lists systemd service units and their enablement. Hosts
+// without systemd (or inside containers) simply report nothing.
+func readServices() []ServiceInfo {
+	units, err := exec.Command("systemctl", "list-units", "--type=service",
+		"--all", "--no-legend", "--plain").Output()
+	if err != nil {
+		return nil
+	}
+	services := parseListUnits(string(units))
+	if files, err := exec.Command("systemctl", "list-unit-files", "--type=service",
+		"--no-legend", "--plain").Output(); err == nil {
+		applyEnablement(services, parseUnitFiles(string(files)))
+	}
+	return services
+}
+
+// parseListUnits parses `systemctl list-units --plain` output:
+//
+//	sshd.service loaded active running OpenSSH server daemon
+func parseListUnits(out string) []ServiceInfo {
+	var services []ServiceInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasSuffix(fields[0], ".service") {
+			continue
+		}
+		services = append(services, ServiceInfo{
+			Name:        fields[0],
+			LoadState:   fields[1],
+			ActiveState: fields[2],
+			SubState:    fields[3],
+		})
+	}
+	return services
+}
+
+// parseUnitFiles parses `systemctl list-unit-files --plain` output:
+//
+//	sshd.service enabled enabled
+func parseUnitFiles(out string) map[string]string {
+	enablement := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], ".service") {
+			continue
+		}
+		enablement[fields[0]] = fields[1]
+	}
+	return enablement
+}
+
+func applyEnablement(services []ServiceInfo, enablement map[string]string) {
+	for i := range services {
+		services[i].Enabled = enablement[services[i].Name]
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package inventory
+
+import "testing"
+
+func TestParseListUnits(t *testing.T) {
+	out := "sshd.service loaded active running OpenSSH server daemon\n" +
+		"cron.service loaded inactive dead Regular background program\n" +
+		"dev-sda1.device loaded active plugged /dev/sda1\n" +
+		"short line\n"
+	services := parseListUnits(out)
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2", len(services))
+	}
+	want := ServiceInfo{Name: "sshd.service", LoadState: "loaded",
+		ActiveState: "active", SubState: "running"}
+	if services[0] != want {
+		t.Errorf("service = %+v, want %+v", services[0], want)
+	}
+}
+
+func TestParseUnitFilesAndEnablement(t *testing.T) {
+	services := []ServiceInfo{{Name: "sshd.service"}, {Name: "cron.service"}}
+	enablement := parseUnitFiles("sshd.service enabled enabled\ncron.service disabled\n")
+	applyEnablement(services, enablement)
+	if services[0].Enabled != "enabled" || services[1].Enabled != "disabled" {
+		t.Errorf("enablement = %+v", services)
+	}
+}
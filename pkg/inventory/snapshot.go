@@ -0,0 +1,20 @@
+package inventory
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// WriteSnapshot writes the inventory as indented JSON with an atomic
+// rename, so local readers never observe a partially written file.
+func WriteSnapshot(path string, inv *Inventory) error {
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,30 @@
+package inventory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	inv := &Inventory{SchemaVersion: SchemaVersion, Hostname: "node-01"}
+	if err := WriteSnapshot(path, inv); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Inventory
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("snapshot is not valid JSON: %v", err)
+	}
+	if got.Hostname != "node-01" || got.SchemaVersion != SchemaVersion {
+		t.Errorf("snapshot = %+v", got)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file left behind")
+	}
+}
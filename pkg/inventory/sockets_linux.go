@@ -0,0 +1,146 @@
+//go:build linux
+
+package inventory
+
+import (
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Socket states from include/net/tcp_states.h.
+const (
+	tcpListen = "0A"
+	// udpUnconnected ("close" in tcp terms) is the state of a bound,
+	// unconnected UDP socket, i.e. one that receives datagrams.
+	udpUnconnected = "07"
+)
+
+// readListeningPorts parses the /proc/net socket tables and attributes
+// each listening socket to its owning process via /proc/*/fd. Process
+// attribution is best-effort: without root only the agent's own sockets
+// resolve.
+func readListeningPorts() []ListeningPort {
+	owners := socketOwners()
+	var out []ListeningPort
+	tables := []struct {
+		file, proto, state string
+	}{
+		{"tcp", "tcp", tcpListen},
+		{"tcp6", "tcp6", tcpListen},
+		{"udp", "udp", udpUnconnected},
+		{"udp6", "udp6", udpUnconnected},
+	}
+	for _, tbl := range tables {
+		data, err := os.ReadFile(filepath.Join(procRoot, "net", tbl.file))
+		if err != nil {
+			continue
+		}
+		out = append(out, parseSocketTable(string(data), tbl.proto, tbl.state, owners)...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Port != out[j].Port {
+			return out[i].Port < out[j].Port
+		}
+		return out[i].Proto < out[j].Proto
+	})
+	return out
+}
+
+type socketOwner struct {
+	pid  int
+	comm string
+}
+
+// socketOwners maps socket inodes to their owning process by scanning
+// /proc/*/fd for socket:[inode] links.
+func socketOwners() map[uint64]socketOwner {
+	owners := make(map[uint64]socketOwner)
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return owners
+	}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, e.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // not our process and not root
+		}
+		comm := ""
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if comm == "" {
+				if data, err := os.ReadFile(filepath.Join(procRoot, e.Name(), "comm")); err == nil {
+					comm = strings.TrimSpace(string(data))
+				}
+			}
+			owners[inode] = socketOwner{pid: pid, comm: comm}
+		}
+	}
+	return owners
+}
+
+// parseSocketTable extracts sockets in the wanted state from one
+// /proc/net table. Lines look like:
+//
+//	0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000 0 0 12345 ...
+func parseSocketTable(data, proto, wantState string, owners map[uint64]socketOwner) []ListeningPort {
+	var out []ListeningPort
+	lines := strings.Split(data, "\n")
+	for _, line := range lines[1:] { // first line is the header
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] != wantState {
+			continue
+		}
+		addr, port, ok := parseHexAddr(fields[1])
+		if !ok {
+			continue
+		}
+		lp := ListeningPort{Proto: proto, Address: addr, Port: port}
+		if inode, err := strconv.ParseUint(fields[9], 10, 64); err == nil {
+			if o, ok := owners[inode]; ok {
+				lp.PID = o.pid
+				lp.Process = o.comm
+			}
+		}
+		out = append(out, lp)
+	}
+	return out
+}
+
+// parseHexAddr decodes the kernel's ADDR:PORT format, where the address
+// is hex in host (little-endian) byte order per 32-bit word.
+func parseHexAddr(s string) (string, int, bool) {
+	addrHex, portHex, found := strings.Cut(s, ":")
+	if !found {
+		return "", 0, false
+	}
+	port, err := strconv.ParseInt(portHex, 16, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	raw, err := hex.DecodeString(addrHex)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, false
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip.String(), int(port), true
+}
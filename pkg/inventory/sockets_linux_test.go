@@ -0,0 +1,31 @@
+//go:build linux
+
+package inventory
+
+import "testing"
+
+func TestParseSocketTable(t *testing.T) {
+	table := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 0100007F:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 67890 1 0000000000000000 100 0 0 10 0\n" +
+		"   2: 0100007F:0050 0100007F:9D2C 01 00000000:00000000 00:00000000 00000000     0        0 11111 1 0000000000000000 100 0 0 10 0\n"
+	owners := map[uint64]socketOwner{12345: {pid: 900, comm: "sshd"}}
+	ports := parseSocketTable(table, "tcp", tcpListen, owners)
+	if len(ports) != 2 {
+		t.Fatalf("got %d sockets, want 2 (established one excluded)", len(ports))
+	}
+	want := ListeningPort{Proto: "tcp", Address: "0.0.0.0", Port: 22, PID: 900, Process: "sshd"}
+	if ports[0] != want {
+		t.Errorf("socket = %+v, want %+v", ports[0], want)
+	}
+	if ports[1].Address != "127.0.0.1" || ports[1].Port != 0x1538 || ports[1].PID != 0 {
+		t.Errorf("socket = %+v", ports[1])
+	}
+}
+
+func TestParseHexAddrIPv6(t *testing.T) {
+	addr, port, ok := parseHexAddr("00000000000000000000000001000000:0035")
+	if !ok || addr != "::1" || port != 53 {
+		t.Errorf("got %q:%d ok=%v, want ::1:53", addr, port, ok)
+	}
+}
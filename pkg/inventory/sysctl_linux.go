@@ -0,0 +1,65 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readKernelModules parses /proc/modules:
+//
+//	nvme 49152 4 nvme_core, Live 0x0000000000000000
+func readKernelModules() []KernelModule {
+	data, err := os.ReadFile(filepath.Join(procRoot, "modules"))
+	if err != nil {
+		return nil
+	}
+	var modules []KernelModule
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		refs, err := strconv.Atoi(fields[2])
+		if err != nil {
+			refs = -1 // permanent modules report "-"
+		}
+		modules = append(modules, KernelModule{
+			Name:     fields[0],
+			Size:     size,
+			Refcount: refs,
+		})
+	}
+	return modules
+}
+
+// sysRoot is a variable so tests can point ReadSysctls at a fixture
+// tree.
+var sysRoot = "/proc/sys"
+
+// ReadSysctls reads the named kernel parameters ("net.ipv4.ip_forward")
+// from /proc/sys. Unreadable or missing parameters are omitted rather
+// than reported as empty, so drift detection can distinguish "not set"
+// from "not present on this kernel".
+func ReadSysctls(names []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	out := make(map[string]string)
+	for _, name := range names {
+		path := filepath.Join(sysRoot, strings.ReplaceAll(name, ".", "/"))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		out[name] = strings.Join(strings.Fields(string(data)), " ")
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadKernelModules(t *testing.T) {
+	old := procRoot
+	procRoot = t.TempDir()
+	defer func() { procRoot = old }()
+
+	modules := "nvme 49152 4 nvme_core, Live 0x0000000000000000\n" +
+		"unix 106496 - - Live 0x0000000000000000\n"
+	if err := os.WriteFile(filepath.Join(procRoot, "modules"), []byte(modules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mods := readKernelModules()
+	if len(mods) != 2 {
+		t.Fatalf("got %d modules, want 2", len(mods))
+	}
+	if mods[0] != (KernelModule{Name: "nvme", Size: 49152, Refcount: 4}) {
+		t.Errorf("module = %+v", mods[0])
+	}
+	if mods[1].Refcount != -1 {
+		t.Errorf("permanent module refcount = %d, want -1", mods[1].Refcount)
+	}
+}
+
+func TestReadSysctls(t *testing.T) {
+	old := sysRoot
+	sysRoot = t.TempDir()
+	defer func() { sysRoot = old }()
+
+	dir := filepath.Join(sysRoot, "net", "ipv4")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ip_forward"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ReadSysctls([]string{"net.ipv4.ip_forward", "net.ipv4.nonexistent"})
+	if len(got) != 1 || got["net.ipv4.ip_forward"] != "1" {
+		t.Errorf("sysctls = %v", got)
+	}
+	if ReadSysctls(nil) != nil {
+		t.Error("empty name list should report nothing")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package inventory
+
+// ReadSysctls reports nothing on platforms without /proc/sys.
+func ReadSysctls(names []string) map[string]string { return nil }
@@ -0,0 +1,90 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// runRoot is a variable so tests can fake daemon runtime directories.
+var runRoot = "/run"
+
+// Kernel timex status bits and return codes (adjtimex(2)).
+const (
+	staUnsync = 0x0040
+	staNano   = 0x2000
+	timeError = 5
+)
+
+// readTimeSync reports the kernel's NTP discipline state, which any of
+// the common daemons maintain through adjtimex.
+func readTimeSync() *TimeSyncInfo {
+	var tx syscall.Timex
+	state, err := syscall.Adjtimex(&tx)
+	if err != nil {
+		return nil
+	}
+	ts := &TimeSyncInfo{
+		Synchronized: state != timeError && tx.Status&staUnsync == 0,
+		Daemon:       detectTimeDaemon(),
+		MaxErrorMS:   float64(tx.Maxerror) / 1000,
+	}
+	// Offset is nanoseconds when STA_NANO is set, else microseconds.
+	if tx.Status&staNano != 0 {
+		ts.OffsetMS = float64(tx.Offset) / 1e6
+	} else {
+		ts.OffsetMS = float64(tx.Offset) / 1e3
+	}
+	if ts.Daemon == "chronyd" {
+		ts.Stratum = chronyStratum()
+	}
+	return ts
+}
+
+// detectTimeDaemon identifies the running time daemon by its runtime
+// directory.
+func detectTimeDaemon() string {
+	checks := []struct {
+		path   string
+		daemon string
+	}{
+		{"chrony", "chronyd"},
+		{"systemd/timesync", "systemd-timesyncd"},
+		{"ntpd.pid", "ntpd"},
+	}
+	for _, c := range checks {
+		if _, err := os.Stat(filepath.Join(runRoot, c.path)); err == nil {
+			return c.daemon
+		}
+	}
+	return ""
+}
+
+// chronyStratum asks chronyc for the tracking report. Best effort: 0
+// when chronyc is unavailable or the daemon refuses.
+func chronyStratum() int {
+	out, err := exec.Command("chronyc", "-c", "tracking").Output()
+	if err != nil {
+		return 0
+	}
+	return parseChronyTracking(string(out))
+}
+
+// parseChronyTracking extracts the stratum from `chronyc -c tracking`
+// CSV output: refid,refname,stratum,...
+func parseChronyTracking(out string) int {
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) < 3 {
+		return 0
+	}
+	stratum, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0
+	}
+	return stratum
+}
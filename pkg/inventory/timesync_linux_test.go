@@ -0,0 +1,44 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectTimeDaemon(t *testing.T) {
+	old := runRoot
+	defer func() { runRoot = old }()
+
+	runRoot = t.TempDir()
+	if got := detectTimeDaemon(); got != "" {
+		t.Errorf("detectTimeDaemon = %q on empty /run", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(runRoot, "chrony"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectTimeDaemon(); got != "chronyd" {
+		t.Errorf("detectTimeDaemon = %q, want chronyd", got)
+	}
+
+	runRoot = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(runRoot, "systemd", "timesync"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectTimeDaemon(); got != "systemd-timesyncd" {
+		t.Errorf("detectTimeDaemon = %q, want systemd-timesyncd", got)
+	}
+}
+
+func TestParseChronyTracking(t *testing.T) {
+	out := "A29FC87B,162.159.200.123,3,1724800000.123,0.000012,-0.000034,0.000100,1.5,0.01,0.05,64.2,Normal\n"
+	if got := parseChronyTracking(out); got != 3 {
+		t.Errorf("stratum = %d, want 3", got)
+	}
+	if got := parseChronyTracking("garbage"); got != 0 {
+		t.Errorf("stratum = %d for garbage, want 0", got)
+	}
+}
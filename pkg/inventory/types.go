@@ -0,0 +1,544 @@
+// Package inventory defines the data reported to the server about a
+// host and its hardware, and implements local (in-band) collection.
+// Out-of-band collection through a BMC lives in pkg/bmc and reuses the
+// hardware types defined here.
+package inventory
+
+// SchemaVersion is the current revision of the inventory schema,
+// stamped into every local and BMC payload so the server can parse old
+// and new agents side by side as fields evolve.
+const SchemaVersion = 1
+
+// Inventory is the local, OS-level view of a host.
+type Inventory struct {
+	SchemaVersion  int             `json:"schema_version"`
+	Hostname       string          `json:"hostname"`
+	OS             string          `json:"os"`
+	Platform       string          `json:"platform"`
+	KernelVersion  string          `json:"kernel_version"`
+	Uptime         int64           `json:"uptime_seconds"`
+	CPU            CPUInfo         `json:"cpu"`
+	Memory         MemoryInfo      `json:"memory"`
+	Disks          []DiskInfo      `json:"disks"`
+	Network        []NetworkInfo   `json:"network"`
+	PCIDevices     []PCIDevice     `json:"pci_devices,omitempty"`
+	Services       []ServiceInfo   `json:"services,omitempty"`
+	Containers     []ContainerInfo `json:"containers,omitempty"`
+	Processes      []ProcessInfo   `json:"processes,omitempty"`
+	ListeningPorts []ListeningPort `json:"listening_ports,omitempty"`
+	DMI            *DMIInfo        `json:"dmi,omitempty"`
+	Users          []UserAccount   `json:"users,omitempty"`
+	KernelModules  []KernelModule  `json:"kernel_modules,omitempty"`
+	// Sysctls holds the configured kernel parameters by dotted name,
+	// e.g. "net.ipv4.ip_forward".
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	// Virtualization is "none" on bare metal, otherwise the detected
+	// hypervisor: kvm, vmware, hyperv, xen, virtualbox, or "unknown"
+	// when a hypervisor is present but unidentified. Tells the server
+	// whether BMC collection is meaningful for this host.
+	Virtualization string         `json:"virtualization,omitempty"`
+	Cloud          *CloudInfo     `json:"cloud,omitempty"`
+	NetworkConfig  *NetworkConfig `json:"network_config,omitempty"`
+	NUMA           *NUMATopology  `json:"numa,omitempty"`
+	// Sensors are the host's own hwmon readings, for thermal and power
+	// visibility on machines without a reachable BMC.
+	Sensors *SensorReadings `json:"sensors,omitempty"`
+	// Batteries and UPS give edge deployments power visibility; both
+	// are opt-in through the "power" collector.
+	Batteries []BatteryInfo `json:"batteries,omitempty"`
+	UPS       []UPSInfo     `json:"ups,omitempty"`
+	// Firmware is the boot and platform security posture, for
+	// compliance dashboards.
+	Firmware *FirmwareInfo `json:"firmware,omitempty"`
+	// TimeSync is the host clock's synchronization state.
+	TimeSync *TimeSyncInfo `json:"time_sync,omitempty"`
+	// Certificates are the TLS certificates the agent is configured to
+	// watch, so expiring certs surface centrally before they bite.
+	Certificates []CertificateInfo `json:"certificates,omitempty"`
+	// Hotfixes lists installed Windows updates; empty elsewhere.
+	Hotfixes []HotfixInfo `json:"hotfixes,omitempty"`
+}
+
+// HotfixInfo is one installed Windows update.
+type HotfixInfo struct {
+	// ID is the KB article number, e.g. KB5034123.
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	InstalledOn string `json:"installed_on,omitempty"`
+}
+
+// CertificateInfo describes one watched TLS certificate.
+type CertificateInfo struct {
+	// Source is the file path, or "port:<n>" for a live endpoint.
+	Source  string `json:"source"`
+	Subject string `json:"subject,omitempty"`
+	Issuer  string `json:"issuer,omitempty"`
+	// NotAfter is the expiry as unix time; DaysRemaining is negative
+	// once the certificate has expired.
+	NotAfter      int64 `json:"not_after"`
+	DaysRemaining int   `json:"days_remaining"`
+	// Error notes a path or endpoint that could not be scanned.
+	Error string `json:"error,omitempty"`
+}
+
+// TimeSyncInfo reports whether and how well the host clock is
+// disciplined, from the kernel's NTP state.
+type TimeSyncInfo struct {
+	Synchronized bool `json:"synchronized"`
+	// Daemon is chronyd, systemd-timesyncd, or ntpd when one is
+	// detected running.
+	Daemon string `json:"daemon,omitempty"`
+	// OffsetMS is the kernel's current clock offset estimate in
+	// milliseconds.
+	OffsetMS float64 `json:"offset_ms"`
+	// MaxErrorMS is the kernel's error bound in milliseconds.
+	MaxErrorMS float64 `json:"max_error_ms,omitempty"`
+	// Stratum is the NTP stratum when the daemon exposes it (chrony).
+	Stratum int `json:"stratum,omitempty"`
+}
+
+// FirmwareInfo summarizes firmware identity and platform security
+// state.
+type FirmwareInfo struct {
+	BIOSVersion string `json:"bios_version,omitempty"`
+	BIOSDate    string `json:"bios_date,omitempty"`
+	// BootMode is uefi or bios.
+	BootMode string `json:"boot_mode"`
+	// SecureBoot is enabled or disabled; empty when the state cannot be
+	// read (legacy boot, or efivars not mounted).
+	SecureBoot string `json:"secure_boot,omitempty"`
+	TPMPresent bool   `json:"tpm_present"`
+	// TPMVersion is 1.2 or 2.0 when a TPM is present.
+	TPMVersion string `json:"tpm_version,omitempty"`
+}
+
+// BatteryInfo is one battery from /sys/class/power_supply.
+type BatteryInfo struct {
+	Name string `json:"name"`
+	// Status is the kernel's charge state: Charging, Discharging, Full,
+	// or Unknown.
+	Status        string `json:"status,omitempty"`
+	ChargePercent int    `json:"charge_percent"`
+	CycleCount    int    `json:"cycle_count,omitempty"`
+}
+
+// UPSInfo is one UPS reported by a local NUT or apcupsd daemon.
+type UPSInfo struct {
+	Name string `json:"name"`
+	// Source is nut or apcupsd.
+	Source         string `json:"source"`
+	Status         string `json:"status,omitempty"`
+	ChargePercent  int    `json:"charge_percent"`
+	RuntimeSeconds int    `json:"runtime_seconds,omitempty"`
+}
+
+// SensorReadings are in-band sensor values from /sys/class/hwmon,
+// using the same temperature and fan structures as the BMC inventory
+// so the server sees one schema either way.
+type SensorReadings struct {
+	Temperatures []TempInfo     `json:"temperatures,omitempty"`
+	Fans         []FanInfo      `json:"fans,omitempty"`
+	Power        []PowerReading `json:"power,omitempty"`
+}
+
+// PowerReading is one power meter value in watts.
+type PowerReading struct {
+	Name  string  `json:"name"`
+	Watts float64 `json:"watts"`
+}
+
+// NUMATopology describes the host's NUMA layout and hugepage pools,
+// for capacity planning and VM placement. Nil on non-NUMA kernels.
+type NUMATopology struct {
+	Nodes     []NUMANode     `json:"nodes"`
+	Hugepages []HugepageInfo `json:"hugepages,omitempty"`
+}
+
+// NUMANode is one memory node and the CPUs attached to it.
+type NUMANode struct {
+	ID          int    `json:"id"`
+	MemoryTotal uint64 `json:"memory_total"`
+	MemoryFree  uint64 `json:"memory_free"`
+	CPUs        []int  `json:"cpus,omitempty"`
+}
+
+// HugepageInfo is one hugepage pool, keyed by page size.
+type HugepageInfo struct {
+	SizeKB int `json:"size_kb"`
+	Total  int `json:"total"`
+	Free   int `json:"free"`
+}
+
+// NetworkConfig is the host's routing, DNS, and NTP configuration, for
+// diagnosing connectivity problems remotely.
+type NetworkConfig struct {
+	// DefaultRoutes lists the default gateway per interface; more than
+	// one entry usually means a misconfigured or multi-homed host.
+	DefaultRoutes []RouteInfo `json:"default_routes,omitempty"`
+	DNSServers    []string    `json:"dns_servers,omitempty"`
+	SearchDomains []string    `json:"search_domains,omitempty"`
+	NTPServers    []string    `json:"ntp_servers,omitempty"`
+}
+
+// RouteInfo is one default route.
+type RouteInfo struct {
+	Interface string `json:"interface"`
+	Gateway   string `json:"gateway"`
+}
+
+// CloudInfo identifies the cloud instance a host runs on, correlating
+// cloud and on-prem machines in one console. Nil outside a cloud.
+type CloudInfo struct {
+	// Provider is aws, gcp, or azure.
+	Provider     string `json:"provider"`
+	InstanceID   string `json:"instance_id,omitempty"`
+	InstanceType string `json:"instance_type,omitempty"`
+	Region       string `json:"region,omitempty"`
+	Zone         string `json:"zone,omitempty"`
+}
+
+// KernelModule describes one loaded kernel module.
+type KernelModule struct {
+	Name string `json:"name"`
+	// Size is the module's memory footprint in bytes.
+	Size int64 `json:"size"`
+	// Refcount is the module's use count; -1 for permanent modules.
+	Refcount int `json:"refcount"`
+}
+
+// UserAccount describes one local account, for spotting unexpected
+// admin users across the fleet.
+type UserAccount struct {
+	Name  string `json:"name"`
+	UID   int    `json:"uid"`
+	GID   int    `json:"gid"`
+	Home  string `json:"home,omitempty"`
+	Shell string `json:"shell,omitempty"`
+	// Admin is set for root and members of the sudo/wheel/admin groups.
+	Admin bool `json:"admin"`
+	// LastLogin is the unix time of the last login, 0 if never or
+	// unknown.
+	LastLogin int64 `json:"last_login,omitempty"`
+}
+
+// DMIInfo is the SMBIOS hardware identity, so serial numbers are
+// available even on hosts without a configured BMC.
+type DMIInfo struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"product_name,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+	AssetTag     string `json:"asset_tag,omitempty"`
+	// ChassisType is the SMBIOS enumerated chassis code, e.g. 23 for
+	// rack mount.
+	ChassisType string `json:"chassis_type,omitempty"`
+	// Baseboard identifies the motherboard, which can differ from the
+	// system product on OEM platforms.
+	BoardVendor string `json:"board_vendor,omitempty"`
+	BoardName   string `json:"board_name,omitempty"`
+	BoardSerial string `json:"board_serial,omitempty"`
+	BIOSVersion string `json:"bios_version,omitempty"`
+	BIOSDate    string `json:"bios_date,omitempty"`
+}
+
+// ListeningPort describes one listening TCP or UDP socket, giving the
+// server an exposure view per host.
+type ListeningPort struct {
+	// Proto is tcp, tcp6, udp, or udp6.
+	Proto string `json:"proto"`
+	// Address is the bind address; 0.0.0.0 or :: means all interfaces.
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+	// PID and Process identify the owning process when readable (needs
+	// the agent to run as root for other users' sockets).
+	PID     int    `json:"pid,omitempty"`
+	Process string `json:"process,omitempty"`
+}
+
+// ProcessInfo describes one of the busiest processes on the host, for
+// remote triage of hot hosts. The cmdline is truncated to keep payloads
+// bounded.
+type ProcessInfo struct {
+	PID     int    `json:"pid"`
+	User    string `json:"user,omitempty"`
+	Cmdline string `json:"cmdline"`
+	// CPUPercent is the process's average CPU usage over its lifetime.
+	CPUPercent float64 `json:"cpu_percent"`
+	// RSS is the resident set size in bytes.
+	RSS uint64 `json:"rss"`
+}
+
+// ContainerInfo describes one container on a container host, collected
+// from the Docker-compatible Engine API.
+type ContainerInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// ImageDigest is the resolved image ID (sha256:...), pinning what
+	// actually runs regardless of tag drift.
+	ImageDigest  string `json:"image_digest,omitempty"`
+	State        string `json:"state"`
+	Status       string `json:"status,omitempty"`
+	RestartCount int    `json:"restart_count"`
+	// Ports lists published ports as "0.0.0.0:8080->80/tcp".
+	Ports []string `json:"ports,omitempty"`
+	// Mounts lists bind mounts and volumes as "source:destination".
+	Mounts []string `json:"mounts,omitempty"`
+}
+
+// ServiceInfo describes one systemd service unit, so the server can see
+// what is running on a host without SSH access.
+type ServiceInfo struct {
+	Name string `json:"name"`
+	// LoadState is systemd's load state: loaded, not-found, masked, ...
+	LoadState string `json:"load_state"`
+	// ActiveState is the high-level state: active, inactive, failed, ...
+	ActiveState string `json:"active_state"`
+	// SubState is the unit-type-specific state: running, exited, dead, ...
+	SubState string `json:"sub_state"`
+	// Enabled is the unit file enablement: enabled, disabled, static, ...
+	Enabled string `json:"enabled,omitempty"`
+}
+
+// PCIDevice describes one device on the PCI bus, so NICs, HBAs, and
+// accelerators are visible even on hosts without BMC access.
+type PCIDevice struct {
+	// Address is the PCI domain:bus:device.function, e.g. 0000:3b:00.0.
+	Address  string `json:"address"`
+	VendorID string `json:"vendor_id"`
+	DeviceID string `json:"device_id"`
+	// Class is the PCI class code, e.g. 0x020000 for Ethernet.
+	Class string `json:"class"`
+	// Driver is the bound kernel driver, if any.
+	Driver string `json:"driver,omitempty"`
+	// NUMANode is the node the device is attached to; -1 when unknown.
+	NUMANode int `json:"numa_node"`
+}
+
+// CPUInfo describes the host processors.
+type CPUInfo struct {
+	Model   string  `json:"model"`
+	Cores   int     `json:"cores"`
+	Threads int     `json:"threads"`
+	MHz     float64 `json:"mhz,omitempty"`
+}
+
+// MemoryInfo describes physical memory usage in bytes.
+type MemoryInfo struct {
+	Total     uint64 `json:"total"`
+	Available uint64 `json:"available"`
+	Used      uint64 `json:"used"`
+}
+
+// DiskInfo describes one mounted filesystem.
+type DiskInfo struct {
+	Device     string `json:"device"`
+	Mountpoint string `json:"mountpoint"`
+	FSType     string `json:"fstype"`
+	Total      uint64 `json:"total"`
+	Used       uint64 `json:"used"`
+	Free       uint64 `json:"free"`
+	// Inode counts; zero on filesystems without inode limits.
+	InodesTotal uint64 `json:"inodes_total,omitempty"`
+	InodesUsed  uint64 `json:"inodes_used,omitempty"`
+	// Options as mounted, e.g. rw, noexec, nosuid.
+	Options []string `json:"options,omitempty"`
+	// ReadOnly reflects the kernel's view of the mount, so it catches
+	// errors=remount-ro transitions even before /proc/mounts updates.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// NetworkInfo describes one network interface, including its place in
+// the bond/bridge/VLAN topology so NIC failover problems can be
+// diagnosed remotely.
+type NetworkInfo struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Up        bool     `json:"up"`
+	MTU       int      `json:"mtu,omitempty"`
+	// Kind is bond, bridge, or vlan for virtual interfaces.
+	Kind string `json:"kind,omitempty"`
+	// Master is the bond or bridge this interface is enslaved to.
+	Master string `json:"master,omitempty"`
+	// BondMode is the bonding mode (e.g. 802.3ad, active-backup) when
+	// Kind is bond.
+	BondMode string `json:"bond_mode,omitempty"`
+	// VLANParent and VLANID describe a VLAN sub-interface.
+	VLANParent string `json:"vlan_parent,omitempty"`
+	VLANID     int    `json:"vlan_id,omitempty"`
+}
+
+// BMCInventory is the out-of-band view of a machine collected through
+// its BMC.
+type BMCInventory struct {
+	SchemaVersion int `json:"schema_version"`
+	// NodeID identifies the ComputerSystem on multi-node sleds and
+	// blades, where one BMC fronts several systems.
+	NodeID        string        `json:"node_id,omitempty"`
+	Manufacturer  string        `json:"manufacturer,omitempty"`
+	Model         string        `json:"model,omitempty"`
+	SerialNumber  string        `json:"serial_number,omitempty"`
+	BIOSVersion   string        `json:"bios_version,omitempty"`
+	BMCVersion    string        `json:"bmc_version,omitempty"`
+	PowerState    string        `json:"power_state,omitempty"`
+	Health        string        `json:"health,omitempty"`
+	Processors    []CPUInfo     `json:"processors,omitempty"`
+	MemoryTotal   uint64        `json:"memory_total,omitempty"`
+	Storage       []StorageInfo `json:"storage,omitempty"`
+	Temperatures  []TempInfo    `json:"temperatures,omitempty"`
+	Fans          []FanInfo     `json:"fans,omitempty"`
+	PowerSupplies []PSUInfo     `json:"power_supplies,omitempty"`
+	// PowerConsumedWatts is the instantaneous draw summed over all
+	// chassis that report a PowerControl reading.
+	PowerConsumedWatts float64 `json:"power_consumed_watts,omitempty"`
+	// PowerCapWatts is the active chassis power limit; zero means
+	// uncapped.
+	PowerCapWatts float64 `json:"power_cap_watts,omitempty"`
+	// Boot is the configured boot order and any active boot source
+	// override.
+	Boot *BootInfo `json:"boot,omitempty"`
+	// Manager describes the BMC itself: firmware, network
+	// configuration, NTP, and license state.
+	Manager *BMCManagerInfo `json:"manager,omitempty"`
+	// PCIeDevices are the PCIe endpoints (GPUs, NICs, HBAs) the BMC
+	// reports, complementing the OS-level pci_devices view.
+	PCIeDevices []BMCPCIeDevice `json:"pcie_devices,omitempty"`
+	// FirmwareInventory is the machine's firmware bill of materials
+	// from the UpdateService, for compliance and update campaigns.
+	FirmwareInventory []FirmwareComponent `json:"firmware_inventory,omitempty"`
+	// BIOSAttributes are the currently applied BIOS settings.
+	// PendingBIOS holds attributes staged in the Bios Settings
+	// resource, typically applied on the next reboot.
+	BIOSAttributes map[string]interface{} `json:"bios_attributes,omitempty"`
+	PendingBIOS    map[string]interface{} `json:"pending_bios,omitempty"`
+	// CollectionErrors maps sections that failed to collect (or ran
+	// past the collect budget) to the reason, so a missing section is
+	// distinguishable from absent hardware.
+	CollectionErrors map[string]string `json:"collection_errors,omitempty"`
+}
+
+// BMCManagerInfo describes the management controller itself, from the
+// Redfish Managers resource. FirmwareVersion is the BMC firmware
+// (iDRAC/iLO/XCC version), distinct from the Redfish spec version in
+// BMCVersion.
+type BMCManagerInfo struct {
+	FirmwareVersion string       `json:"firmware_version,omitempty"`
+	Model           string       `json:"model,omitempty"`
+	Health          string       `json:"health,omitempty"`
+	NICs            []BMCNICInfo `json:"nics,omitempty"`
+	// NTPEnabled and NTPServers mirror the NetworkProtocol NTP block.
+	NTPEnabled bool     `json:"ntp_enabled"`
+	NTPServers []string `json:"ntp_servers,omitempty"`
+	// License is the vendor license tier when the BMC exposes one
+	// (e.g. iLO Advanced).
+	License string `json:"license,omitempty"`
+}
+
+// BMCNICInfo is one network interface of the management controller.
+type BMCNICInfo struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	// DHCP reports whether any address came from DHCP rather than
+	// static configuration.
+	DHCP   bool `json:"dhcp"`
+	VLANID int  `json:"vlan_id,omitempty"`
+}
+
+// FirmwareComponent is one entry of the UpdateService firmware
+// inventory.
+type FirmwareComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Updateable reports whether the UpdateService can flash this
+	// component.
+	Updateable bool `json:"updateable"`
+}
+
+// BMCPCIeDevice is one PCIe device as seen by the BMC, including
+// accelerators surfaced through the Processors collection.
+type BMCPCIeDevice struct {
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	// DeviceClass is the PCIe function class (DisplayController,
+	// NetworkController, ...) or "Accelerator" for GPU processors.
+	DeviceClass     string `json:"device_class,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	// Slot is the physical slot ordinal when the chassis maps it.
+	Slot    int    `json:"slot,omitempty"`
+	Health  string `json:"health,omitempty"`
+	Chassis string `json:"chassis,omitempty"`
+}
+
+// BootInfo is the system's boot configuration as seen by the BMC.
+type BootInfo struct {
+	// Order lists boot entries in the configured order.
+	Order []string `json:"order,omitempty"`
+	// OverrideTarget is the one-time or persistent boot source
+	// override (Pxe, Hdd, ...); OverrideEnabled is Disabled, Once, or
+	// Continuous.
+	OverrideTarget  string `json:"override_target,omitempty"`
+	OverrideEnabled string `json:"override_enabled,omitempty"`
+	// OverrideMode is UEFI or Legacy when the BMC reports it.
+	OverrideMode string `json:"override_mode,omitempty"`
+}
+
+// StorageInfo describes a physical drive as seen by the BMC.
+type StorageInfo struct {
+	Name            string `json:"name"`
+	Model           string `json:"model,omitempty"`
+	SerialNumber    string `json:"serial_number,omitempty"`
+	MediaType       string `json:"media_type,omitempty"`
+	CapacityBytes   uint64 `json:"capacity_bytes,omitempty"`
+	Health          string `json:"health,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	// MediaLifeLeftPercent is the drive's predicted remaining
+	// endurance; -1 when the drive does not report one. Zero means
+	// the endurance is exhausted, so it is always serialized.
+	MediaLifeLeftPercent int `json:"media_life_left_percent"`
+	// SpeedGbs is the negotiated link speed in Gbit/s.
+	SpeedGbs float64 `json:"speed_gbs,omitempty"`
+	// EncryptionAbility and EncryptionStatus mirror the Redfish drive
+	// fields (e.g. SelfEncryptingDrive / Unlocked).
+	EncryptionAbility string `json:"encryption_ability,omitempty"`
+	EncryptionStatus  string `json:"encryption_status,omitempty"`
+	// FailurePredicted is the drive's SMART trip as surfaced by the
+	// BMC.
+	FailurePredicted bool `json:"failure_predicted,omitempty"`
+}
+
+// TempInfo is one temperature sensor reading in degrees Celsius.
+// Chassis identifies the enclosure the sensor belongs to on
+// multi-chassis systems; local sensors leave it empty.
+type TempInfo struct {
+	Name    string  `json:"name"`
+	Celsius float64 `json:"celsius"`
+	// CriticalCelsius is the sensor's upper critical threshold when the
+	// BMC reports one.
+	CriticalCelsius float64 `json:"critical_celsius,omitempty"`
+	Health          string  `json:"health,omitempty"`
+	Chassis         string  `json:"chassis,omitempty"`
+}
+
+// FanInfo is one fan reading.
+type FanInfo struct {
+	Name string `json:"name"`
+	RPM  int    `json:"rpm"`
+	// CriticalRPM is the fan's lower critical threshold when the BMC
+	// reports one.
+	CriticalRPM int    `json:"critical_rpm,omitempty"`
+	Health      string `json:"health,omitempty"`
+	Chassis     string `json:"chassis,omitempty"`
+}
+
+// PSUInfo describes one power supply.
+type PSUInfo struct {
+	Name         string  `json:"name"`
+	Model        string  `json:"model,omitempty"`
+	SerialNumber string  `json:"serial_number,omitempty"`
+	CapacityWatt float64 `json:"capacity_watts,omitempty"`
+	Health       string  `json:"health,omitempty"`
+	Chassis      string  `json:"chassis,omitempty"`
+}
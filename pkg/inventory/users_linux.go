@@ -0,0 +1,98 @@
+//go:build linux
+
+package inventory
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// etcRoot is a variable so tests can point the reader at a fixture
+// tree.
+var etcRoot = "/etc"
+
+// adminGroups are the groups whose membership grants sudo on the
+// distributions we run.
+var adminGroups = map[string]bool{"sudo": true, "wheel": true, "admin": true}
+
+// lastlog records are fixed-size, indexed by uid: a 32-bit time
+// followed by the terminal (32 bytes) and host (256 bytes).
+const lastlogRecordSize = 4 + 32 + 256
+
+// readUsers reports local accounts with login shells, their admin
+// status, and last login time. Nologin system accounts are skipped to
+// keep the payload focused on accounts someone could actually use.
+func readUsers() []UserAccount {
+	passwd, err := os.ReadFile(filepath.Join(etcRoot, "passwd"))
+	if err != nil {
+		return nil
+	}
+	var admins map[string]bool
+	if groups, err := os.ReadFile(filepath.Join(etcRoot, "group")); err == nil {
+		admins = adminMembers(string(groups))
+	}
+	users := parsePasswd(string(passwd), admins)
+	lastlog, _ := os.ReadFile("/var/log/lastlog")
+	for i := range users {
+		users[i].LastLogin = lastLoginTime(lastlog, users[i].UID)
+	}
+	return users
+}
+
+func parsePasswd(data string, admins map[string]bool) []UserAccount {
+	var users []UserAccount
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		shell := fields[6]
+		if strings.HasSuffix(shell, "nologin") || strings.HasSuffix(shell, "/false") {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		gid, _ := strconv.Atoi(fields[3])
+		users = append(users, UserAccount{
+			Name:  fields[0],
+			UID:   uid,
+			GID:   gid,
+			Home:  fields[5],
+			Shell: shell,
+			Admin: uid == 0 || admins[fields[0]],
+		})
+	}
+	return users
+}
+
+// adminMembers extracts the members of the admin groups from
+// /etc/group.
+func adminMembers(data string) map[string]bool {
+	members := make(map[string]bool)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 4 || !adminGroups[fields[0]] {
+			continue
+		}
+		for _, name := range strings.Split(fields[3], ",") {
+			if name != "" {
+				members[name] = true
+			}
+		}
+	}
+	return members
+}
+
+// lastLoginTime reads a uid's record from the binary lastlog database.
+func lastLoginTime(lastlog []byte, uid int) int64 {
+	off := uid * lastlogRecordSize
+	if lastlog == nil || off+4 > len(lastlog) {
+		return 0
+	}
+	return int64(binary.LittleEndian.Uint32(lastlog[off:]))
+}
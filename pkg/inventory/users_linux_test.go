@@ -0,0 +1,53 @@
+//go:build linux
+
+package inventory
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+const testPasswd = `root:x:0:0:root:/root:/bin/bash
+daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin
+alice:x:1000:1000:Alice:/home/alice:/bin/bash
+bob:x:1001:1001::/home/bob:/bin/zsh
+#comment:x:9:9:::/bin/sh
+`
+
+const testGroup = `root:x:0:
+sudo:x:27:alice
+users:x:100:alice,bob
+`
+
+func TestParsePasswd(t *testing.T) {
+	users := parsePasswd(testPasswd, adminMembers(testGroup))
+	if len(users) != 3 {
+		t.Fatalf("got %d users, want 3 (nologin and comment skipped)", len(users))
+	}
+	if !users[0].Admin || users[0].Name != "root" {
+		t.Errorf("root = %+v", users[0])
+	}
+	if !users[1].Admin {
+		t.Error("alice should be admin via sudo group")
+	}
+	if users[2].Admin {
+		t.Error("bob should not be admin")
+	}
+	if users[1].UID != 1000 || users[1].Shell != "/bin/bash" || users[1].Home != "/home/alice" {
+		t.Errorf("alice = %+v", users[1])
+	}
+}
+
+func TestLastLoginTime(t *testing.T) {
+	lastlog := make([]byte, lastlogRecordSize*3)
+	binary.LittleEndian.PutUint32(lastlog[lastlogRecordSize*2:], 1700000000)
+	if got := lastLoginTime(lastlog, 2); got != 1700000000 {
+		t.Errorf("lastLoginTime(2) = %d", got)
+	}
+	if got := lastLoginTime(lastlog, 1); got != 0 {
+		t.Errorf("lastLoginTime(1) = %d, want 0 for never", got)
+	}
+	if got := lastLoginTime(lastlog, 99); got != 0 {
+		t.Errorf("lastLoginTime(99) = %d, want 0 past end", got)
+	}
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectVirtualization identifies the hypervisor from DMI vendor
+// strings, falling back to the CPUID hypervisor flag the kernel mirrors
+// into /proc/cpuinfo. A host with the flag set but no recognizable
+// vendor reports "unknown" rather than guessing.
+func detectVirtualization(dmi *DMIInfo) string {
+	if dmi != nil {
+		if v := hypervisorFromDMI(dmi); v != "" {
+			return v
+		}
+	}
+	// Xen PV guests have no DMI at all but expose /sys/hypervisor.
+	if data, err := os.ReadFile("/sys/hypervisor/type"); err == nil {
+		if t := strings.TrimSpace(string(data)); t != "" {
+			return strings.ToLower(t)
+		}
+	}
+	if cpuHasHypervisorFlag() {
+		return "unknown"
+	}
+	return "none"
+}
+
+func hypervisorFromDMI(dmi *DMIInfo) string {
+	id := strings.ToLower(dmi.Manufacturer + " " + dmi.ProductName)
+	switch {
+	case strings.Contains(id, "qemu") || strings.Contains(id, "kvm"):
+		return "kvm"
+	case strings.Contains(id, "vmware"):
+		return "vmware"
+	case strings.Contains(id, "virtualbox"):
+		return "virtualbox"
+	case strings.Contains(id, "xen"):
+		return "xen"
+	case strings.Contains(id, "microsoft") && strings.Contains(id, "virtual"):
+		return "hyperv"
+	}
+	return ""
+}
+
+func cpuHasHypervisorFlag() bool {
+	data, err := os.ReadFile(filepath.Join(procRoot, "cpuinfo"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "flags") {
+			for _, flag := range strings.Fields(line) {
+				if flag == "hypervisor" {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
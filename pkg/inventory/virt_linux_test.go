@@ -0,0 +1,34 @@
+//go:build linux
+
+package inventory
+
+import "testing"
+
+func TestHypervisorFromDMI(t *testing.T) {
+	cases := []struct {
+		vendor, product, want string
+	}{
+		{"QEMU", "Standard PC (i440FX + PIIX, 1996)", "kvm"},
+		{"VMware, Inc.", "VMware Virtual Platform", "vmware"},
+		{"Microsoft Corporation", "Virtual Machine", "hyperv"},
+		{"Xen", "HVM domU", "xen"},
+		{"innotek GmbH", "VirtualBox", "virtualbox"},
+		{"Dell Inc.", "PowerEdge R750", ""},
+	}
+	for _, tc := range cases {
+		dmi := &DMIInfo{Manufacturer: tc.vendor, ProductName: tc.product}
+		if got := hypervisorFromDMI(dmi); got != tc.want {
+			t.Errorf("hypervisorFromDMI(%q, %q) = %q, want %q",
+				tc.vendor, tc.product, got, tc.want)
+		}
+	}
+}
+
+func TestDetectVirtualizationBareMetal(t *testing.T) {
+	// A bare-metal DMI with no hypervisor hints must report "none"
+	// regardless of what the test host itself runs on.
+	dmi := &DMIInfo{Manufacturer: "Supermicro", ProductName: "X12DPi-N6"}
+	if got := hypervisorFromDMI(dmi); got != "" {
+		t.Errorf("bare metal detected as %q", got)
+	}
+}
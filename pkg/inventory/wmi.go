@@ -0,0 +1,33 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// This file holds the parsing half of the Windows WMI collector. It is
+// portable so the parsers stay testable on every platform; the exec
+// half lives in local_windows.go.
+
+// cimScript renders the PowerShell pipeline that dumps a CIM class as
+// JSON.
+func cimScript(class string, properties []string) string {
+	return fmt.Sprintf("Get-CimInstance -ClassName %s | Select-Object %s | ConvertTo-Json -Compress",
+		class, strings.Join(properties, ","))
+}
+
+// decodeCIM unmarshals ConvertTo-Json output into out, which must be a
+// pointer to a slice. PowerShell emits a bare object when a class has
+// exactly one instance, so that case is wrapped into a one-element
+// array first.
+func decodeCIM(data []byte, out interface{}) error {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil
+	}
+	if strings.HasPrefix(trimmed, "{") {
+		trimmed = "[" + trimmed + "]"
+	}
+	return json.Unmarshal([]byte(trimmed), out)
+}
@@ -0,0 +1,39 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCIMScript(t *testing.T) {
+	got := cimScript("Win32_BIOS", []string{"SerialNumber", "SMBIOSBIOSVersion"})
+	if !strings.Contains(got, "Get-CimInstance -ClassName Win32_BIOS") ||
+		!strings.Contains(got, "Select-Object SerialNumber,SMBIOSBIOSVersion") ||
+		!strings.Contains(got, "ConvertTo-Json") {
+		t.Errorf("script = %q", got)
+	}
+}
+
+func TestDecodeCIMArray(t *testing.T) {
+	var out []struct{ Name string }
+	err := decodeCIM([]byte(`[{"Name":"a"},{"Name":"b"}]`), &out)
+	if err != nil || len(out) != 2 || out[1].Name != "b" {
+		t.Errorf("out = %+v, err = %v", out, err)
+	}
+}
+
+func TestDecodeCIMSingleObject(t *testing.T) {
+	// PowerShell drops the array wrapper for single-instance classes.
+	var out []struct{ Version string }
+	err := decodeCIM([]byte(`{"Version":"10.0.20348"}`), &out)
+	if err != nil || len(out) != 1 || out[0].Version != "10.0.20348" {
+		t.Errorf("out = %+v, err = %v", out, err)
+	}
+}
+
+func TestDecodeCIMEmpty(t *testing.T) {
+	var out []struct{ Name string }
+	if err := decodeCIM([]byte("  \n"), &out); err != nil || out != nil {
+		t.Errorf("out = %+v, err = %v", out, err)
+	}
+}
@@ -0,0 +1,91 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLogger writes terminal audit records to an append-only local
+// file and optionally to syslog, independent of the server, so a
+// forensic trail survives even when the control plane is compromised
+// or unreachable. Each record is one JSON object per line.
+type AuditLogger struct {
+	mu   sync.Mutex
+	file *os.File
+	sys  syslogWriter
+}
+
+// syslogWriter is the subset of the platform syslog connection the
+// audit trail needs; openSyslog is build-tagged per platform.
+type syslogWriter interface {
+	Info(msg string) error
+	Close() error
+}
+
+// NewAuditLogger opens the audit sinks. path may be empty to log to
+// syslog only; useSyslog connects to the local syslog daemon.
+func NewAuditLogger(path string, useSyslog bool) (*AuditLogger, error) {
+	a := &AuditLogger{}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open audit log: %w", err)
+		}
+		a.file = f
+	}
+	if useSyslog {
+		sys, err := openSyslog("agent-terminal")
+		if err != nil {
+			if a.file != nil {
+				a.file.Close()
+			}
+			return nil, fmt.Errorf("open syslog: %w", err)
+		}
+		a.sys = sys
+	}
+	return a, nil
+}
+
+// event appends one audit record; failures are ignored beyond the
+// sinks' own error handling since there is no better place to report
+// them.
+func (a *AuditLogger) event(name string, fields map[string]interface{}) {
+	rec := map[string]interface{}{
+		"time":  time.Now().UTC().Format(time.RFC3339),
+		"event": name,
+	}
+	for k, v := range fields {
+		rec[k] = v
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file != nil {
+		a.file.Write(append(line, '\n'))
+	}
+	if a.sys != nil {
+		a.sys.Info(string(line))
+	}
+}
+
+// Close flushes and closes the audit sinks.
+func (a *AuditLogger) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var err error
+	if a.file != nil {
+		err = a.file.Close()
+		a.file = nil
+	}
+	if a.sys != nil {
+		a.sys.Close()
+		a.sys = nil
+	}
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+
+package terminal
+
+import "log/syslog"
+
+// openSyslog connects to the local syslog daemon under the authpriv
+// facility, where access audit trails belong.
+func openSyslog(tag string) (syslogWriter, error) {
+	return syslog.New(syslog.LOG_AUTHPRIV|syslog.LOG_INFO, tag)
+}
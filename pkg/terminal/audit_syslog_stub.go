@@ -0,0 +1,11 @@
+//go:build windows || plan9
+
+package terminal
+
+import "errors"
+
+// openSyslog is unavailable on this platform; the audit file remains
+// the only local sink.
+func openSyslog(tag string) (syslogWriter, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,99 @@
+package terminal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Policy constrains what remote operators may run. Deny patterns win
+// over allow patterns; read-only mode and the sudo ban are evaluated
+// per pipeline segment so `cat x | sudo tee y` does not slip through.
+type Policy struct {
+	allow    []*regexp.Regexp
+	deny     []*regexp.Regexp
+	readOnly bool
+	noSudo   bool
+}
+
+// NewPolicy compiles a command policy from the configured patterns.
+func NewPolicy(allow, deny []string, readOnly, noSudo bool) (*Policy, error) {
+	p := &Policy{readOnly: readOnly, noSudo: noSudo}
+	for _, pat := range allow {
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return nil, fmt.Errorf("allow pattern %q: %w", pat, err)
+		}
+		p.allow = append(p.allow, re)
+	}
+	for _, pat := range deny {
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return nil, fmt.Errorf("deny pattern %q: %w", pat, err)
+		}
+		p.deny = append(p.deny, re)
+	}
+	return p, nil
+}
+
+// readOnlyCommands are the inspection commands permitted in read-only
+// mode.
+var readOnlyCommands = map[string]bool{
+	"cat": true, "less": true, "more": true, "head": true, "tail": true,
+	"ls": true, "stat": true, "file": true, "find": true, "du": true,
+	"df": true, "grep": true, "awk": true, "sed": true, "wc": true,
+	"ps": true, "top": true, "free": true, "uptime": true, "uname": true,
+	"id": true, "who": true, "w": true, "env": true, "hostname": true,
+	"date": true, "dmesg": true, "journalctl": true, "ip": true,
+	"ss": true, "netstat": true, "ping": true, "dig": true,
+	"nslookup": true, "lspci": true, "lsusb": true, "lsblk": true,
+	"lscpu": true, "echo": true, "pwd": true, "which": true,
+}
+
+// escalationCommands switch identity and defeat any other constraint.
+var escalationCommands = map[string]bool{"sudo": true, "su": true, "doas": true}
+
+// segmentSplit breaks a command line at pipeline and list operators.
+var segmentSplit = regexp.MustCompile(`\|\||&&|[|;]`)
+
+// Check returns nil when the command is allowed, or an error naming
+// the violated rule.
+func (p *Policy) Check(command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+	for _, re := range p.deny {
+		if re.MatchString(command) {
+			return fmt.Errorf("command matches deny pattern %q", re.String())
+		}
+	}
+	if p.noSudo || p.readOnly {
+		for _, seg := range segmentSplit.Split(command, -1) {
+			fields := strings.Fields(seg)
+			if len(fields) == 0 {
+				continue
+			}
+			name := path.Base(fields[0])
+			if p.noSudo && escalationCommands[name] {
+				return fmt.Errorf("privilege escalation via %q is not permitted", name)
+			}
+			if p.readOnly && !readOnlyCommands[name] {
+				return fmt.Errorf("%q is not permitted in read-only mode", name)
+			}
+		}
+	}
+	if p.readOnly && strings.Contains(command, ">") {
+		return fmt.Errorf("output redirection is not permitted in read-only mode")
+	}
+	if len(p.allow) > 0 {
+		for _, re := range p.allow {
+			if re.MatchString(command) {
+				return nil
+			}
+		}
+		return fmt.Errorf("command matches no allow pattern")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyDenyWinsOverAllow(t *testing.T) {
+	p, err := NewPolicy([]string{".*"}, []string{`\brm\b`}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check("ls -l /tmp"); err != nil {
+		t.Errorf("Check(ls) = %v, want nil", err)
+	}
+	if err := p.Check("rm -rf /tmp/x"); err == nil {
+		t.Error("Check(rm) = nil, want deny violation")
+	}
+}
+
+func TestPolicyAllowList(t *testing.T) {
+	p, err := NewPolicy([]string{`^systemctl status`, `^journalctl`}, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check("systemctl status sshd"); err != nil {
+		t.Errorf("Check = %v, want nil", err)
+	}
+	if err := p.Check("systemctl restart sshd"); err == nil {
+		t.Error("Check = nil, want allow violation")
+	}
+}
+
+func TestPolicyNoSudo(t *testing.T) {
+	p, err := NewPolicy(nil, nil, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{
+		"sudo reboot",
+		"cat /etc/passwd | sudo tee /etc/passwd.bak",
+		"ls && su -",
+		"/usr/bin/sudo id",
+	} {
+		if err := p.Check(cmd); err == nil {
+			t.Errorf("Check(%q) = nil, want escalation violation", cmd)
+		}
+	}
+	if err := p.Check("echo sudoku"); err != nil {
+		t.Errorf("Check(echo sudoku) = %v, want nil", err)
+	}
+}
+
+func TestPolicyReadOnly(t *testing.T) {
+	p, err := NewPolicy(nil, nil, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"ls /etc", "cat /proc/cpuinfo | grep model", "df -h"} {
+		if err := p.Check(cmd); err != nil {
+			t.Errorf("Check(%q) = %v, want nil", cmd, err)
+		}
+	}
+	for _, cmd := range []string{"rm /tmp/x", "ls > /tmp/out", "cat x; reboot"} {
+		if err := p.Check(cmd); err == nil {
+			t.Errorf("Check(%q) = nil, want read-only violation", cmd)
+		}
+	}
+}
+
+func TestPolicyEmptyLineAllowed(t *testing.T) {
+	p, err := NewPolicy(nil, []string{".+"}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check("   "); err != nil {
+		t.Errorf("Check(blank) = %v, want nil", err)
+	}
+}
+
+func TestNewPolicyBadPattern(t *testing.T) {
+	if _, err := NewPolicy([]string{"("}, nil, false, false); err == nil {
+		t.Error("NewPolicy with invalid allow pattern succeeded")
+	}
+	_, err := NewPolicy(nil, []string{"["}, false, false)
+	if err == nil {
+		t.Fatal("NewPolicy with invalid deny pattern succeeded")
+	}
+	if !strings.Contains(err.Error(), "deny pattern") {
+		t.Errorf("error %q does not name the deny pattern", err)
+	}
+}
@@ -0,0 +1,38 @@
+package terminal
+
+import "sync"
+
+// scrollback is a bounded ring of the most recent session output,
+// replayed when the server reattaches after a brief control-plane
+// interruption. The pump goroutine writes while reattach reads, so
+// access is guarded.
+type scrollback struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func newScrollback(max int) *scrollback {
+	return &scrollback{max: max}
+}
+
+// write appends p, discarding the oldest bytes beyond the bound.
+func (s *scrollback) write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(p) >= s.max {
+		s.buf = append(s.buf[:0], p[len(p)-s.max:]...)
+		return
+	}
+	s.buf = append(s.buf, p...)
+	if over := len(s.buf) - s.max; over > 0 {
+		s.buf = append(s.buf[:0], s.buf[over:]...)
+	}
+}
+
+// snapshot returns a copy of the buffered output.
+func (s *scrollback) snapshot() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte(nil), s.buf...)
+}
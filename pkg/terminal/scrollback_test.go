@@ -0,0 +1,37 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScrollbackKeepsRecentOutput(t *testing.T) {
+	sb := newScrollback(8)
+	sb.write([]byte("abc"))
+	sb.write([]byte("def"))
+	if got := sb.snapshot(); !bytes.Equal(got, []byte("abcdef")) {
+		t.Errorf("snapshot = %q, want %q", got, "abcdef")
+	}
+	sb.write([]byte("ghij"))
+	if got := sb.snapshot(); !bytes.Equal(got, []byte("cdefghij")) {
+		t.Errorf("snapshot = %q, want %q", got, "cdefghij")
+	}
+}
+
+func TestScrollbackLargeWrite(t *testing.T) {
+	sb := newScrollback(4)
+	sb.write([]byte("0123456789"))
+	if got := sb.snapshot(); !bytes.Equal(got, []byte("6789")) {
+		t.Errorf("snapshot = %q, want %q", got, "6789")
+	}
+}
+
+func TestScrollbackSnapshotIsCopy(t *testing.T) {
+	sb := newScrollback(8)
+	sb.write([]byte("abcd"))
+	snap := sb.snapshot()
+	sb.write([]byte("efgh"))
+	if !bytes.Equal(snap, []byte("abcd")) {
+		t.Errorf("earlier snapshot changed to %q", snap)
+	}
+}
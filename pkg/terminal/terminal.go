@@ -0,0 +1,566 @@
+// Package terminal provides interactive shell sessions driven by the
+// server over the WebSocket connection. The server sends
+// terminal_command messages (init, input, resize, close) and the agent
+// streams PTY output back as terminal_output messages. Besides the
+// default shell sessions, an init with session_type "sol" bridges a
+// Serial-over-LAN console to the named BMC target over the same
+// messages.
+package terminal
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simonschuang/demo/internal/pty"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// Executor manages terminal sessions for one client connection.
+type Executor struct {
+	client *websocket.Client
+
+	// solCommand builds the command bridging a Serial-over-LAN console
+	// for sol sessions; nil means sol sessions are unsupported.
+	solCommand func(target string) (*exec.Cmd, error)
+
+	// idleTimeout and maxDuration bound sessions; zero means no limit.
+	idleTimeout time.Duration
+	maxDuration time.Duration
+
+	// maxSessions and maxPerUser cap simultaneous sessions overall and
+	// per requesting user; zero means no limit.
+	maxSessions int
+	maxPerUser  int
+
+	// scrollbackBytes bounds the per-session ring of recent output
+	// replayed on reattach; zero disables scrollback.
+	scrollbackBytes int
+
+	// policy, when set with enforceInput, is checked against each
+	// completed interactive input line.
+	policy       *Policy
+	enforceInput bool
+
+	// audit receives session lifecycle records; transcriptDir, when
+	// non-empty, additionally stores the full output of each session.
+	audit         *AuditLogger
+	transcriptDir string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	id   string
+	cmd  *exec.Cmd
+	term pty.Session
+	done chan struct{}
+	// user is the requesting user from the init command data, used for
+	// per-user quotas; may be empty.
+	user string
+
+	started time.Time
+	// lastInput tracks activity for the idle timeout, guarded by the
+	// executor mutex.
+	lastInput time.Time
+
+	// lineBuf assembles keystrokes into the pending input line for
+	// policy checks; only the handler goroutine touches it.
+	lineBuf []byte
+
+	// sb buffers recent output for reattach; nil when scrollback is
+	// disabled.
+	sb *scrollback
+
+	// hash accumulates the SHA-256 of all session output for the audit
+	// trail; transcript optionally stores the output itself. Both are
+	// touched only by the pump goroutine.
+	hash        hash.Hash
+	transcript  *os.File
+	outputBytes int64
+}
+
+// NewExecutor builds a terminal executor sending output through client.
+func NewExecutor(client *websocket.Client) *Executor {
+	return &Executor{
+		client:   client,
+		sessions: make(map[string]*session),
+	}
+}
+
+// HandleCommand processes one terminal_command message.
+func (e *Executor) HandleCommand(msg *websocket.Message) {
+	action, _ := msg.Data["action"].(string)
+	sessionID, _ := msg.Data["session_id"].(string)
+	if sessionID == "" {
+		e.sendError(sessionID, "missing session_id")
+		return
+	}
+	switch action {
+	case "init":
+		sessionType, _ := msg.Data["session_type"].(string)
+		target, _ := msg.Data["target"].(string)
+		user, _ := msg.Data["user"].(string)
+		e.startSession(sessionID, sessionType, target, user, msg.MessageID)
+	case "input":
+		input, _ := msg.Data["input"].(string)
+		e.writeInput(sessionID, input)
+	case "resize":
+		rows, _ := msg.Data["rows"].(float64)
+		cols, _ := msg.Data["cols"].(float64)
+		e.resize(sessionID, uint16(rows), uint16(cols))
+	case "reattach":
+		e.reattach(sessionID)
+	case "close":
+		e.closeSession(sessionID)
+	default:
+		e.sendError(sessionID, "unknown action: "+action)
+	}
+}
+
+// SetSOLCommand installs the builder for sol session commands. Call
+// before the executor handles its first message.
+func (e *Executor) SetSOLCommand(build func(target string) (*exec.Cmd, error)) {
+	e.solCommand = build
+}
+
+// SetLimits bounds sessions by idle time and total lifetime; zero
+// disables the respective limit. Call before the executor handles its
+// first message.
+func (e *Executor) SetLimits(idleTimeout, maxDuration time.Duration) {
+	e.idleTimeout = idleTimeout
+	e.maxDuration = maxDuration
+}
+
+// SetScrollback bounds the ring of recent output kept per session for
+// reattach; zero disables scrollback. Call before the executor handles
+// its first message.
+func (e *Executor) SetScrollback(bytes int) {
+	e.scrollbackBytes = bytes
+}
+
+// SetSessionLimits caps simultaneous sessions overall and per
+// requesting user; zero disables the respective limit. Call before the
+// executor handles its first message.
+func (e *Executor) SetSessionLimits(maxSessions, maxPerUser int) {
+	e.maxSessions = maxSessions
+	e.maxPerUser = maxPerUser
+}
+
+// SetAudit installs the audit trail; with transcriptDir non-empty the
+// full output of each session is stored there as <session id>.log.
+// Call before the executor handles its first message.
+func (e *Executor) SetAudit(a *AuditLogger, transcriptDir string) {
+	e.audit = a
+	e.transcriptDir = transcriptDir
+}
+
+// SetPolicy installs the command policy; with enforceInput set, every
+// completed interactive input line is checked before it reaches the
+// shell. Call before the executor handles its first message.
+func (e *Executor) SetPolicy(p *Policy, enforceInput bool) {
+	e.policy = p
+	e.enforceInput = enforceInput
+}
+
+func (e *Executor) startSession(sessionID, sessionType, target, user, origin string) {
+	e.mu.Lock()
+	if _, exists := e.sessions[sessionID]; exists {
+		e.mu.Unlock()
+		e.sendError(sessionID, "session already exists")
+		return
+	}
+	total := len(e.sessions)
+	perUser := 0
+	if user != "" {
+		for _, s := range e.sessions {
+			if s.user == user {
+				perUser++
+			}
+		}
+	}
+	e.mu.Unlock()
+	// Inits are dispatched from a single goroutine, so checking before
+	// the session is created cannot race another init.
+	if e.maxSessions > 0 && total >= e.maxSessions {
+		e.rejectSession(sessionID, "session_limit", e.maxSessions,
+			"maximum number of concurrent sessions reached")
+		return
+	}
+	if e.maxPerUser > 0 && user != "" && perUser >= e.maxPerUser {
+		e.rejectSession(sessionID, "user_session_limit", e.maxPerUser,
+			"maximum number of concurrent sessions for user "+user+" reached")
+		return
+	}
+
+	var cmd *exec.Cmd
+	switch sessionType {
+	case "", "shell":
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			if runtime.GOOS == "windows" {
+				shell = "powershell.exe"
+			} else {
+				shell = "/bin/sh"
+			}
+		}
+		cmd = exec.Command(shell)
+		cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	case "sol":
+		if e.solCommand == nil {
+			e.sendError(sessionID, "sol sessions are not supported on this agent")
+			return
+		}
+		var err error
+		if cmd, err = e.solCommand(target); err != nil {
+			e.sendError(sessionID, err.Error())
+			return
+		}
+	default:
+		e.sendError(sessionID, "unknown session type: "+sessionType)
+		return
+	}
+	term, err := pty.Start(cmd)
+	if err != nil {
+		e.sendError(sessionID, "start session: "+err.Error())
+		return
+	}
+	now := time.Now()
+	s := &session{
+		id:        sessionID,
+		cmd:       cmd,
+		term:      term,
+		done:      make(chan struct{}),
+		user:      user,
+		started:   now,
+		lastInput: now,
+	}
+	if e.scrollbackBytes > 0 {
+		s.sb = newScrollback(e.scrollbackBytes)
+	}
+	if e.audit != nil {
+		s.hash = sha256.New()
+		if e.transcriptDir != "" {
+			name := sanitizeSessionID(sessionID) + ".log"
+			f, err := os.OpenFile(filepath.Join(e.transcriptDir, name),
+				os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				slog.Warn("terminal transcript open failed", "session_id", sessionID, "error", err)
+			} else {
+				s.transcript = f
+			}
+		}
+		e.audit.event("session_start", map[string]interface{}{
+			"session_id":   sessionID,
+			"session_type": sessionType,
+			"target":       target,
+			"user":         user,
+			"message_id":   origin,
+			"command":      cmd.Path,
+		})
+	}
+	e.mu.Lock()
+	e.sessions[sessionID] = s
+	e.mu.Unlock()
+
+	slog.Info("terminal session started", "session_id", sessionID, "command", cmd.Path)
+	go e.pump(s)
+	if e.idleTimeout > 0 || e.maxDuration > 0 {
+		go e.watch(s)
+	}
+}
+
+// watch expires a session that hits the idle timeout or the maximum
+// duration: the user gets a notice in the terminal, the server an
+// expired event, and the session is torn down.
+func (e *Executor) watch(s *session) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		e.mu.Lock()
+		idle := time.Since(s.lastInput)
+		e.mu.Unlock()
+		var reason string
+		switch {
+		case e.maxDuration > 0 && time.Since(s.started) >= e.maxDuration:
+			reason = "maximum session duration reached"
+		case e.idleTimeout > 0 && idle >= e.idleTimeout:
+			reason = "session idle timeout reached"
+		default:
+			continue
+		}
+		notice := "\r\n[" + reason + ", closing session]\r\n"
+		e.send(s.id, map[string]interface{}{
+			"output": base64.StdEncoding.EncodeToString([]byte(notice)),
+		})
+		e.send(s.id, map[string]interface{}{"event": "expired", "reason": reason})
+		if e.audit != nil {
+			e.audit.event("session_expired", map[string]interface{}{
+				"session_id": s.id,
+				"reason":     reason,
+			})
+		}
+		slog.Info("terminal session expired", "session_id", s.id, "reason", reason)
+		e.closeSession(s.id)
+		return
+	}
+}
+
+// pump copies PTY output to the server until the shell exits.
+func (e *Executor) pump(s *session) {
+	defer func() {
+		s.term.Wait()
+		close(s.done)
+		e.mu.Lock()
+		delete(e.sessions, s.id)
+		e.mu.Unlock()
+		e.send(s.id, map[string]interface{}{"event": "closed"})
+		if e.audit != nil {
+			fields := map[string]interface{}{
+				"session_id":       s.id,
+				"duration_seconds": int(time.Since(s.started).Seconds()),
+				"output_bytes":     s.outputBytes,
+			}
+			if s.hash != nil {
+				fields["transcript_sha256"] = hex.EncodeToString(s.hash.Sum(nil))
+			}
+			if s.transcript != nil {
+				fields["transcript_file"] = s.transcript.Name()
+				s.transcript.Close()
+			}
+			e.audit.event("session_closed", fields)
+		}
+		slog.Info("terminal session closed", "session_id", s.id)
+	}()
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.term.Read(buf)
+		if n > 0 {
+			if s.sb != nil {
+				s.sb.write(buf[:n])
+			}
+			if s.hash != nil {
+				s.hash.Write(buf[:n])
+				s.outputBytes += int64(n)
+			}
+			if s.transcript != nil {
+				s.transcript.Write(buf[:n])
+			}
+			e.send(s.id, map[string]interface{}{
+				"output": base64.StdEncoding.EncodeToString(buf[:n]),
+			})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (e *Executor) writeInput(sessionID, input string) {
+	s := e.lookup(sessionID)
+	if s == nil {
+		e.sendError(sessionID, "no such session")
+		return
+	}
+	e.mu.Lock()
+	s.lastInput = time.Now()
+	e.mu.Unlock()
+	data, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		// Tolerate raw (non-encoded) input from older servers.
+		data = []byte(input)
+	}
+	if e.policy != nil && e.enforceInput {
+		e.filterInput(s, data)
+		return
+	}
+	s.term.Write(data)
+}
+
+// filterInput mirrors keystrokes into the session line buffer and
+// checks each line submitted with Enter against the policy. A
+// violating line never reaches the shell: the newline is dropped and a
+// kill-line replaces it so the shell discards what was echoed, and the
+// violation is logged and reported to the server.
+func (e *Executor) filterInput(s *session, data []byte) {
+	fwd := make([]byte, 0, len(data))
+	for _, b := range data {
+		switch b {
+		case '\r', '\n':
+			line := string(s.lineBuf)
+			s.lineBuf = s.lineBuf[:0]
+			if err := e.policy.Check(line); err != nil {
+				fwd = append(fwd, 0x15) // NAK: kill the pending line
+				e.reportViolation(s, line, err)
+				continue
+			}
+			fwd = append(fwd, b)
+		case 0x7f, 0x08: // backspace
+			if n := len(s.lineBuf); n > 0 {
+				s.lineBuf = s.lineBuf[:n-1]
+			}
+			fwd = append(fwd, b)
+		case 0x03, 0x15: // interrupt and kill-line discard the line
+			s.lineBuf = s.lineBuf[:0]
+			fwd = append(fwd, b)
+		default:
+			if b >= 0x20 {
+				s.lineBuf = append(s.lineBuf, b)
+			}
+			fwd = append(fwd, b)
+		}
+	}
+	if len(fwd) > 0 {
+		s.term.Write(fwd)
+	}
+}
+
+func (e *Executor) reportViolation(s *session, line string, err error) {
+	notice := "\r\n[command blocked: " + err.Error() + "]\r\n"
+	e.send(s.id, map[string]interface{}{
+		"output": base64.StdEncoding.EncodeToString([]byte(notice)),
+	})
+	e.send(s.id, map[string]interface{}{
+		"event":  "policy_violation",
+		"reason": err.Error(),
+	})
+	if e.audit != nil {
+		e.audit.event("policy_violation", map[string]interface{}{
+			"session_id": s.id,
+			"command":    line,
+			"reason":     err.Error(),
+		})
+	}
+	slog.Warn("terminal command blocked", "session_id", s.id, "command", line, "error", err)
+}
+
+// sanitizeSessionID maps a server-supplied session id to a safe file
+// name component.
+func sanitizeSessionID(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// reattach replays the scrollback of a still-running session so the
+// server can resume it after a brief interruption. The replayed chunks
+// are marked so the client can tell them from live output.
+func (e *Executor) reattach(sessionID string) {
+	s := e.lookup(sessionID)
+	if s == nil {
+		e.sendError(sessionID, "no such session")
+		return
+	}
+	e.mu.Lock()
+	s.lastInput = time.Now()
+	e.mu.Unlock()
+	e.send(s.id, map[string]interface{}{"event": "reattached"})
+	if s.sb != nil {
+		data := s.sb.snapshot()
+		for off := 0; off < len(data); off += 4096 {
+			end := off + 4096
+			if end > len(data) {
+				end = len(data)
+			}
+			e.send(s.id, map[string]interface{}{
+				"output": base64.StdEncoding.EncodeToString(data[off:end]),
+				"replay": true,
+			})
+		}
+	}
+	if e.audit != nil {
+		e.audit.event("session_reattached", map[string]interface{}{
+			"session_id": s.id,
+		})
+	}
+	slog.Info("terminal session reattached", "session_id", s.id)
+}
+
+func (e *Executor) resize(sessionID string, rows, cols uint16) {
+	s := e.lookup(sessionID)
+	if s == nil {
+		return
+	}
+	if err := s.term.Resize(rows, cols); err != nil {
+		slog.Warn("terminal resize failed", "session_id", sessionID, "error", err)
+	}
+}
+
+func (e *Executor) closeSession(sessionID string) {
+	s := e.lookup(sessionID)
+	if s == nil {
+		return
+	}
+	s.term.Close()
+	s.term.Kill()
+}
+
+// CloseAll tears down every active session.
+func (e *Executor) CloseAll() {
+	e.mu.Lock()
+	sessions := make([]*session, 0, len(e.sessions))
+	for _, s := range e.sessions {
+		sessions = append(sessions, s)
+	}
+	e.mu.Unlock()
+	for _, s := range sessions {
+		s.term.Close()
+		s.term.Kill()
+	}
+}
+
+func (e *Executor) lookup(sessionID string) *session {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.sessions[sessionID]
+}
+
+func (e *Executor) send(sessionID string, data map[string]interface{}) {
+	data["session_id"] = sessionID
+	msg := websocket.NewMessage(websocket.TypeTerminalOutput, data)
+	if err := e.client.Send(msg); err != nil {
+		slog.Warn("terminal output send failed", "session_id", sessionID, "error", err)
+	}
+}
+
+func (e *Executor) sendError(sessionID, text string) {
+	e.send(sessionID, map[string]interface{}{"error": text})
+}
+
+// rejectSession refuses an init with a structured error naming the
+// exhausted quota.
+func (e *Executor) rejectSession(sessionID, code string, limit int, text string) {
+	e.send(sessionID, map[string]interface{}{
+		"error": text,
+		"code":  code,
+		"limit": limit,
+	})
+	if e.audit != nil {
+		e.audit.event("session_rejected", map[string]interface{}{
+			"session_id": sessionID,
+			"code":       code,
+			"limit":      limit,
+		})
+	}
+	slog.Warn("terminal session rejected", "session_id", sessionID, "code", code, "limit", limit)
+}
@@ -0,0 +1,328 @@
+// Package transfer moves files between the server and the agent over
+// the WebSocket connection. Transfers are chunked and checksummed:
+// file_put announces an upload and file_chunk messages carry its
+// pieces until file_done verifies the SHA-256; file_get streams a file
+// back the same way. Uploads land in a .part file next to the target
+// until verified, which also gives interrupted transfers a resume
+// offset. Paths are restricted to a configured allowlist and a size
+// limit applies in both directions.
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/simonschuang/demo/pkg/config"
+	"github.com/simonschuang/demo/pkg/websocket"
+)
+
+// partSuffix marks an upload that has not yet been verified.
+const partSuffix = ".part"
+
+// Manager services file transfer messages for one client connection.
+type Manager struct {
+	client *websocket.Client
+	cfg    config.FileTransferConfig
+
+	mu      sync.Mutex
+	uploads map[string]*upload
+}
+
+// upload is an in-flight file_put, keyed by the message id of the
+// announcing request.
+type upload struct {
+	path string
+	file *os.File
+	size int64
+	sha  string
+}
+
+// New builds a transfer manager sending through client.
+func New(client *websocket.Client, cfg config.FileTransferConfig) *Manager {
+	return &Manager{
+		client:  client,
+		cfg:     cfg,
+		uploads: make(map[string]*upload),
+	}
+}
+
+// Register subscribes the manager to the file transfer message types.
+func (m *Manager) Register() {
+	m.client.RegisterHandler(websocket.TypeFilePut, m.handlePut)
+	m.client.RegisterHandler(websocket.TypeFileChunk, m.handleChunk)
+	m.client.RegisterHandler(websocket.TypeFileDone, m.handleDone)
+	m.client.RegisterHandler(websocket.TypeFileGet, m.handleGet)
+}
+
+func (m *Manager) handlePut(msg *websocket.Message) {
+	result, err := m.beginPut(msg.MessageID, msg.Data)
+	m.reply(websocket.TypeFilePut, msg.MessageID, result, err)
+}
+
+func (m *Manager) handleChunk(msg *websocket.Message) {
+	if err := m.applyChunk(msg.Data); err != nil {
+		m.reply(websocket.TypeFileChunk, msg.MessageID, nil, err)
+	}
+}
+
+func (m *Manager) handleDone(msg *websocket.Message) {
+	result, err := m.finishPut(msg.Data)
+	m.reply(websocket.TypeFileDone, msg.MessageID, result, err)
+}
+
+func (m *Manager) handleGet(msg *websocket.Message) {
+	// Hashing and streaming a large file must not stall inbound
+	// dispatch.
+	go func() {
+		if err := m.serveGet(msg.MessageID, msg.Data); err != nil {
+			m.reply(websocket.TypeFileGet, msg.MessageID, nil, err)
+		}
+	}()
+}
+
+// beginPut validates an upload announcement and opens its .part file,
+// reporting the offset already present so the server can resume.
+func (m *Manager) beginPut(transferID string, data map[string]interface{}) (map[string]interface{}, error) {
+	if !m.cfg.Enabled {
+		return nil, fmt.Errorf("file transfer is disabled on this agent")
+	}
+	if transferID == "" {
+		return nil, fmt.Errorf("missing message_id")
+	}
+	path, _ := data["path"].(string)
+	if err := m.checkPath(path); err != nil {
+		return nil, err
+	}
+	size, _ := data["size"].(float64)
+	if size < 0 || int64(size) > m.cfg.MaxSizeBytes {
+		return nil, fmt.Errorf("size %d exceeds limit %d", int64(size), m.cfg.MaxSizeBytes)
+	}
+	sha, _ := data["sha256"].(string)
+	if sha == "" {
+		return nil, fmt.Errorf("missing sha256")
+	}
+	f, err := os.OpenFile(filepath.Clean(path)+partSuffix, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	offset := st.Size()
+	if offset > int64(size) {
+		// A stale partial from a different file; start over.
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, err
+		}
+		offset = 0
+	}
+	up := &upload{path: filepath.Clean(path), file: f, size: int64(size), sha: sha}
+	m.mu.Lock()
+	if _, exists := m.uploads[transferID]; exists {
+		m.mu.Unlock()
+		f.Close()
+		return nil, fmt.Errorf("transfer %s already in progress", transferID)
+	}
+	m.uploads[transferID] = up
+	m.mu.Unlock()
+	slog.Info("file upload started", "path", up.path, "size", up.size, "offset", offset)
+	return map[string]interface{}{"status": "ready", "offset": offset}, nil
+}
+
+// applyChunk writes one upload chunk at its stated offset.
+func (m *Manager) applyChunk(data map[string]interface{}) error {
+	transferID, _ := data["transfer_id"].(string)
+	up := m.lookup(transferID)
+	if up == nil {
+		return fmt.Errorf("no transfer %q in progress", transferID)
+	}
+	offset, _ := data["offset"].(float64)
+	raw, err := base64.StdEncoding.DecodeString(stringField(data, "data"))
+	if err != nil {
+		return fmt.Errorf("decode chunk: %w", err)
+	}
+	if int64(offset)+int64(len(raw)) > up.size {
+		return fmt.Errorf("chunk at offset %d overruns declared size %d", int64(offset), up.size)
+	}
+	if _, err := up.file.WriteAt(raw, int64(offset)); err != nil {
+		return fmt.Errorf("write chunk: %w", err)
+	}
+	return nil
+}
+
+// finishPut verifies the assembled upload and moves it into place. A
+// checksum mismatch discards the partial so the next attempt starts
+// clean.
+func (m *Manager) finishPut(data map[string]interface{}) (map[string]interface{}, error) {
+	transferID, _ := data["transfer_id"].(string)
+	m.mu.Lock()
+	up := m.uploads[transferID]
+	delete(m.uploads, transferID)
+	m.mu.Unlock()
+	if up == nil {
+		return nil, fmt.Errorf("no transfer %q in progress", transferID)
+	}
+	if err := up.file.Close(); err != nil {
+		return nil, err
+	}
+	part := up.path + partSuffix
+	sum, size, err := hashFile(part)
+	if err != nil {
+		return nil, err
+	}
+	if size != up.size || !strings.EqualFold(sum, up.sha) {
+		os.Remove(part)
+		return nil, fmt.Errorf("checksum mismatch: got %s (%d bytes), want %s (%d bytes)",
+			sum, size, up.sha, up.size)
+	}
+	if err := os.Rename(part, up.path); err != nil {
+		return nil, err
+	}
+	slog.Info("file upload completed", "path", up.path, "size", size)
+	return map[string]interface{}{"status": "ok", "path": up.path, "sha256": sum}, nil
+}
+
+// serveGet streams a file to the server: a ready reply with size and
+// checksum, the chunks from the requested offset, and a closing
+// file_done.
+func (m *Manager) serveGet(transferID string, data map[string]interface{}) error {
+	if !m.cfg.Enabled {
+		return fmt.Errorf("file transfer is disabled on this agent")
+	}
+	path, _ := data["path"].(string)
+	if err := m.checkPath(path); err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	offset, _ := data["offset"].(float64)
+	sum, size, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if size > m.cfg.MaxSizeBytes {
+		return fmt.Errorf("size %d exceeds limit %d", size, m.cfg.MaxSizeBytes)
+	}
+	if int64(offset) > size {
+		return fmt.Errorf("offset %d beyond end of file (%d bytes)", int64(offset), size)
+	}
+	m.reply(websocket.TypeFileGet, transferID, map[string]interface{}{
+		"status": "ready", "size": size, "sha256": sum,
+	}, nil)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, m.cfg.ChunkSizeBytes)
+	pos := int64(offset)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			chunk := websocket.NewMessage(websocket.TypeFileChunk, map[string]interface{}{
+				"transfer_id": transferID,
+				"offset":      pos,
+				"data":        base64.StdEncoding.EncodeToString(buf[:n]),
+			})
+			chunk.MessageID = transferID
+			if err := m.client.Send(chunk); err != nil {
+				return fmt.Errorf("send chunk: %w", err)
+			}
+			pos += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	m.reply(websocket.TypeFileDone, transferID, map[string]interface{}{
+		"status": "ok", "path": path, "size": size, "sha256": sum,
+	}, nil)
+	slog.Info("file download completed", "path", path, "size", size)
+	return nil
+}
+
+// checkPath enforces the configured path allowlist.
+func (m *Manager) checkPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("missing path")
+	}
+	if !m.pathAllowed(path) {
+		return fmt.Errorf("path %q is not within an allowed prefix", path)
+	}
+	return nil
+}
+
+// pathAllowed reports whether the cleaned path sits under one of the
+// configured prefixes.
+func (m *Manager) pathAllowed(path string) bool {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	for _, prefix := range m.cfg.AllowedPaths {
+		prefix = filepath.Clean(prefix)
+		if path == prefix || strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) lookup(transferID string) *upload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.uploads[transferID]
+}
+
+// reply sends a response of the given type keyed to the request.
+func (m *Manager) reply(msgType, messageID string, result map[string]interface{}, err error) {
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	if err != nil {
+		slog.Warn("file transfer failed", "type", msgType, "error", err)
+		result["error"] = err.Error()
+	}
+	msg := websocket.NewMessage(msgType, result)
+	msg.MessageID = messageID
+	if sendErr := m.client.Send(msg); sendErr != nil {
+		slog.Warn("file transfer reply failed", "type", msgType, "error", sendErr)
+	}
+}
+
+// hashFile returns the SHA-256 and size of the file at path.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func stringField(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
@@ -0,0 +1,175 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonschuang/demo/pkg/config"
+)
+
+func testManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	return New(nil, config.FileTransferConfig{
+		Enabled:        true,
+		AllowedPaths:   []string{dir},
+		MaxSizeBytes:   1 << 20,
+		ChunkSizeBytes: 8,
+	})
+}
+
+func TestPathAllowed(t *testing.T) {
+	m := testManager(t, "/var/agent/files")
+	for path, want := range map[string]bool{
+		"/var/agent/files/a.txt":        true,
+		"/var/agent/files/sub/b.txt":    true,
+		"/var/agent/files":              true,
+		"/var/agent/files/../secret":    false,
+		"/var/agent/filesystem/c.txt":   false,
+		"/etc/passwd":                   false,
+		"relative/path":                 false,
+		"/var/agent/files/sub/../a.txt": true,
+	} {
+		if got := m.pathAllowed(path); got != want {
+			t.Errorf("pathAllowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestUploadLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	m := testManager(t, dir)
+	content := []byte("hello, chunked world")
+	sum := sha256.Sum256(content)
+	path := filepath.Join(dir, "upload.txt")
+
+	result, err := m.beginPut("t1", map[string]interface{}{
+		"path":   path,
+		"size":   float64(len(content)),
+		"sha256": hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		t.Fatalf("beginPut: %v", err)
+	}
+	if result["offset"].(int64) != 0 {
+		t.Errorf("offset = %v, want 0", result["offset"])
+	}
+	for off := 0; off < len(content); off += 8 {
+		end := off + 8
+		if end > len(content) {
+			end = len(content)
+		}
+		err := m.applyChunk(map[string]interface{}{
+			"transfer_id": "t1",
+			"offset":      float64(off),
+			"data":        base64.StdEncoding.EncodeToString(content[off:end]),
+		})
+		if err != nil {
+			t.Fatalf("applyChunk at %d: %v", off, err)
+		}
+	}
+	if _, err := m.finishPut(map[string]interface{}{"transfer_id": "t1"}); err != nil {
+		t.Fatalf("finishPut: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("uploaded content = %q, want %q", got, content)
+	}
+	if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial file still present after completion")
+	}
+}
+
+func TestUploadChecksumMismatchDiscardsPartial(t *testing.T) {
+	dir := t.TempDir()
+	m := testManager(t, dir)
+	path := filepath.Join(dir, "bad.txt")
+	_, err := m.beginPut("t1", map[string]interface{}{
+		"path":   path,
+		"size":   float64(4),
+		"sha256": "deadbeef",
+	})
+	if err != nil {
+		t.Fatalf("beginPut: %v", err)
+	}
+	if err := m.applyChunk(map[string]interface{}{
+		"transfer_id": "t1",
+		"offset":      float64(0),
+		"data":        base64.StdEncoding.EncodeToString([]byte("data")),
+	}); err != nil {
+		t.Fatalf("applyChunk: %v", err)
+	}
+	if _, err := m.finishPut(map[string]interface{}{"transfer_id": "t1"}); err == nil {
+		t.Fatal("finishPut succeeded despite checksum mismatch")
+	}
+	if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("corrupt partial was not discarded")
+	}
+}
+
+func TestUploadResumeOffset(t *testing.T) {
+	dir := t.TempDir()
+	m := testManager(t, dir)
+	content := []byte("resumable payload")
+	sum := sha256.Sum256(content)
+	path := filepath.Join(dir, "resume.txt")
+	// A previous attempt left the first 8 bytes behind.
+	if err := os.WriteFile(path+partSuffix, content[:8], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.beginPut("t1", map[string]interface{}{
+		"path":   path,
+		"size":   float64(len(content)),
+		"sha256": hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		t.Fatalf("beginPut: %v", err)
+	}
+	if result["offset"].(int64) != 8 {
+		t.Fatalf("offset = %v, want 8", result["offset"])
+	}
+	if err := m.applyChunk(map[string]interface{}{
+		"transfer_id": "t1",
+		"offset":      float64(8),
+		"data":        base64.StdEncoding.EncodeToString(content[8:]),
+	}); err != nil {
+		t.Fatalf("applyChunk: %v", err)
+	}
+	if _, err := m.finishPut(map[string]interface{}{"transfer_id": "t1"}); err != nil {
+		t.Fatalf("finishPut: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("resumed content = %q, want %q", got, content)
+	}
+}
+
+func TestChunkOverrunRejected(t *testing.T) {
+	dir := t.TempDir()
+	m := testManager(t, dir)
+	_, err := m.beginPut("t1", map[string]interface{}{
+		"path":   filepath.Join(dir, "small.txt"),
+		"size":   float64(4),
+		"sha256": "deadbeef",
+	})
+	if err != nil {
+		t.Fatalf("beginPut: %v", err)
+	}
+	err = m.applyChunk(map[string]interface{}{
+		"transfer_id": "t1",
+		"offset":      float64(0),
+		"data":        base64.StdEncoding.EncodeToString([]byte("too much data")),
+	})
+	if err == nil {
+		t.Fatal("applyChunk accepted a chunk past the declared size")
+	}
+}
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TypeAuthRefresh is sent by the server to challenge the agent for a
+// fresh token, and by the agent to present one in-band.
+const TypeAuthRefresh = "auth_refresh"
+
+// TokenProvider supplies the client token used to authenticate to the
+// server. Implementations are consulted on every connect and refresh so
+// rotated tokens are picked up without restarting the agent.
+type TokenProvider interface {
+	Token() (string, error)
+}
+
+// StaticToken is a TokenProvider returning a fixed token.
+type StaticToken string
+
+// Token implements TokenProvider.
+func (t StaticToken) Token() (string, error) { return string(t), nil }
+
+// FileToken reads the token from a file on every call.
+type FileToken string
+
+// Token implements TokenProvider.
+func (t FileToken) Token() (string, error) {
+	data, err := os.ReadFile(string(t))
+	if err != nil {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// CommandToken runs a command and uses its trimmed stdout as the token.
+type CommandToken string
+
+// Token implements TokenProvider.
+func (t CommandToken) Token() (string, error) {
+	out, err := exec.Command("/bin/sh", "-c", string(t)).Output()
+	if err != nil {
+		return "", fmt.Errorf("token command: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetTokenProvider installs the token source used for the handshake and
+// for auth_refresh exchanges. When unset, the configured client_token is
+// used directly.
+func (c *Client) SetTokenProvider(p TokenProvider) {
+	c.tokenProvider = p
+}
+
+// currentToken resolves the token to present to the server.
+func (c *Client) currentToken() (string, error) {
+	if c.tokenProvider != nil {
+		return c.tokenProvider.Token()
+	}
+	return c.cfg.ClientToken, nil
+}
+
+// handleAuthRefresh answers a server auth_refresh challenge with a
+// fresh token, echoing the MessageID so the server can correlate it.
+func (c *Client) handleAuthRefresh(msg *Message) {
+	token, err := c.currentToken()
+	reply := NewMessage(TypeAuthRefresh, map[string]interface{}{})
+	reply.MessageID = msg.MessageID
+	if err != nil {
+		slog.Error("auth refresh: obtaining token failed", "error", err)
+		reply.Data["error"] = err.Error()
+	} else {
+		reply.Data["token"] = token
+	}
+	if err := c.Send(reply); err != nil {
+		slog.Error("auth refresh: send failed", "error", err)
+	}
+}
+
+// Reauthenticate proactively presents a fresh token to the server
+// in-band, without reconnecting.
+func (c *Client) Reauthenticate() error {
+	token, err := c.currentToken()
+	if err != nil {
+		return err
+	}
+	return c.Send(NewMessage(TypeAuthRefresh, map[string]interface{}{
+		"token": token,
+	}))
+}
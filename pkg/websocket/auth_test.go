@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileTokenReadsFreshValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("first\n"), 0o600)
+	p := FileToken(path)
+	if tok, err := p.Token(); err != nil || tok != "first" {
+		t.Fatalf("Token = %q, %v", tok, err)
+	}
+	os.WriteFile(path, []byte("rotated\n"), 0o600)
+	if tok, _ := p.Token(); tok != "rotated" {
+		t.Errorf("Token after rotation = %q, want rotated", tok)
+	}
+}
+
+func TestHandleAuthRefreshRepliesWithToken(t *testing.T) {
+	c := testClient()
+	c.SetTokenProvider(StaticToken("tok-123"))
+
+	challenge := NewMessage(TypeAuthRefresh, nil)
+	challenge.MessageID = "abc"
+	c.handleAuthRefresh(challenge)
+
+	stop := make(chan struct{})
+	close(stop)
+	reply, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("no reply queued: %v", err)
+	}
+	if reply.Type != TypeAuthRefresh || reply.MessageID != "abc" {
+		t.Errorf("reply = %+v", reply)
+	}
+	if reply.Data["token"] != "tok-123" {
+		t.Errorf("reply token = %v", reply.Data["token"])
+	}
+}
+
+func TestCurrentTokenFallsBackToConfig(t *testing.T) {
+	c := testClient()
+	c.cfg.ClientToken = "from-config"
+	if tok, _ := c.currentToken(); tok != "from-config" {
+		t.Errorf("currentToken = %q", tok)
+	}
+}
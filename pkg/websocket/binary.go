@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+)
+
+// Binary frames carry a small header instead of base64-inside-JSON,
+// cutting roughly a third of the encoding overhead for high-volume
+// streams such as terminal output and file transfer.
+//
+// Layout: [version:1][typeLen:1][type:typeLen][payload...]
+const binaryFrameVersion = 1
+
+// SendBinary queues a message whose payload is sent as a raw binary
+// WebSocket frame.
+func (c *Client) SendBinary(msgType string, payload []byte) error {
+	if len(msgType) > 255 {
+		return fmt.Errorf("message type too long for binary frame: %q", msgType)
+	}
+	msg := &Message{
+		Type:      msgType,
+		ClientID:  c.cfg.ClientID,
+		Timestamp: time.Now().Unix(),
+		Binary:    payload,
+	}
+	return c.queue.push(msg, priorityFor(msgType), c.policyFor(msgType))
+}
+
+// encodeBinaryFrame serializes a binary message into its wire form.
+func encodeBinaryFrame(msg *Message) []byte {
+	out := make([]byte, 0, 2+len(msg.Type)+len(msg.Binary))
+	out = append(out, binaryFrameVersion, byte(len(msg.Type)))
+	out = append(out, msg.Type...)
+	out = append(out, msg.Binary...)
+	return out
+}
+
+// decodeBinaryFrame parses a binary frame received from the server.
+func decodeBinaryFrame(data []byte) (*Message, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("binary frame too short")
+	}
+	if data[0] != binaryFrameVersion {
+		return nil, fmt.Errorf("unsupported binary frame version %d", data[0])
+	}
+	typeLen := int(data[1])
+	if len(data) < 2+typeLen {
+		return nil, fmt.Errorf("binary frame truncated")
+	}
+	return &Message{
+		Type:      string(data[2 : 2+typeLen]),
+		Timestamp: time.Now().Unix(),
+		Binary:    data[2+typeLen:],
+	}, nil
+}
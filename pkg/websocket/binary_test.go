@@ -0,0 +1,48 @@
+package websocket
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBinaryFrameRoundTrip(t *testing.T) {
+	payload := []byte{0x00, 0x01, 0xFF, 0xFE, 'h', 'i'}
+	frame := encodeBinaryFrame(&Message{Type: TypeTerminalOutput, Binary: payload})
+	msg, err := decodeBinaryFrame(frame)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if msg.Type != TypeTerminalOutput || !bytes.Equal(msg.Binary, payload) {
+		t.Errorf("round trip = %q/%v", msg.Type, msg.Binary)
+	}
+}
+
+func TestDecodeBinaryFrameErrors(t *testing.T) {
+	cases := [][]byte{
+		nil,
+		{binaryFrameVersion},
+		{99, 2, 'h', 'i'},             // bad version
+		{binaryFrameVersion, 10, 'x'}, // truncated type
+	}
+	for i, data := range cases {
+		if _, err := decodeBinaryFrame(data); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestSendBinaryQueuesAtTypePriority(t *testing.T) {
+	c := testClient()
+	if err := c.SendBinary(TypeTerminalOutput, []byte("data")); err != nil {
+		t.Fatalf("SendBinary: %v", err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	msg, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	if msg.Binary == nil || msg.Type != TypeTerminalOutput {
+		t.Errorf("queued message = %+v", msg)
+	}
+}
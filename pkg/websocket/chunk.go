@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TypeChunk carries one segment of a message that exceeded the frame
+// size limit.
+const TypeChunk = "chunk"
+
+// chunkAssembleTimeout is how long a partially received message is kept
+// before its segments are discarded.
+const chunkAssembleTimeout = 2 * time.Minute
+
+// splitMessage slices the serialized message into numbered chunk
+// messages of at most maxSize payload bytes each. Chunking operates on
+// the full envelope so reassembly restores the message verbatim.
+func splitMessage(serialized []byte, maxSize int) []*Message {
+	// Base64 inflates the payload by 4/3; size the raw segments so the
+	// encoded chunk stays under the limit.
+	segment := maxSize * 3 / 4
+	if segment < 1 {
+		segment = 1
+	}
+	total := (len(serialized) + segment - 1) / segment
+	id := newMessageID()
+	chunks := make([]*Message, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * segment
+		if end > len(serialized) {
+			end = len(serialized)
+		}
+		chunks = append(chunks, NewMessage(TypeChunk, map[string]interface{}{
+			"chunk_id": id,
+			"seq":      i,
+			"total":    total,
+			"payload":  base64.StdEncoding.EncodeToString(serialized[i*segment : end]),
+		}))
+	}
+	return chunks
+}
+
+// chunkAssembler reassembles inbound chunk messages into their original
+// form.
+type chunkAssembler struct {
+	mu      sync.Mutex
+	partial map[string]*partialMessage
+}
+
+type partialMessage struct {
+	segments [][]byte
+	received int
+	started  time.Time
+}
+
+func newChunkAssembler() *chunkAssembler {
+	return &chunkAssembler{partial: make(map[string]*partialMessage)}
+}
+
+// add ingests one chunk and returns the fully reassembled message when
+// the last segment arrives, or nil while the message is incomplete.
+func (a *chunkAssembler) add(msg *Message) (*Message, error) {
+	id, _ := msg.Data["chunk_id"].(string)
+	seqF, _ := msg.Data["seq"].(float64)
+	totalF, _ := msg.Data["total"].(float64)
+	payload, _ := msg.Data["payload"].(string)
+	seq, total := int(seqF), int(totalF)
+	if id == "" || total < 1 || seq < 0 || seq >= total {
+		return nil, fmt.Errorf("malformed chunk (id=%q seq=%d total=%d)", id, seq, total)
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("chunk payload: %w", err)
+	}
+
+	a.mu.Lock()
+	a.evictStaleLocked()
+	p := a.partial[id]
+	if p == nil {
+		p = &partialMessage{segments: make([][]byte, total), started: time.Now()}
+		a.partial[id] = p
+	}
+	if total != len(p.segments) {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("chunk %s: inconsistent total", id)
+	}
+	if p.segments[seq] == nil {
+		p.segments[seq] = raw
+		p.received++
+	}
+	complete := p.received == len(p.segments)
+	if complete {
+		delete(a.partial, id)
+	}
+	a.mu.Unlock()
+
+	if !complete {
+		return nil, nil
+	}
+	var full []byte
+	for _, s := range p.segments {
+		full = append(full, s...)
+	}
+	out := &Message{}
+	if err := json.Unmarshal(full, out); err != nil {
+		return nil, fmt.Errorf("reassembled message: %w", err)
+	}
+	return out, nil
+}
+
+func (a *chunkAssembler) evictStaleLocked() {
+	for id, p := range a.partial {
+		if time.Since(p.started) > chunkAssembleTimeout {
+			delete(a.partial, id)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestChunkRoundTrip(t *testing.T) {
+	original := NewMessage(TypeBMCInventory, map[string]interface{}{
+		"raw_data": strings.Repeat("x", 10000),
+	})
+	serialized, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := splitMessage(serialized, 1024)
+	if len(chunks) < 2 {
+		t.Fatalf("splitMessage produced %d chunks, want several", len(chunks))
+	}
+
+	a := newChunkAssembler()
+	var got *Message
+	// Deliver out of order to exercise reordering.
+	for i := len(chunks) - 1; i >= 0; i-- {
+		// Simulate the JSON round trip that turns seq/total into
+		// float64 on the receiving side.
+		raw, _ := json.Marshal(chunks[i])
+		inbound := &Message{}
+		json.Unmarshal(raw, inbound)
+		full, err := a.add(inbound)
+		if err != nil {
+			t.Fatalf("add chunk %d: %v", i, err)
+		}
+		if full != nil {
+			got = full
+		}
+	}
+	if got == nil {
+		t.Fatal("message never completed")
+	}
+	if got.Type != original.Type || got.Data["raw_data"] != original.Data["raw_data"] {
+		t.Error("reassembled message differs from original")
+	}
+	if len(a.partial) != 0 {
+		t.Errorf("partial map not cleaned up: %d entries", len(a.partial))
+	}
+}
+
+func TestChunkSizeStaysUnderLimit(t *testing.T) {
+	serialized := []byte(strings.Repeat("y", 50000))
+	for _, chunk := range splitMessage(serialized, 4096) {
+		payload, _ := chunk.Data["payload"].(string)
+		if len(payload) > 4096 {
+			t.Fatalf("chunk payload %d bytes exceeds limit", len(payload))
+		}
+	}
+}
+
+func TestAssemblerRejectsMalformedChunk(t *testing.T) {
+	a := newChunkAssembler()
+	if _, err := a.add(NewMessage(TypeChunk, map[string]interface{}{})); err == nil {
+		t.Error("expected error for chunk without metadata")
+	}
+}
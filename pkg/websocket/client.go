@@ -0,0 +1,541 @@
+// Package websocket implements the agent's connection to the management
+// server: connecting, dispatching inbound messages to registered
+// handlers, and writing outbound messages from prioritized send queues.
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/simonschuang/demo/internal/certpin"
+	"github.com/simonschuang/demo/internal/ws"
+	"github.com/simonschuang/demo/pkg/config"
+)
+
+const (
+	// writeDeadline bounds a single frame write.
+	writeDeadline = 10 * time.Second
+	// sendQueueSize is the per-priority outbound queue capacity.
+	sendQueueSize = 256
+)
+
+// MessageHandler processes one inbound message.
+type MessageHandler func(*Message)
+
+// Client maintains the WebSocket connection to the management server.
+type Client struct {
+	cfg *config.Config
+
+	mu   sync.Mutex
+	conn *ws.Conn
+
+	queue   *sendQueue
+	limiter *rateLimiter
+	dedup   *deduper
+
+	handlersMu    sync.RWMutex
+	handlers      map[string][]*handlerEntry
+	nextHandlerID int
+
+	middlewareMu sync.RWMutex
+	outbound     []Middleware
+	inbound      []Middleware
+
+	states *stateFeed
+	// attempts counts consecutive connection tries since the last
+	// success, distinguishing reconnects from the first connect.
+	attempts int
+
+	pending   *pendingRequests
+	assembler *chunkAssembler
+	stats     *stats
+	caps      *capabilities
+
+	tokenProvider TokenProvider
+
+	sessionMu sync.Mutex
+	session   string
+
+	pingInterval time.Duration
+	readDeadline time.Duration
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewClient builds a client from the agent configuration.
+func NewClient(cfg *config.Config) *Client {
+	queueSize := cfg.SendQueue.Size
+	if queueSize <= 0 {
+		queueSize = sendQueueSize
+	}
+	c := &Client{
+		cfg:       cfg,
+		queue:     newSendQueue(queueSize),
+		limiter:   newRateLimiter(cfg.RateLimit.BytesPerSec, cfg.RateLimit.MessagesPerSec),
+		handlers:  make(map[string][]*handlerEntry),
+		pending:   newPendingRequests(),
+		assembler: newChunkAssembler(),
+		stats:     newStats(),
+		caps:      newCapabilities(),
+		states:    newStateFeed(),
+		done:      make(chan struct{}),
+
+		pingInterval: config.DefaultPingInterval * time.Second,
+		readDeadline: config.DefaultReadDeadline * time.Second,
+	}
+	if cfg.PingInterval > 0 {
+		c.pingInterval = time.Duration(cfg.PingInterval) * time.Second
+	}
+	if cfg.ReadDeadline > 0 {
+		c.readDeadline = time.Duration(cfg.ReadDeadline) * time.Second
+	}
+	if cfg.Dedup.Enabled {
+		c.dedup = newDeduper(cfg.Dedup.Types,
+			time.Duration(cfg.Dedup.MaxAgeSeconds)*time.Second)
+	}
+	if cfg.SendQueue.BlockTimeoutMS > 0 {
+		c.queue.blockWait = time.Duration(cfg.SendQueue.BlockTimeoutMS) * time.Millisecond
+	}
+	c.RegisterHandler(TypeAuthRefresh, c.handleAuthRefresh)
+	c.RegisterHandler(TypeWelcome, c.handleWelcome)
+	return c
+}
+
+// Middleware inspects or rewrites a message on its way through the
+// client. Returning nil drops the message.
+type Middleware func(*Message) *Message
+
+// UseOutbound appends a middleware applied to every outbound message
+// just before it is written, in registration order. All producers
+// (terminal, heartbeat, inventory) pass through it transparently.
+func (c *Client) UseOutbound(m Middleware) {
+	c.middlewareMu.Lock()
+	defer c.middlewareMu.Unlock()
+	c.outbound = append(c.outbound, m)
+}
+
+// UseInbound appends a middleware applied to every inbound message
+// before handler dispatch, in registration order.
+func (c *Client) UseInbound(m Middleware) {
+	c.middlewareMu.Lock()
+	defer c.middlewareMu.Unlock()
+	c.inbound = append(c.inbound, m)
+}
+
+// applyMiddleware runs msg through the chain, returning nil when a
+// middleware drops it.
+func (c *Client) applyMiddleware(chain []Middleware, msg *Message) *Message {
+	for _, m := range chain {
+		if msg = m(msg); msg == nil {
+			return nil
+		}
+	}
+	return msg
+}
+
+func (c *Client) outboundChain() []Middleware {
+	c.middlewareMu.RLock()
+	defer c.middlewareMu.RUnlock()
+	return c.outbound
+}
+
+func (c *Client) inboundChain() []Middleware {
+	c.middlewareMu.RLock()
+	defer c.middlewareMu.RUnlock()
+	return c.inbound
+}
+
+// TypeWildcard subscribes a handler to every inbound message type.
+const TypeWildcard = "*"
+
+type handlerEntry struct {
+	id int
+	h  MessageHandler
+}
+
+// RegisterHandler subscribes a handler to inbound messages of the given
+// type. Multiple handlers may subscribe to the same type and run in
+// registration order; the wildcard type "*" receives every message.
+// The returned function unsubscribes the handler.
+func (c *Client) RegisterHandler(msgType string, h MessageHandler) func() {
+	c.handlersMu.Lock()
+	c.nextHandlerID++
+	entry := &handlerEntry{id: c.nextHandlerID, h: h}
+	c.handlers[msgType] = append(c.handlers[msgType], entry)
+	c.handlersMu.Unlock()
+	return func() {
+		c.handlersMu.Lock()
+		defer c.handlersMu.Unlock()
+		entries := c.handlers[msgType]
+		for i, e := range entries {
+			if e.id == entry.id {
+				c.handlers[msgType] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Connect dials the server and starts the read and write pumps. It
+// returns once the connection is established.
+func (c *Client) Connect() error {
+	c.mu.Lock()
+	c.attempts++
+	attempt := c.attempts
+	c.mu.Unlock()
+	if attempt == 1 {
+		c.states.emit(StateEvent{State: StateConnecting, Attempt: attempt})
+	} else {
+		c.states.emit(StateEvent{State: StateReconnecting, Attempt: attempt})
+	}
+	tlsConfig, err := c.cfg.TLS.Build()
+	if err != nil {
+		return fmt.Errorf("tls configuration: %w", err)
+	}
+	// The legacy top-level boolean still works.
+	if c.cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	certpin.Apply(tlsConfig, c.cfg.PinSHA256)
+	dialer := &ws.Dialer{
+		HandshakeTimeout: 15 * time.Second,
+		TLSConfig:        tlsConfig,
+	}
+	if c.cfg.ProxyEnabled() {
+		dialer.Proxy = http.ProxyFromEnvironment
+	}
+	header := http.Header{}
+	token, err := c.currentToken()
+	if err != nil {
+		return fmt.Errorf("obtain client token: %w", err)
+	}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	header.Set("X-Client-ID", c.cfg.ClientID)
+	for k, v := range c.cfg.Headers {
+		header.Set(k, v)
+	}
+
+	dialURL, err := c.dialURL()
+	if err != nil {
+		return err
+	}
+	conn, _, err := dialer.Dial(dialURL, header)
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", c.cfg.ServerURL, err)
+	}
+	c.mu.Lock()
+	c.conn = conn
+	c.attempts = 0
+	c.mu.Unlock()
+
+	c.stats.recordConnect()
+	// The server may have lost our state while we were away; do not
+	// suppress the first post-reconnect sends.
+	c.dedup.reset()
+	slog.Info("connected to server", "url", c.cfg.ServerURL)
+	go c.readPump(conn)
+	go c.writePump(conn)
+	c.sendHello()
+	c.states.emit(StateEvent{State: StateConnected})
+	return nil
+}
+
+// Connected reports whether the client currently has a live connection.
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+// dialURL builds the server URL with any configured extra query
+// parameters appended.
+func (c *Client) dialURL() (string, error) {
+	if len(c.cfg.QueryParams) == 0 {
+		return c.cfg.ServerURL, nil
+	}
+	u, err := url.Parse(c.cfg.ServerURL)
+	if err != nil {
+		return "", fmt.Errorf("parse server URL: %w", err)
+	}
+	q := u.Query()
+	for k, v := range c.cfg.QueryParams {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// stamp fills in the sender identity on an outbound message: the
+// client ID and any configured fleet labels.
+func (c *Client) stamp(msg *Message) {
+	if msg.ClientID == "" {
+		msg.ClientID = c.cfg.ClientID
+	}
+	if msg.Labels == nil && len(c.cfg.Labels) > 0 {
+		msg.Labels = c.cfg.Labels
+	}
+}
+
+// Send queues a message for delivery. The message's priority is derived
+// from its type. Send never blocks; it returns ErrQueueFull when the
+// priority queue has no room and ErrQueueClosed once the client is
+// draining or shut down.
+func (c *Client) Send(msg *Message) error {
+	c.stamp(msg)
+	if !c.dedup.shouldSend(msg) {
+		slog.Debug("suppressing unchanged message", "type", msg.Type)
+		return nil
+	}
+	return c.queue.push(msg, priorityFor(msg.Type), c.policyFor(msg.Type))
+}
+
+// policyFor resolves the configured overflow policy for a message type.
+func (c *Client) policyFor(msgType string) overflowPolicy {
+	switch c.cfg.SendQueue.Overflow[msgType] {
+	case "drop_oldest":
+		return dropOldest
+	case "block":
+		return blockTimeout
+	default:
+		return dropNew
+	}
+}
+
+// SendWithPriority queues a message at an explicit priority, overriding
+// the type-derived default.
+func (c *Client) SendWithPriority(msg *Message, p Priority) error {
+	c.stamp(msg)
+	if p < 0 || p >= numPriorities {
+		return fmt.Errorf("invalid priority %d", p)
+	}
+	return c.queue.push(msg, p, c.policyFor(msg.Type))
+}
+
+// Drain stops accepting new outbound messages, waits up to timeout for
+// the send queues to empty, and then closes the connection cleanly.
+// Producers should be stopped before calling Drain so the queue can
+// only shrink.
+func (c *Client) Drain(timeout time.Duration) {
+	c.queue.startDrain()
+	deadline := time.Now().Add(timeout)
+	for c.queue.depth() > 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := c.queue.depth(); n > 0 {
+		slog.Warn("drain deadline exceeded", "undelivered", n)
+	}
+	c.Close()
+}
+
+// Close tears down the connection and stops the pumps.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.queue.close()
+		c.mu.Lock()
+		if c.conn != nil {
+			c.conn.WriteControl(ws.CloseMessage,
+				ws.FormatCloseMessage(1000, ""), time.Now().Add(time.Second))
+			c.conn.Close()
+		}
+		c.mu.Unlock()
+	})
+}
+
+// readPump reads inbound messages and dispatches them to handlers until
+// the connection fails.
+func (c *Client) readPump(conn *ws.Conn) {
+	defer c.disconnect(conn, nil)
+	// Any frame counts as life, including the pongs answering our
+	// pings, so an idle but healthy connection is never declared dead.
+	conn.SetReadIdleTimeout(c.readDeadline)
+	for {
+		frameType, data, err := conn.ReadMessage()
+		if err != nil {
+			c.disconnect(conn, err)
+			return
+		}
+		var msg *Message
+		if frameType == ws.BinaryMessage {
+			msg, err = decodeBinaryFrame(data)
+			if err != nil {
+				slog.Warn("discarding binary frame", "error", err)
+				continue
+			}
+		} else {
+			msg = &Message{}
+			if err := json.Unmarshal(data, msg); err != nil {
+				slog.Warn("discarding malformed message", "error", err)
+				continue
+			}
+		}
+		c.stats.recordReceived(msg.Type, len(data))
+		if msg.Type == TypeChunk {
+			full, err := c.assembler.add(msg)
+			if err != nil {
+				slog.Warn("discarding chunk", "error", err)
+				continue
+			}
+			if full == nil {
+				continue
+			}
+			msg = full
+		}
+		if msg = c.applyMiddleware(c.inboundChain(), msg); msg == nil {
+			continue
+		}
+		if c.pending.deliver(msg) {
+			continue
+		}
+		c.dispatch(msg)
+	}
+}
+
+func (c *Client) dispatch(msg *Message) {
+	c.handlersMu.RLock()
+	entries := append([]*handlerEntry(nil), c.handlers[msg.Type]...)
+	entries = append(entries, c.handlers[TypeWildcard]...)
+	c.handlersMu.RUnlock()
+	if len(entries) == 0 {
+		slog.Debug("no handler for message type", "type", msg.Type)
+		return
+	}
+	for _, e := range entries {
+		c.safeInvoke(e.h, msg)
+	}
+}
+
+// safeInvoke runs one handler, converting a panic into a logged error
+// and an agent_error report to the server instead of letting it kill
+// readPump.
+func (c *Client) safeInvoke(h MessageHandler, msg *Message) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stack := debug.Stack()
+		slog.Error("handler panicked", "type", msg.Type, "panic", r,
+			"stack", string(stack))
+		report := NewMessage(TypeAgentError, map[string]interface{}{
+			"source":       "message_handler",
+			"message_type": msg.Type,
+			"panic":        fmt.Sprint(r),
+			"stack":        string(stack),
+		})
+		if err := c.Send(report); err != nil {
+			slog.Warn("agent_error send failed", "error", err)
+		}
+	}()
+	h(msg)
+}
+
+// writePump drains the send queues in priority order and keeps the
+// connection alive with periodic pings.
+func (c *Client) writePump(conn *ws.Conn) {
+	pingTicker := time.NewTicker(c.pingInterval)
+	defer pingTicker.Stop()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-pingTicker.C:
+				conn.WriteControl(ws.PingMessage, nil, time.Now().Add(writeDeadline))
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for {
+		msg, err := c.queue.pop(c.done)
+		if err != nil {
+			return
+		}
+		if msg = c.applyMiddleware(c.outboundChain(), msg); msg == nil {
+			continue
+		}
+		if msg.Binary != nil && !c.caps.supportsBinary() {
+			msg = binaryFallback(msg)
+		}
+		if msg.Binary != nil {
+			data := encodeBinaryFrame(msg)
+			c.limiter.wait(len(data))
+			conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err := conn.WriteMessage(ws.BinaryMessage, data); err != nil {
+				c.disconnect(conn, err)
+				return
+			}
+			c.stats.recordSent(msg.Type, len(data))
+			continue
+		}
+		data, err := json.Marshal(msg)
+		if err != nil {
+			slog.Warn("dropping unmarshalable message", "type", msg.Type, "error", err)
+			continue
+		}
+		if max := c.cfg.MaxMessageSize; max > 0 && len(data) > max && msg.Type != TypeChunk && c.caps.supportsChunking() {
+			if err := c.writeChunked(conn, data, max); err != nil {
+				c.disconnect(conn, err)
+				return
+			}
+			continue
+		}
+		c.limiter.wait(len(data))
+		conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+		if err := conn.WriteMessage(ws.TextMessage, data); err != nil {
+			c.disconnect(conn, err)
+			return
+		}
+		c.stats.recordSent(msg.Type, len(data))
+	}
+}
+
+// writeChunked splits an oversized serialized message and writes the
+// chunk frames in sequence.
+func (c *Client) writeChunked(conn *ws.Conn, data []byte, max int) error {
+	for _, chunk := range splitMessage(data, max) {
+		chunkData, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		c.limiter.wait(len(chunkData))
+		conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+		if err := conn.WriteMessage(ws.TextMessage, chunkData); err != nil {
+			return err
+		}
+		c.stats.recordSent(TypeChunk, len(chunkData))
+	}
+	return nil
+}
+
+// disconnect closes the connection once and notifies the disconnect
+// callback.
+func (c *Client) disconnect(conn *ws.Conn, err error) {
+	c.mu.Lock()
+	current := c.conn == conn
+	if current {
+		c.conn = nil
+	}
+	c.mu.Unlock()
+	if !current {
+		return
+	}
+	conn.Close()
+	if err != nil {
+		c.stats.recordError(err)
+		slog.Warn("connection lost", "error", err)
+	}
+	c.states.emit(StateEvent{State: StateDisconnected, Err: err})
+}
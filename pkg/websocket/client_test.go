@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simonschuang/demo/pkg/config"
+)
+
+func TestDialURLAppendsQueryParams(t *testing.T) {
+	c := NewClient(&config.Config{
+		ServerURL:   "wss://mgmt.example.com/agent?existing=1",
+		QueryParams: map[string]string{"tenant": "t1", "site": "lab"},
+	})
+	got, err := c.dialURL()
+	if err != nil {
+		t.Fatalf("dialURL: %v", err)
+	}
+	for _, want := range []string{"existing=1", "tenant=t1", "site=lab"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dialURL = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestSendStampsConfiguredLabels(t *testing.T) {
+	c := NewClient(&config.Config{
+		ClientID: "test",
+		Labels:   map[string]string{"site": "lab1", "rack": "r42"},
+	})
+	if err := c.Send(NewMessage(TypeHeartbeat, nil)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	msg, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	if msg.Labels["site"] != "lab1" || msg.Labels["rack"] != "r42" {
+		t.Errorf("Labels = %v", msg.Labels)
+	}
+	if msg.ClientID != "test" {
+		t.Errorf("ClientID = %q", msg.ClientID)
+	}
+}
+
+func TestSendWithoutLabelsLeavesMessageBare(t *testing.T) {
+	c := testClient()
+	c.Send(NewMessage(TypeHeartbeat, nil))
+	stop := make(chan struct{})
+	close(stop)
+	msg, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	if msg.Labels != nil {
+		t.Errorf("Labels = %v, want nil", msg.Labels)
+	}
+}
+
+func TestMultipleHandlersAndUnsubscribe(t *testing.T) {
+	c := testClient()
+	var order []string
+	c.RegisterHandler("evt", func(*Message) { order = append(order, "a") })
+	unsub := c.RegisterHandler("evt", func(*Message) { order = append(order, "b") })
+	c.RegisterHandler(TypeWildcard, func(*Message) { order = append(order, "w") })
+
+	c.dispatch(NewMessage("evt", nil))
+	if want := []string{"a", "b", "w"}; len(order) != 3 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+		t.Fatalf("dispatch order = %v, want %v", order, want)
+	}
+
+	unsub()
+	unsub() // double-unsubscribe is a no-op
+	order = nil
+	c.dispatch(NewMessage("evt", nil))
+	if len(order) != 2 || order[0] != "a" || order[1] != "w" {
+		t.Errorf("after unsubscribe order = %v, want [a w]", order)
+	}
+}
+
+func TestWildcardHandlerSeesUnknownTypes(t *testing.T) {
+	c := testClient()
+	var got []string
+	c.RegisterHandler(TypeWildcard, func(m *Message) { got = append(got, m.Type) })
+	c.dispatch(NewMessage("never_registered", nil))
+	if len(got) != 1 || got[0] != "never_registered" {
+		t.Errorf("wildcard saw %v", got)
+	}
+}
+
+func TestPanickingHandlerIsRecovered(t *testing.T) {
+	c := testClient()
+	var after bool
+	c.RegisterHandler("boom", func(*Message) { panic("malformed data") })
+	c.RegisterHandler("boom", func(*Message) { after = true })
+
+	c.dispatch(NewMessage("boom", nil)) // must not panic
+	if !after {
+		t.Error("handler after the panicking one did not run")
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	report, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("no agent_error queued: %v", err)
+	}
+	if report.Type != TypeAgentError || report.Data["panic"] != "malformed data" {
+		t.Errorf("report = %+v", report)
+	}
+	if report.Data["stack"] == "" {
+		t.Error("report has no stack trace")
+	}
+}
+
+func TestDialURLWithoutParamsIsUnchanged(t *testing.T) {
+	c := NewClient(&config.Config{ServerURL: "wss://mgmt.example.com/agent"})
+	got, err := c.dialURL()
+	if err != nil || got != "wss://mgmt.example.com/agent" {
+		t.Errorf("dialURL = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// deduper suppresses resends of messages whose payload has not changed
+// since the last delivery, with a max age forcing a periodic full send
+// so the server never works from indefinitely stale data.
+type deduper struct {
+	mu       sync.Mutex
+	types    map[string]bool
+	maxAge   time.Duration
+	lastHash map[string]string
+	lastSent map[string]time.Time
+}
+
+// newDeduper builds a deduper for the given message types. A nil
+// deduper (disabled) passes everything through.
+func newDeduper(types []string, maxAge time.Duration) *deduper {
+	d := &deduper{
+		types:    make(map[string]bool, len(types)),
+		maxAge:   maxAge,
+		lastHash: make(map[string]string),
+		lastSent: make(map[string]time.Time),
+	}
+	for _, t := range types {
+		d.types[t] = true
+	}
+	return d
+}
+
+// shouldSend reports whether msg must be delivered, recording its hash
+// when it is. Messages of undeduplicated types always pass.
+func (d *deduper) shouldSend(msg *Message) bool {
+	if d == nil || !d.types[msg.Type] {
+		return true
+	}
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return true
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, sent := d.lastHash[msg.Type], d.lastSent[msg.Type]
+	if last == hash && time.Since(sent) < d.maxAge {
+		return false
+	}
+	d.lastHash[msg.Type] = hash
+	d.lastSent[msg.Type] = time.Now()
+	return true
+}
+
+// reset forgets delivery history, forcing the next message of every
+// type through. Called on reconnect since the new server side may have
+// no state.
+func (d *deduper) reset() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.lastHash = make(map[string]string)
+	d.lastSent = make(map[string]time.Time)
+	d.mu.Unlock()
+}
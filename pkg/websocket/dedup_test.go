@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduperSuppressesUnchangedPayload(t *testing.T) {
+	d := newDeduper([]string{TypeInventory}, time.Hour)
+	msg := NewMessage(TypeInventory, map[string]interface{}{"hostname": "n1"})
+	if !d.shouldSend(msg) {
+		t.Fatal("first send suppressed")
+	}
+	if d.shouldSend(msg) {
+		t.Error("identical resend not suppressed")
+	}
+	changed := NewMessage(TypeInventory, map[string]interface{}{"hostname": "n2"})
+	if !d.shouldSend(changed) {
+		t.Error("changed payload suppressed")
+	}
+}
+
+func TestDeduperMaxAgeForcesResend(t *testing.T) {
+	d := newDeduper([]string{TypeInventory}, 10*time.Millisecond)
+	msg := NewMessage(TypeInventory, map[string]interface{}{"a": 1})
+	d.shouldSend(msg)
+	time.Sleep(20 * time.Millisecond)
+	if !d.shouldSend(msg) {
+		t.Error("stale duplicate still suppressed after max age")
+	}
+}
+
+func TestDeduperIgnoresOtherTypesAndReset(t *testing.T) {
+	d := newDeduper([]string{TypeInventory}, time.Hour)
+	hb := NewMessage(TypeHeartbeat, map[string]interface{}{"x": 1})
+	if !d.shouldSend(hb) || !d.shouldSend(hb) {
+		t.Error("undeduplicated type was suppressed")
+	}
+	inv := NewMessage(TypeInventory, map[string]interface{}{"a": 1})
+	d.shouldSend(inv)
+	d.reset()
+	if !d.shouldSend(inv) {
+		t.Error("duplicate suppressed after reset")
+	}
+}
+
+func TestNilDeduperPassesThrough(t *testing.T) {
+	var d *deduper
+	if !d.shouldSend(NewMessage(TypeInventory, nil)) {
+		t.Error("nil deduper suppressed a message")
+	}
+	d.reset() // must not panic
+}
@@ -0,0 +1,96 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnState is a connection lifecycle state.
+type ConnState int
+
+const (
+	// StateConnecting is emitted when a first connection attempt starts.
+	StateConnecting ConnState = iota
+	// StateReconnecting is emitted when a connection attempt follows a
+	// failure or disconnect; Attempt counts consecutive tries.
+	StateReconnecting
+	// StateConnected is emitted after a successful handshake.
+	StateConnected
+	// StateDisconnected is emitted when the connection drops; Err
+	// carries the reason when one is known.
+	StateDisconnected
+)
+
+// String returns the state name for logging.
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+// StateEvent describes one connection lifecycle transition.
+type StateEvent struct {
+	State   ConnState
+	Err     error
+	Attempt int
+	Time    time.Time
+}
+
+// stateFeed fans state events out to subscribers. Slow subscribers drop
+// events rather than blocking the connection path.
+type stateFeed struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan StateEvent
+}
+
+func newStateFeed() *stateFeed {
+	return &stateFeed{subs: make(map[int]chan StateEvent)}
+}
+
+func (f *stateFeed) subscribe(buffer int) (<-chan StateEvent, func()) {
+	if buffer < 1 {
+		buffer = 16
+	}
+	ch := make(chan StateEvent, buffer)
+	f.mu.Lock()
+	f.nextID++
+	id := f.nextID
+	f.subs[id] = ch
+	f.mu.Unlock()
+	return ch, func() {
+		f.mu.Lock()
+		if sub, ok := f.subs[id]; ok {
+			delete(f.subs, id)
+			close(sub)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *stateFeed) emit(ev StateEvent) {
+	ev.Time = time.Now()
+	f.mu.Lock()
+	for _, ch := range f.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	f.mu.Unlock()
+}
+
+// SubscribeState returns a channel of connection state events and an
+// unsubscribe function. Events are dropped, not queued indefinitely,
+// when the subscriber falls behind the buffer.
+func (c *Client) SubscribeState(buffer int) (<-chan StateEvent, func()) {
+	return c.states.subscribe(buffer)
+}
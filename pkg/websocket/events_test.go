@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStateFeedFanOutAndUnsubscribe(t *testing.T) {
+	f := newStateFeed()
+	ch1, unsub1 := f.subscribe(4)
+	ch2, _ := f.subscribe(4)
+
+	f.emit(StateEvent{State: StateConnected})
+	for i, ch := range []<-chan StateEvent{ch1, ch2} {
+		ev := <-ch
+		if ev.State != StateConnected || ev.Time.IsZero() {
+			t.Errorf("subscriber %d got %+v", i, ev)
+		}
+	}
+
+	unsub1()
+	if _, open := <-ch1; open {
+		t.Error("unsubscribed channel not closed")
+	}
+	f.emit(StateEvent{State: StateDisconnected, Err: errors.New("eof")})
+	if ev := <-ch2; ev.State != StateDisconnected || ev.Err == nil {
+		t.Errorf("remaining subscriber got %+v", ev)
+	}
+}
+
+func TestStateFeedDropsWhenSubscriberLags(t *testing.T) {
+	f := newStateFeed()
+	ch, _ := f.subscribe(1)
+	f.emit(StateEvent{State: StateConnecting})
+	f.emit(StateEvent{State: StateConnected}) // dropped, buffer full
+	if ev := <-ch; ev.State != StateConnecting {
+		t.Errorf("got %v", ev.State)
+	}
+	select {
+	case ev := <-ch:
+		t.Errorf("unexpected second event %v", ev.State)
+	default:
+	}
+}
+
+func TestConnStateString(t *testing.T) {
+	if StateReconnecting.String() != "reconnecting" || ConnState(99).String() != "unknown" {
+		t.Error("String() mapping wrong")
+	}
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Well-known message types exchanged with the server.
+const (
+	TypeWelcome         = "welcome"
+	TypeHeartbeat       = "heartbeat"
+	TypeInventory       = "inventory"
+	TypeInventoryDelta  = "inventory_delta"
+	TypeMetrics         = "metrics"
+	TypeBMCInventory    = "bmc_inventory"
+	TypeBMCEvent        = "bmc_event"
+	TypeAlert           = "alert"
+	TypeBMCDiscovery    = "bmc_discovery"
+	TypeTerminalCommand = "terminal_command"
+	TypeTerminalOutput  = "terminal_output"
+	TypeExecCommand     = "exec_command"
+	TypeExecOutput      = "exec_output"
+	TypeFilePut         = "file_put"
+	TypeFileGet         = "file_get"
+	TypeFileChunk       = "file_chunk"
+	TypeFileDone        = "file_done"
+	TypeError           = "error"
+	TypeAgentError      = "agent_error"
+)
+
+// Message is the envelope for everything exchanged over the WebSocket
+// connection.
+type Message struct {
+	Type      string                 `json:"type"`
+	MessageID string                 `json:"message_id,omitempty"`
+	ClientID  string                 `json:"client_id,omitempty"`
+	Timestamp int64                  `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	// Labels carries the agent's configured fleet labels (site, rack,
+	// environment, ...) so the server can slice the fleet without its
+	// own host mapping.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Binary, when non-nil, is sent as a raw binary frame instead of
+	// the JSON envelope. It is never JSON-encoded.
+	Binary []byte `json:"-"`
+}
+
+// NewMessage builds a message of the given type with the current
+// timestamp.
+func NewMessage(msgType string, data map[string]interface{}) *Message {
+	return &Message{
+		Type:      msgType,
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+}
+
+// newMessageID returns a random identifier for request/response
+// correlation.
+func newMessageID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Priority orders outbound messages. Lower values are sent first so
+// interactive and control traffic is never starved behind bulk payloads.
+type Priority int
+
+const (
+	// PriorityControl covers protocol and command/ack traffic.
+	PriorityControl Priority = iota
+	// PriorityTerminal covers interactive terminal output.
+	PriorityTerminal
+	// PriorityHeartbeat covers liveness messages.
+	PriorityHeartbeat
+	// PriorityInventory covers bulk inventory payloads.
+	PriorityInventory
+
+	numPriorities
+)
+
+// priorityFor maps a message type to its outbound priority. Unknown
+// types are treated as control traffic so new low-volume message types
+// stay responsive by default.
+func priorityFor(msgType string) Priority {
+	switch msgType {
+	case TypeTerminalOutput, TypeTerminalCommand, TypeExecOutput:
+		return PriorityTerminal
+	case TypeHeartbeat, TypeMetrics:
+		return PriorityHeartbeat
+	case TypeInventory, TypeInventoryDelta, TypeBMCInventory, TypeFileChunk:
+		return PriorityInventory
+	default:
+		return PriorityControl
+	}
+}
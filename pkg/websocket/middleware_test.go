@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/simonschuang/demo/pkg/config"
+)
+
+func testClient() *Client {
+	return NewClient(&config.Config{ClientID: "test"})
+}
+
+func TestMiddlewareChainOrderAndDrop(t *testing.T) {
+	c := testClient()
+	c.UseOutbound(func(m *Message) *Message {
+		m.Data["first"] = true
+		return m
+	})
+	c.UseOutbound(func(m *Message) *Message {
+		if m.Data["first"] != true {
+			t.Error("middleware ran out of order")
+		}
+		if m.Type == TypeHeartbeat {
+			return nil
+		}
+		return m
+	})
+
+	kept := c.applyMiddleware(c.outboundChain(), NewMessage(TypeInventory, map[string]interface{}{}))
+	if kept == nil || kept.Data["first"] != true {
+		t.Errorf("inventory message = %+v, want enriched and kept", kept)
+	}
+	if dropped := c.applyMiddleware(c.outboundChain(), NewMessage(TypeHeartbeat, map[string]interface{}{})); dropped != nil {
+		t.Error("heartbeat message should have been dropped")
+	}
+}
+
+func TestInboundMiddlewareSeesMessageBeforeDispatch(t *testing.T) {
+	c := testClient()
+	c.UseInbound(func(m *Message) *Message {
+		m.Data["seen"] = true
+		return m
+	})
+	var got *Message
+	c.RegisterHandler("ping", func(m *Message) { got = m })
+
+	msg := NewMessage("ping", map[string]interface{}{})
+	if msg = c.applyMiddleware(c.inboundChain(), msg); msg != nil {
+		c.dispatch(msg)
+	}
+	if got == nil || got.Data["seen"] != true {
+		t.Errorf("handler message = %+v, want enriched", got)
+	}
+}
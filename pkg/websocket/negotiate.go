@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ProtocolVersion is the agent's wire protocol revision, announced in
+// the hello message after each connect.
+const ProtocolVersion = 1
+
+// TypeHello announces the agent's protocol version and capabilities.
+const TypeHello = "hello"
+
+// Capability names the agent can announce and the server can accept.
+const (
+	CapBinaryFrames = "binary_frames"
+	CapChunking     = "chunking"
+	CapPriority     = "priority"
+)
+
+// capabilities tracks what the connected server supports. Everything is
+// assumed supported until a welcome message says otherwise, so agents
+// talking to servers predating negotiation keep full functionality.
+type capabilities struct {
+	mu        sync.RWMutex
+	announced bool
+	binary    bool
+	chunking  bool
+}
+
+func newCapabilities() *capabilities {
+	return &capabilities{binary: true, chunking: true}
+}
+
+func (n *capabilities) supportsBinary() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.binary
+}
+
+func (n *capabilities) supportsChunking() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.chunking
+}
+
+// update applies the server's advertised capability list.
+func (n *capabilities) update(caps []interface{}) {
+	supported := map[string]bool{}
+	for _, c := range caps {
+		if s, ok := c.(string); ok {
+			supported[s] = true
+		}
+	}
+	n.mu.Lock()
+	n.announced = true
+	n.binary = supported[CapBinaryFrames]
+	n.chunking = supported[CapChunking]
+	n.mu.Unlock()
+}
+
+// sendHello announces the protocol version and capability list, and
+// asks to resume the previous server session when one is known.
+func (c *Client) sendHello() {
+	msg := NewMessage(TypeHello, map[string]interface{}{
+		"protocol_version": ProtocolVersion,
+		"capabilities":     []string{CapBinaryFrames, CapChunking, CapPriority},
+	})
+	if token := c.sessionToken(); token != "" {
+		msg.Data["resume_session"] = token
+	}
+	if err := c.Send(msg); err != nil {
+		slog.Warn("hello send failed", "error", err)
+	}
+}
+
+// handleWelcome records the server's protocol version and adapts the
+// client's optional features to what the server accepts. A welcome
+// without a capability list leaves everything enabled for backward
+// compatibility.
+func (c *Client) handleWelcome(msg *Message) {
+	if caps, ok := msg.Data["capabilities"].([]interface{}); ok {
+		c.caps.update(caps)
+		slog.Info("negotiated server capabilities",
+			"binary_frames", c.caps.supportsBinary(),
+			"chunking", c.caps.supportsChunking())
+	}
+	if v, ok := msg.Data["protocol_version"].(float64); ok && int(v) < ProtocolVersion {
+		slog.Info("server speaks older protocol", "server_version", int(v),
+			"agent_version", ProtocolVersion)
+	}
+	// A server-assigned session token makes the session resumable: we
+	// replay it in the next hello so in-flight terminal sessions and
+	// pending commands survive a reconnect.
+	if token, ok := msg.Data["session_token"].(string); ok && token != "" {
+		c.setSessionToken(token)
+	}
+	if resumed, ok := msg.Data["session_resumed"].(bool); ok {
+		slog.Info("session negotiation", "resumed", resumed)
+	}
+	// The welcome is stamped with the server's clock; a large
+	// disagreement usually means broken NTP on this host, which will
+	// also corrupt every timestamp the agent reports.
+	if skew := clockSkew(msg.Timestamp, time.Now()); skew > maxClockSkew {
+		slog.Warn("local clock skewed from server", "skew", skew)
+	}
+}
+
+// maxClockSkew is how far the local clock may drift from the server's
+// welcome timestamp before the agent complains.
+const maxClockSkew = 30 * time.Second
+
+// clockSkew returns the absolute difference between a server unix
+// timestamp and the local clock; zero when the server sent none.
+func clockSkew(serverUnix int64, now time.Time) time.Duration {
+	if serverUnix <= 0 {
+		return 0
+	}
+	skew := now.Sub(time.Unix(serverUnix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew
+}
+
+// sessionToken returns the resumable session token, if any.
+func (c *Client) sessionToken() string {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.session
+}
+
+func (c *Client) setSessionToken(token string) {
+	c.sessionMu.Lock()
+	c.session = token
+	c.sessionMu.Unlock()
+}
+
+// binaryFallback re-encodes a binary message as base64 inside the JSON
+// envelope for servers without binary frame support.
+func binaryFallback(msg *Message) *Message {
+	fallback := *msg
+	fallback.Binary = nil
+	fallback.Data = map[string]interface{}{
+		"encoding": "base64",
+		"payload":  base64.StdEncoding.EncodeToString(msg.Binary),
+	}
+	return &fallback
+}
@@ -0,0 +1,80 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapabilitiesDefaultEnabled(t *testing.T) {
+	n := newCapabilities()
+	if !n.supportsBinary() || !n.supportsChunking() {
+		t.Error("capabilities should default to enabled before negotiation")
+	}
+}
+
+func TestHandleWelcomeDisablesUnsupported(t *testing.T) {
+	c := testClient()
+	c.handleWelcome(NewMessage(TypeWelcome, map[string]interface{}{
+		"protocol_version": float64(1),
+		"capabilities":     []interface{}{CapChunking},
+	}))
+	if c.caps.supportsBinary() {
+		t.Error("binary frames should be disabled when the server omits the capability")
+	}
+	if !c.caps.supportsChunking() {
+		t.Error("chunking should stay enabled")
+	}
+}
+
+func TestHandleWelcomeWithoutCapabilitiesKeepsDefaults(t *testing.T) {
+	c := testClient()
+	c.handleWelcome(NewMessage(TypeWelcome, map[string]interface{}{"session": "s1"}))
+	if !c.caps.supportsBinary() || !c.caps.supportsChunking() {
+		t.Error("welcome without capability list must not disable features")
+	}
+}
+
+func TestWelcomeStoresSessionTokenForResume(t *testing.T) {
+	c := testClient()
+	c.handleWelcome(NewMessage(TypeWelcome, map[string]interface{}{
+		"session_token": "sess-42",
+	}))
+	if c.sessionToken() != "sess-42" {
+		t.Fatalf("session token = %q", c.sessionToken())
+	}
+
+	c.sendHello()
+	stop := make(chan struct{})
+	close(stop)
+	hello, err := c.queue.pop(stop)
+	if err != nil {
+		t.Fatalf("no hello queued: %v", err)
+	}
+	if hello.Data["resume_session"] != "sess-42" {
+		t.Errorf("hello = %v, want resume_session", hello.Data)
+	}
+}
+
+func TestBinaryFallbackEncodesPayload(t *testing.T) {
+	msg := &Message{Type: TypeTerminalOutput, Binary: []byte("hi")}
+	fb := binaryFallback(msg)
+	if fb.Binary != nil {
+		t.Error("fallback still carries raw binary")
+	}
+	if fb.Data["encoding"] != "base64" || fb.Data["payload"] != "aGk=" {
+		t.Errorf("fallback data = %v", fb.Data)
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	if got := clockSkew(0, now); got != 0 {
+		t.Errorf("skew without server timestamp = %v", got)
+	}
+	if got := clockSkew(now.Unix()-90, now); got != 90*time.Second {
+		t.Errorf("skew = %v, want 90s", got)
+	}
+	if got := clockSkew(now.Unix()+90, now); got != 90*time.Second {
+		t.Errorf("future skew = %v, want 90s", got)
+	}
+}
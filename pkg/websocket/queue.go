@@ -0,0 +1,170 @@
+package websocket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// overflowPolicy selects what push does when a priority queue is full.
+type overflowPolicy int
+
+const (
+	// dropNew rejects the incoming message (the historical behavior).
+	dropNew overflowPolicy = iota
+	// dropOldest evicts the oldest queued message of the same priority
+	// to make room. Right for bulk data where the newest snapshot wins.
+	dropOldest
+	// blockTimeout waits briefly for space before giving up. Right for
+	// ordered streams like terminal output.
+	blockTimeout
+)
+
+// ErrQueueFull is returned by Send when the outbound queue for the
+// message's priority has no room left.
+var ErrQueueFull = errors.New("send queue full")
+
+// ErrQueueClosed is returned by Send once the client is draining or
+// shut down and no longer accepts outbound messages.
+var ErrQueueClosed = errors.New("send queue closed")
+
+// sendQueue holds outbound messages in per-priority FIFO queues.
+// writePump drains the highest-priority non-empty queue first, so a
+// large inventory payload sitting in the queue cannot delay terminal
+// output or heartbeats queued after it.
+type sendQueue struct {
+	mu       sync.Mutex
+	queues   [numPriorities][]*Message
+	limit    int
+	closed   bool
+	draining bool
+	// notify carries a token whenever a message is pushed so that pop
+	// can block without polling.
+	notify chan struct{}
+	// space carries a token whenever a message is popped so blocked
+	// pushes can retry.
+	space chan struct{}
+	// blockWait bounds how long a blockTimeout push waits for room.
+	blockWait time.Duration
+}
+
+func newSendQueue(perPriorityLimit int) *sendQueue {
+	return &sendQueue{
+		limit:     perPriorityLimit,
+		notify:    make(chan struct{}, 1),
+		space:     make(chan struct{}, 1),
+		blockWait: time.Second,
+	}
+}
+
+// push enqueues msg at the given priority, resolving a full queue
+// according to policy.
+func (q *sendQueue) push(msg *Message, p Priority, policy overflowPolicy) error {
+	var deadline time.Time
+	if policy == blockTimeout {
+		deadline = time.Now().Add(q.blockWait)
+	}
+	for {
+		q.mu.Lock()
+		if q.closed || q.draining {
+			q.mu.Unlock()
+			return ErrQueueClosed
+		}
+		if len(q.queues[p]) < q.limit {
+			q.queues[p] = append(q.queues[p], msg)
+			q.mu.Unlock()
+			select {
+			case q.notify <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+		switch policy {
+		case dropOldest:
+			q.queues[p] = append(q.queues[p][1:], msg)
+			q.mu.Unlock()
+			select {
+			case q.notify <- struct{}{}:
+			default:
+			}
+			return nil
+		case blockTimeout:
+			q.mu.Unlock()
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return ErrQueueFull
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-q.space:
+				timer.Stop()
+			case <-timer.C:
+				return ErrQueueFull
+			}
+		default: // dropNew
+			q.mu.Unlock()
+			return ErrQueueFull
+		}
+	}
+}
+
+// pop removes and returns the next message in priority order, blocking
+// until one is available or the queue is closed, which is reported as
+// ErrQueueClosed.
+func (q *sendQueue) pop(stop <-chan struct{}) (*Message, error) {
+	for {
+		q.mu.Lock()
+		for p := Priority(0); p < numPriorities; p++ {
+			if len(q.queues[p]) > 0 {
+				msg := q.queues[p][0]
+				q.queues[p] = q.queues[p][1:]
+				q.mu.Unlock()
+				select {
+				case q.space <- struct{}{}:
+				default:
+				}
+				return msg, nil
+			}
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return nil, ErrQueueClosed
+		}
+		select {
+		case <-q.notify:
+		case <-stop:
+			return nil, ErrQueueClosed
+		}
+	}
+}
+
+// depth reports the total number of queued messages.
+func (q *sendQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for p := Priority(0); p < numPriorities; p++ {
+		n += len(q.queues[p])
+	}
+	return n
+}
+
+// startDrain stops accepting new messages while letting queued ones be
+// popped.
+func (q *sendQueue) startDrain() {
+	q.mu.Lock()
+	q.draining = true
+	q.mu.Unlock()
+}
+
+// close marks the queue closed and wakes any blocked pop.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueuePriorityOrder(t *testing.T) {
+	q := newSendQueue(16)
+	q.push(NewMessage(TypeInventory, nil), PriorityInventory, dropNew)
+	q.push(NewMessage(TypeHeartbeat, nil), PriorityHeartbeat, dropNew)
+	q.push(NewMessage(TypeTerminalOutput, nil), PriorityTerminal, dropNew)
+
+	stop := make(chan struct{})
+	wantOrder := []string{TypeTerminalOutput, TypeHeartbeat, TypeInventory}
+	for _, want := range wantOrder {
+		msg, err := q.pop(stop)
+		if err != nil {
+			t.Fatalf("pop: %v", err)
+		}
+		if msg.Type != want {
+			t.Errorf("pop type = %s, want %s", msg.Type, want)
+		}
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	q := newSendQueue(1)
+	if err := q.push(NewMessage(TypeHeartbeat, nil), PriorityHeartbeat, dropNew); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if err := q.push(NewMessage(TypeHeartbeat, nil), PriorityHeartbeat, dropNew); err != ErrQueueFull {
+		t.Errorf("push on full queue = %v, want ErrQueueFull", err)
+	}
+	// Other priorities have independent capacity.
+	if err := q.push(NewMessage(TypeInventory, nil), PriorityInventory, dropNew); err != nil {
+		t.Errorf("push other priority = %v", err)
+	}
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	q := newSendQueue(2)
+	q.push(NewMessage(TypeInventory, map[string]interface{}{"seq": 1}), PriorityInventory, dropNew)
+	q.push(NewMessage(TypeInventory, map[string]interface{}{"seq": 2}), PriorityInventory, dropNew)
+	if err := q.push(NewMessage(TypeInventory, map[string]interface{}{"seq": 3}), PriorityInventory, dropOldest); err != nil {
+		t.Fatalf("push with dropOldest: %v", err)
+	}
+	stop := make(chan struct{})
+	for _, want := range []int{2, 3} {
+		msg, err := q.pop(stop)
+		if err != nil {
+			t.Fatalf("pop: %v", err)
+		}
+		if got := msg.Data["seq"].(int); got != want {
+			t.Errorf("pop seq = %d, want %d", got, want)
+		}
+	}
+	if q.depth() != 0 {
+		t.Errorf("depth = %d, want 0", q.depth())
+	}
+}
+
+func TestQueueBlockTimeout(t *testing.T) {
+	q := newSendQueue(1)
+	q.blockWait = 20 * time.Millisecond
+	q.push(NewMessage(TypeTerminalOutput, nil), PriorityTerminal, dropNew)
+
+	// No pop arrives: push times out with ErrQueueFull.
+	start := time.Now()
+	if err := q.push(NewMessage(TypeTerminalOutput, nil), PriorityTerminal, blockTimeout); err != ErrQueueFull {
+		t.Errorf("blocked push = %v, want ErrQueueFull", err)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("blocked push returned without waiting")
+	}
+
+	// A concurrent pop frees space: push succeeds within the wait.
+	q.blockWait = time.Second
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.pop(make(chan struct{}))
+	}()
+	if err := q.push(NewMessage(TypeTerminalOutput, nil), PriorityTerminal, blockTimeout); err != nil {
+		t.Errorf("push after space freed = %v", err)
+	}
+}
+
+func TestQueueCloseUnblocksPop(t *testing.T) {
+	q := newSendQueue(16)
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.pop(make(chan struct{}))
+		done <- err
+	}()
+	q.close()
+	if err := <-done; err != ErrQueueClosed {
+		t.Errorf("pop after close = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestQueueDrainRejectsNewButServesQueued(t *testing.T) {
+	q := newSendQueue(16)
+	q.push(NewMessage(TypeHeartbeat, nil), PriorityHeartbeat, dropNew)
+	q.startDrain()
+	if err := q.push(NewMessage(TypeHeartbeat, nil), PriorityHeartbeat, dropNew); err == nil {
+		t.Error("push during drain should fail")
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if msg, err := q.pop(stop); err != nil || msg == nil {
+		t.Errorf("queued message not served during drain: %v", err)
+	}
+	if q.depth() != 0 {
+		t.Errorf("depth = %d, want 0", q.depth())
+	}
+}
+
+func TestPriorityFor(t *testing.T) {
+	cases := map[string]Priority{
+		TypeTerminalOutput: PriorityTerminal,
+		TypeHeartbeat:      PriorityHeartbeat,
+		TypeInventory:      PriorityInventory,
+		TypeBMCInventory:   PriorityInventory,
+		"some_new_type":    PriorityControl,
+	}
+	for msgType, want := range cases {
+		if got := priorityFor(msgType); got != want {
+			t.Errorf("priorityFor(%s) = %d, want %d", msgType, got, want)
+		}
+	}
+}
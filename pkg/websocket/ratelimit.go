@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple token-bucket rate limiter. A zero rate means
+// unlimited and wait returns immediately.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n tokens are available and consumes them. Requests
+// larger than the burst size are allowed through by letting the bucket
+// go negative, which delays subsequent sends proportionally instead of
+// deadlocking on oversized payloads.
+func (b *tokenBucket) wait(n float64) {
+	if b == nil || b.rate <= 0 {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= n
+	var sleep time.Duration
+	if b.tokens < 0 {
+		sleep = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+	if sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
+
+// rateLimiter throttles the outbound message path by bytes and message
+// count per second.
+type rateLimiter struct {
+	bytes *tokenBucket
+	msgs  *tokenBucket
+}
+
+// newRateLimiter builds a limiter from per-second budgets; zero values
+// disable the corresponding dimension.
+func newRateLimiter(bytesPerSec, messagesPerSec int) *rateLimiter {
+	rl := &rateLimiter{}
+	if bytesPerSec > 0 {
+		// Allow a burst of one second's budget so small messages are
+		// not needlessly paced.
+		rl.bytes = newTokenBucket(float64(bytesPerSec), float64(bytesPerSec))
+	}
+	if messagesPerSec > 0 {
+		rl.msgs = newTokenBucket(float64(messagesPerSec), float64(messagesPerSec))
+	}
+	return rl
+}
+
+// wait blocks until a message of the given size may be sent.
+func (rl *rateLimiter) wait(size int) {
+	if rl == nil {
+		return
+	}
+	rl.msgs.wait(1)
+	rl.bytes.wait(float64(size))
+}
@@ -0,0 +1,38 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterDisabled(t *testing.T) {
+	rl := newRateLimiter(0, 0)
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		rl.wait(1 << 20)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("disabled limiter slept for %v", elapsed)
+	}
+}
+
+func TestTokenBucketPacesBeyondBurst(t *testing.T) {
+	// 10000 tokens/sec with a 100-token burst: consuming 600 tokens
+	// should take at least ~50ms for the 500 tokens beyond the burst.
+	b := newTokenBucket(10000, 100)
+	start := time.Now()
+	b.wait(600)
+	b.wait(1) // pays for the overdraft
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("waits finished in %v, expected pacing of at least ~50ms", elapsed)
+	}
+}
+
+func TestTokenBucketBurstIsFree(t *testing.T) {
+	b := newTokenBucket(10, 1000)
+	start := time.Now()
+	b.wait(1000)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("burst-sized wait slept for %v", elapsed)
+	}
+}
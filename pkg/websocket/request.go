@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRequestTimeout is returned by SendRequest when no reply arrives
+// within the timeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// pendingRequests tracks in-flight SendRequest calls by message ID.
+type pendingRequests struct {
+	mu      sync.Mutex
+	waiters map[string]chan *Message
+}
+
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{waiters: make(map[string]chan *Message)}
+}
+
+// add registers a waiter for the given message ID.
+func (p *pendingRequests) add(id string) chan *Message {
+	ch := make(chan *Message, 1)
+	p.mu.Lock()
+	p.waiters[id] = ch
+	p.mu.Unlock()
+	return ch
+}
+
+// remove drops the waiter for id.
+func (p *pendingRequests) remove(id string) {
+	p.mu.Lock()
+	delete(p.waiters, id)
+	p.mu.Unlock()
+}
+
+// deliver routes msg to a waiter keyed by its message ID, reporting
+// whether one consumed it.
+func (p *pendingRequests) deliver(msg *Message) bool {
+	if msg.MessageID == "" {
+		return false
+	}
+	p.mu.Lock()
+	ch, ok := p.waiters[msg.MessageID]
+	if ok {
+		delete(p.waiters, msg.MessageID)
+	}
+	p.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+	return ok
+}
+
+// SendRequest sends a message with a generated MessageID and waits for
+// the server's reply carrying the same ID, returning its data. Replies
+// are matched before regular handler dispatch.
+func (c *Client) SendRequest(msgType string, data map[string]interface{}, timeout time.Duration) (map[string]interface{}, error) {
+	msg := NewMessage(msgType, data)
+	msg.MessageID = newMessageID()
+
+	ch := c.pending.add(msg.MessageID)
+	if err := c.Send(msg); err != nil {
+		c.pending.remove(msg.MessageID)
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		if errText, ok := reply.Data["error"].(string); ok && errText != "" {
+			return reply.Data, errors.New(errText)
+		}
+		return reply.Data, nil
+	case <-timer.C:
+		c.pending.remove(msg.MessageID)
+		return nil, ErrRequestTimeout
+	case <-c.done:
+		c.pending.remove(msg.MessageID)
+		return nil, errors.New("client closed")
+	}
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendRequestCorrelatesReply(t *testing.T) {
+	c := testClient()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Wait for the request to appear in the send queue, then
+		// synthesize the server's reply with the same MessageID.
+		for {
+			stop := make(chan struct{})
+			close(stop)
+			msg, err := c.queue.pop(stop)
+			if err == nil {
+				reply := NewMessage(msg.Type+"_response", map[string]interface{}{"ok": true})
+				reply.MessageID = msg.MessageID
+				if !c.pending.deliver(reply) {
+					t.Error("deliver did not find the waiter")
+				}
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	data, err := c.SendRequest("get_config", nil, time.Second)
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if data["ok"] != true {
+		t.Errorf("reply data = %v", data)
+	}
+	<-done
+}
+
+func TestSendRequestTimeout(t *testing.T) {
+	c := testClient()
+	if _, err := c.SendRequest("get_config", nil, 20*time.Millisecond); err != ErrRequestTimeout {
+		t.Errorf("SendRequest = %v, want ErrRequestTimeout", err)
+	}
+	// The waiter must have been cleaned up.
+	c.pending.mu.Lock()
+	n := len(c.pending.waiters)
+	c.pending.mu.Unlock()
+	if n != 0 {
+		t.Errorf("pending waiters = %d, want 0", n)
+	}
+}
+
+func TestDeliverIgnoresUncorrelatedMessages(t *testing.T) {
+	c := testClient()
+	if c.pending.deliver(NewMessage("welcome", nil)) {
+		t.Error("message without ID should not be consumed")
+	}
+	msg := NewMessage("welcome", nil)
+	msg.MessageID = "nobody-waiting"
+	if c.pending.deliver(msg) {
+		t.Error("message with unknown ID should not be consumed")
+	}
+}
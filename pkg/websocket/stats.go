@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of connection and traffic counters,
+// used for remote debugging of flapping agents.
+type Stats struct {
+	Connected        bool             `json:"connected"`
+	Reconnects       int64            `json:"reconnects"`
+	MessagesSent     map[string]int64 `json:"messages_sent"`
+	MessagesReceived map[string]int64 `json:"messages_received"`
+	BytesSent        int64            `json:"bytes_sent"`
+	BytesReceived    int64            `json:"bytes_received"`
+	SendQueueDepth   int              `json:"send_queue_depth"`
+	LastError        string           `json:"last_error,omitempty"`
+	LastErrorTime    int64            `json:"last_error_time,omitempty"`
+}
+
+// stats accumulates counters behind a mutex; reads take a snapshot.
+type stats struct {
+	mu            sync.Mutex
+	connects      int64
+	sentByType    map[string]int64
+	recvByType    map[string]int64
+	bytesSent     int64
+	bytesReceived int64
+	lastError     string
+	lastErrorTime time.Time
+}
+
+func newStats() *stats {
+	return &stats{
+		sentByType: make(map[string]int64),
+		recvByType: make(map[string]int64),
+	}
+}
+
+func (s *stats) recordConnect() {
+	s.mu.Lock()
+	s.connects++
+	s.mu.Unlock()
+}
+
+func (s *stats) recordSent(msgType string, bytes int) {
+	s.mu.Lock()
+	s.sentByType[msgType]++
+	s.bytesSent += int64(bytes)
+	s.mu.Unlock()
+}
+
+func (s *stats) recordReceived(msgType string, bytes int) {
+	s.mu.Lock()
+	s.recvByType[msgType]++
+	s.bytesReceived += int64(bytes)
+	s.mu.Unlock()
+}
+
+func (s *stats) recordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.lastErrorTime = time.Now()
+	s.mu.Unlock()
+}
+
+// Stats returns a snapshot of the client's counters.
+func (c *Client) Stats() Stats {
+	c.stats.mu.Lock()
+	out := Stats{
+		Reconnects:       c.stats.connects - 1,
+		MessagesSent:     make(map[string]int64, len(c.stats.sentByType)),
+		MessagesReceived: make(map[string]int64, len(c.stats.recvByType)),
+		BytesSent:        c.stats.bytesSent,
+		BytesReceived:    c.stats.bytesReceived,
+		LastError:        c.stats.lastError,
+	}
+	if out.Reconnects < 0 {
+		out.Reconnects = 0
+	}
+	for k, v := range c.stats.sentByType {
+		out.MessagesSent[k] = v
+	}
+	for k, v := range c.stats.recvByType {
+		out.MessagesReceived[k] = v
+	}
+	if !c.stats.lastErrorTime.IsZero() {
+		out.LastErrorTime = c.stats.lastErrorTime.Unix()
+	}
+	c.stats.mu.Unlock()
+
+	out.SendQueueDepth = c.queue.depth()
+	c.mu.Lock()
+	out.Connected = c.conn != nil
+	c.mu.Unlock()
+	return out
+}
@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatsSnapshot(t *testing.T) {
+	c := testClient()
+	c.stats.recordConnect()
+	c.stats.recordConnect()
+	c.stats.recordSent(TypeHeartbeat, 100)
+	c.stats.recordSent(TypeHeartbeat, 100)
+	c.stats.recordReceived(TypeTerminalCommand, 50)
+	c.stats.recordError(errors.New("broken pipe"))
+	c.Send(NewMessage(TypeInventory, nil))
+
+	s := c.Stats()
+	if s.Reconnects != 1 {
+		t.Errorf("Reconnects = %d, want 1", s.Reconnects)
+	}
+	if s.MessagesSent[TypeHeartbeat] != 2 || s.BytesSent != 200 {
+		t.Errorf("sent counters = %v / %d", s.MessagesSent, s.BytesSent)
+	}
+	if s.MessagesReceived[TypeTerminalCommand] != 1 || s.BytesReceived != 50 {
+		t.Errorf("recv counters = %v / %d", s.MessagesReceived, s.BytesReceived)
+	}
+	if s.SendQueueDepth != 1 {
+		t.Errorf("SendQueueDepth = %d, want 1", s.SendQueueDepth)
+	}
+	if s.LastError != "broken pipe" || s.LastErrorTime == 0 {
+		t.Errorf("LastError = %q at %d", s.LastError, s.LastErrorTime)
+	}
+	if s.Connected {
+		t.Error("Connected = true for never-connected client")
+	}
+
+	// The snapshot must be independent of the live counters.
+	s.MessagesSent[TypeHeartbeat] = 999
+	if c.Stats().MessagesSent[TypeHeartbeat] != 2 {
+		t.Error("snapshot mutation leaked into client stats")
+	}
+}